@@ -2,10 +2,13 @@
 package checkgrp
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"os"
+	"time"
 
+	"github.com/andrewyang17/blockchain/foundation/blockchain/state"
 	"go.uber.org/zap"
 )
 
@@ -13,16 +16,23 @@ import (
 type Handlers struct {
 	Build string
 	Log   *zap.SugaredLogger
+	State *state.State
 }
 
 // Readiness checks if the database is ready and if not will return a 500 status.
 // Do not respond by just returning an error because further up in the call
 // stack it will interpret that as a non-trusted error.
 func (h Handlers) Readiness(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+	defer cancel()
+
 	status := "ok"
 	statusCode := http.StatusOK
-
-	// TODO: NOT SURE WHAT TO DO RIGHT NOW
+	if err := h.State.Healthy(ctx); err != nil {
+		status = "db not ready"
+		statusCode = http.StatusInternalServerError
+		h.Log.Errorw("readiness", "ERROR", err)
+	}
 
 	data := struct {
 		Status string `json:"status"`
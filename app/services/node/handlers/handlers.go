@@ -11,20 +11,31 @@ import (
 	"github.com/andrewyang17/blockchain/app/services/node/handlers/debug/checkgrp"
 	v1 "github.com/andrewyang17/blockchain/app/services/node/handlers/v1"
 	"github.com/andrewyang17/blockchain/business/web/v1/mid"
+	"github.com/andrewyang17/blockchain/foundation/blockchain/database"
 	"github.com/andrewyang17/blockchain/foundation/blockchain/state"
 	"github.com/andrewyang17/blockchain/foundation/events"
 	"github.com/andrewyang17/blockchain/foundation/nameservice"
 	"github.com/andrewyang17/blockchain/foundation/web"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.uber.org/zap"
 )
 
 // MuxConfig contains all the mandatory systems required by handlers.
 type MuxConfig struct {
-	Shutdown chan os.Signal
-	Log      *zap.SugaredLogger
-	State    *state.State
-	NS       *nameservice.NameService
-	Evts     *events.Events
+	Shutdown       chan os.Signal
+	Log            *zap.SugaredLogger
+	State          *state.State
+	NS             *nameservice.NameService
+	Evts           *events.Events
+	AllowedOrigins []string
+	TxSubmitRate   float64
+	TxSubmitBurst  int
+	MaxBlockRange  uint64
+	MaxNonceGap    uint64
+	AllowToIDs     []database.AccountID
+	DenyToIDs      []database.AccountID
+	MineToken      string
+	FinalityDepth  uint64
 }
 
 // PublicMux constructs a http.Handler with all application routes defined.
@@ -36,24 +47,25 @@ func PublicMux(cfg MuxConfig) http.Handler {
 		mid.Logger(cfg.Log),
 		mid.Errors(cfg.Log),
 		mid.Metrics(),
-		mid.Cors("*"),
+		mid.Cors(cfg.AllowedOrigins),
 		mid.Panics(),
 	)
 
-	// Accept CORS 'OPTIONS' preflight requests if config has been provided.
-	// Don't forget to apply the CORS middleware to the routes that need it.
+	// Accept CORS 'OPTIONS' preflight requests. The CORS middleware answers
+	// the request and only sets the headers for the allowed origins.
 	// Example Config: `conf:"default:https://MY_DOMAIN.COM"`
 	h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
 		return nil
 	}
-	app.Handle(http.MethodOptions, "", "/*", h, mid.Cors("*"))
+	app.Handle(http.MethodOptions, "", "/*", h)
 
 	// Load the v1 routes.
 	v1.PublicRoutes(app, v1.Config{
-		Log:   cfg.Log,
-		State: cfg.State,
-		NS:    cfg.NS,
-		Evts:  cfg.Evts,
+		Log:           cfg.Log,
+		State:         cfg.State,
+		NS:            cfg.NS,
+		Evts:          cfg.Evts,
+		FinalityDepth: cfg.FinalityDepth,
 	})
 
 	return app
@@ -68,23 +80,29 @@ func PrivateMux(cfg MuxConfig) http.Handler {
 		mid.Logger(cfg.Log),
 		mid.Errors(cfg.Log),
 		mid.Metrics(),
-		mid.Cors("*"),
+		mid.Cors([]string{"*"}),
 		mid.Panics(),
 	)
 
-	// Accept CORS 'OPTIONS' preflight requests if config has been provided.
-	// Don't forget to apply the CORS middleware to the routes that need it.
-	// Example Config: `conf:"default:https://MY_DOMAIN.COM"`
+	// Accept CORS 'OPTIONS' preflight requests. The CORS middleware answers
+	// the request and only sets the headers for the allowed origins.
 	h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
 		return nil
 	}
-	app.Handle(http.MethodOptions, "", "/*", h, mid.Cors("*"))
+	app.Handle(http.MethodOptions, "", "/*", h)
 
 	// Load the v1 routes.
 	v1.PrivateRoutes(app, v1.Config{
-		Log:   cfg.Log,
-		State: cfg.State,
-		NS:    cfg.NS,
+		Log:           cfg.Log,
+		State:         cfg.State,
+		NS:            cfg.NS,
+		TxSubmitRate:  cfg.TxSubmitRate,
+		TxSubmitBurst: cfg.TxSubmitBurst,
+		MaxBlockRange: cfg.MaxBlockRange,
+		MaxNonceGap:   cfg.MaxNonceGap,
+		AllowToIDs:    cfg.AllowToIDs,
+		DenyToIDs:     cfg.DenyToIDs,
+		MineToken:     cfg.MineToken,
 	})
 
 	return app
@@ -112,16 +130,20 @@ func DebugStandardLibraryMux() *http.ServeMux {
 // debug application routes for the service. This bypassing the use of the
 // DefaultServerMux. Using the DefaultServerMux would be a security risk since
 // a dependency could inject a handler into our service without us knowing it.
-func DebugMux(build string, log *zap.SugaredLogger) http.Handler {
+func DebugMux(build string, log *zap.SugaredLogger, state *state.State) http.Handler {
 	mux := DebugStandardLibraryMux()
 
 	// Register debug check endpoints.
 	cgh := checkgrp.Handlers{
 		Build: build,
 		Log:   log,
+		State: state,
 	}
 	mux.HandleFunc("/debug/readiness", cgh.Readiness)
 	mux.HandleFunc("/debug/liveness", cgh.Liveness)
 
+	// Register the Prometheus metrics endpoint for scraping.
+	mux.Handle("/metrics", promhttp.Handler())
+
 	return mux
 }
@@ -0,0 +1,63 @@
+package private
+
+import "github.com/andrewyang17/blockchain/foundation/blockchain/database"
+
+type accountTx struct {
+	BlockNumber uint64 `json:"block_number"`
+	database.BlockTx
+}
+
+type txPage struct {
+	Total  int                `json:"total"`
+	Limit  int                `json:"limit"`
+	Offset int                `json:"offset"`
+	Txs    []database.BlockTx `json:"transactions"`
+}
+
+type hashPage struct {
+	Total  int      `json:"total"`
+	Limit  int      `json:"limit"`
+	Offset int      `json:"offset"`
+	Hashes []string `json:"hashes"`
+}
+
+type statusSummary struct {
+	LatestBlockNumber uint64 `json:"latest_block_number"`
+	LatestBlockHash   string `json:"latest_block_hash"`
+}
+
+type txStats struct {
+	Count     int    `json:"count"`
+	Accounts  int    `json:"accounts"`
+	Bytes     int    `json:"bytes"`
+	MinTip    uint64 `json:"min_tip"`
+	MaxTip    uint64 `json:"max_tip"`
+	MedianTip uint64 `json:"median_tip"`
+}
+
+type peerInfo struct {
+	Host   string  `json:"host"`
+	Height *uint64 `json:"height,omitempty"`
+}
+
+type blockReport struct {
+	Valid     bool       `json:"valid"`
+	Error     string     `json:"error,omitempty"`
+	StateRoot string     `json:"state_root"`
+	Txs       []txReport `json:"transactions"`
+}
+
+type txReport struct {
+	Hash  string             `json:"hash"`
+	From  database.AccountID `json:"from"`
+	Nonce uint64             `json:"nonce"`
+	Valid bool               `json:"valid"`
+	Error string             `json:"error,omitempty"`
+}
+
+type txResult struct {
+	Index    int    `json:"index"`
+	Hash     string `json:"hash"`
+	Accepted bool   `json:"accepted"`
+	Error    string `json:"error,omitempty"`
+}
\ No newline at end of file
@@ -3,12 +3,18 @@ package private
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"math/bits"
 	"net/http"
+	"sort"
 	"strconv"
+	"strings"
 
 	v1 "github.com/andrewyang17/blockchain/business/web/v1"
+	"github.com/andrewyang17/blockchain/business/web/v1/mid"
 	"github.com/andrewyang17/blockchain/foundation/blockchain/database"
 	"github.com/andrewyang17/blockchain/foundation/blockchain/peer"
 	"github.com/andrewyang17/blockchain/foundation/blockchain/state"
@@ -19,9 +25,18 @@ import (
 
 // Handlers manages the set of bar ledger endpoints.
 type Handlers struct {
-	Log   *zap.SugaredLogger
-	State *state.State
-	NS    *nameservice.NameService
+	Log           *zap.SugaredLogger
+	State         *state.State
+	NS            *nameservice.NameService
+	MaxBlockRange uint64
+	MaxNonceGap   uint64
+	AllowToIDs    []database.AccountID
+	DenyToIDs     []database.AccountID
+
+	// TxLimiter is the rate limiter shared by the submit routes. The batch
+	// route takes a token for each transaction past the first, since the
+	// request itself took one. Nil turns off the extra charge.
+	TxLimiter *mid.RateLimiter
 }
 
 // SubmitPeer is called by a node, so they can be added to the known peer list.
@@ -36,62 +51,232 @@ func (h Handlers) SubmitPeer(ctx context.Context, w http.ResponseWriter, r *http
 		return fmt.Errorf("unable to decode payload: %w", err)
 	}
 
-	if !h.State.AddKnownPeer(peer) {
+	if err := peer.Validate(); err != nil {
+		return v1.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	if peer.Match(h.State.Host()) {
+		return v1.NewRequestError(errors.New("node can't add itself as a peer"), http.StatusBadRequest)
+	}
+
+	// Submitting a peer that is already known is not an error.
+	if h.State.AddKnownPeer(peer) {
 		h.Log.Infow("adding peer", "traceid", v.TraceID, "host", peer.Host)
 	}
 
 	return web.Respond(ctx, w, nil, http.StatusOK)
 }
 
-// Status returns the current status of the node.
+// Peers returns the list of known peers with the latest block number each
+// peer reported, if the peer has reported its status.
+func (h Handlers) Peers(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	peers := []peerInfo{}
+	for _, pr := range h.State.KnownExternalPeers() {
+		info := peerInfo{Host: pr.Host}
+		if height, exists := h.State.PeerHeight(pr); exists {
+			info.Height = &height
+		}
+		peers = append(peers, info)
+	}
+
+	sort.Slice(peers, func(i, j int) bool {
+		return peers[i].Host < peers[j].Host
+	})
+
+	return web.Respond(ctx, w, peers, http.StatusOK)
+}
+
+// Status returns the current status of the node. The known peers are always
+// sent as a list ordered by host, so peers get the same schema for the same
+// set of peers.
 func (h Handlers) Status(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
 	latestBlock := h.State.LatestBlock()
 
+	peers := h.State.KnownExternalPeers()
+	if peers == nil {
+		peers = []peer.Peer{}
+	}
+
+	sort.Slice(peers, func(i, j int) bool {
+		return peers[i].Host < peers[j].Host
+	})
+
 	status := peer.PeerStatus{
 		LatestBlockHash:   latestBlock.Hash(),
 		LatestBlockNumber: latestBlock.Header.Number,
-		KnownPeers:        h.State.KnownExternalPeers(),
+		KnownPeers:        peers,
 	}
 
 	return web.Respond(ctx, w, status, http.StatusOK)
 }
 
+// StatusSummary returns the latest block number and hash of the node without
+// the known peers, for peers that only need the height to make sync decisions.
+func (h Handlers) StatusSummary(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	latestBlock := h.State.LatestBlock()
+
+	summary := statusSummary{
+		LatestBlockNumber: latestBlock.Header.Number,
+		LatestBlockHash:   latestBlock.Hash(),
+	}
+
+	return web.Respond(ctx, w, summary, http.StatusOK)
+}
+
+// defaultMaxBlockRange is the largest number of blocks returned in a single
+// call when no maximum has been configured.
+const defaultMaxBlockRange = 1000
+
 // BlocksByNumber returns all the blocks based on the specified to/from values.
+// The number of blocks requested can't be more than the configured maximum
+// and any blocks past the end of the chain are left out.
 func (h Handlers) BlocksByNumber(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
-	fromStr := web.Param(r, "from")
-	if fromStr == "latest" || fromStr == "" {
-		fromStr = fmt.Sprintf("%d", state.QueryLastest)
+	latest := h.State.LatestBlock().Header.Number
+
+	from := latest
+	if fromStr := web.Param(r, "from"); fromStr != "latest" && fromStr != "" {
+		var err error
+		from, err = strconv.ParseUint(fromStr, 10, 64)
+		if err != nil {
+			return v1.NewRequestError(fmt.Errorf("invalid from: %w", err), http.StatusBadRequest)
+		}
 	}
 
-	toStr := web.Param(r, "to")
-	if toStr == "latest" || toStr == "" {
-		toStr = fmt.Sprintf("%d", state.QueryLastest)
+	// A from past the end of the chain isn't an error when asking for the
+	// latest blocks, there are just no blocks to return yet.
+	to := latest
+	if toStr := web.Param(r, "to"); toStr != "latest" && toStr != "" {
+		var err error
+		to, err = strconv.ParseUint(toStr, 10, 64)
+		if err != nil {
+			return v1.NewRequestError(fmt.Errorf("invalid to: %w", err), http.StatusBadRequest)
+		}
+
+		if from > to {
+			return v1.NewRequestError(errors.New("from greater than to"), http.StatusBadRequest)
+		}
 	}
 
-	from, err := strconv.ParseUint(fromStr, 10, 64)
+	maxRange := h.MaxBlockRange
+	if maxRange == 0 {
+		maxRange = defaultMaxBlockRange
+	}
+	if to >= from && to-from >= maxRange {
+		return v1.NewRequestError(fmt.Errorf("block range can't be more than %d blocks", maxRange), http.StatusBadRequest)
+	}
+
+	// Blocks past the end of the chain don't exist yet.
+	if to > latest {
+		to = latest
+	}
+	if from > to {
+		return web.Respond(ctx, w, nil, http.StatusNoContent)
+	}
+
+	// Read the first block before anything is written so a missing block
+	// can still be reported with a status code.
+	block, err := h.State.QueryBlockByNumber(ctx, from)
+	if err != nil {
+		return web.Respond(ctx, w, nil, http.StatusNoContent)
+	}
+
+	// Stream the blocks to the client as a JSON array one block at a time,
+	// so the range is never held in memory. If a block can't be read part
+	// way through, the error ends the array early and the client fails to
+	// decode the response.
+	web.SetStatusCode(ctx, http.StatusOK)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := io.WriteString(w, "["); err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(w)
+	for num := from; ; num++ {
+		if err := enc.Encode(database.NewBlockData(block)); err != nil {
+			return err
+		}
+
+		if num == to {
+			break
+		}
+
+		block, err = h.State.QueryBlockByNumber(ctx, num+1)
+		if err != nil {
+			return err
+		}
+
+		if _, err := io.WriteString(w, ","); err != nil {
+			return err
+		}
+	}
+
+	_, err = io.WriteString(w, "]")
+	return err
+}
+
+// maxAccountBlockRange is the largest number of blocks that can be searched
+// for the transactions of an account in a single call.
+const maxAccountBlockRange = 1000
+
+// TransactionsByAccount returns the transactions sent from or to the specified
+// account in the specified range of blocks, along with the block number of
+// each transaction.
+func (h Handlers) TransactionsByAccount(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	accountID, err := database.ToAccountID(web.Param(r, "id"))
 	if err != nil {
 		return v1.NewRequestError(err, http.StatusBadRequest)
 	}
-	to, err := strconv.ParseUint(toStr, 10, 64)
+
+	latest := h.State.LatestBlock().Header.Number
+
+	from, err := strconv.ParseUint(web.Param(r, "from"), 10, 64)
 	if err != nil {
 		return v1.NewRequestError(err, http.StatusBadRequest)
 	}
 
+	to := latest
+	if toStr := web.Param(r, "to"); toStr != "latest" {
+		to, err = strconv.ParseUint(toStr, 10, 64)
+		if err != nil {
+			return v1.NewRequestError(err, http.StatusBadRequest)
+		}
+	}
+
 	if from > to {
 		return v1.NewRequestError(errors.New("from greater than to"), http.StatusBadRequest)
 	}
 
-	blocks := h.State.QueryBlocksByNumber(from, to)
-	if len(blocks) == 0 {
-		return web.Respond(ctx, w, nil, http.StatusNoContent)
+	if to-from >= maxAccountBlockRange {
+		return v1.NewRequestError(fmt.Errorf("block range can't be more than %d blocks", maxAccountBlockRange), http.StatusBadRequest)
+	}
+
+	// The chain starts at block 1 and blocks past the end of the chain don't
+	// exist yet.
+	if from == 0 {
+		from = 1
+	}
+	if to > latest {
+		to = latest
 	}
 
-	blockData := make([]database.BlockData, len(blocks))
-	for i, block := range blocks {
-		blockData[i] = database.NewBlockData(block)
+	var txs []accountTx
+	if from <= to {
+		for _, block := range h.State.QueryBlocksByNumber(ctx, from, to) {
+			for _, tx := range block.MerkleTree.Values() {
+				if tx.FromID == accountID || tx.Pays(accountID) {
+					txs = append(txs, accountTx{BlockNumber: block.Header.Number, BlockTx: tx})
+				}
+			}
+		}
 	}
 
-	return web.Respond(ctx, w, blockData, http.StatusOK)
+	if len(txs) == 0 {
+		return web.Respond(ctx, w, nil, http.StatusNoContent)
+	}
+
+	return web.Respond(ctx, w, txs, http.StatusOK)
 }
 
 // ProposeBlock takes a block received from a peer, validates it and
@@ -130,6 +315,80 @@ func (h Handlers) ProposeBlock(ctx context.Context, w http.ResponseWriter, r *ht
 	return web.Respond(ctx, w, resp, http.StatusOK)
 }
 
+// MineBlock mines a block from the mempool right away instead of waiting for
+// the worker and returns the header of the new block. When the mempool has
+// nothing to mine, the header of the current latest block is returned.
+func (h Handlers) MineBlock(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	if !h.State.IsMiningAllowed() {
+		return v1.NewRequestError(errors.New("mining is paused while the node is syncing"), http.StatusConflict)
+	}
+
+	block, err := h.State.MineNewBlock(ctx)
+	if err != nil {
+		if errors.Is(err, state.ErrNoTransactions) {
+			return web.Respond(ctx, w, h.State.LatestBlock().Header, http.StatusOK)
+		}
+		return v1.NewRequestError(fmt.Errorf("unable to mine block: %w", err), http.StatusInternalServerError)
+	}
+
+	// Any block the worker is mining is now stale.
+	h.State.Worker.SignalCancelMining()
+
+	// Propose the new block to the network. Log the error, but that's it.
+	if err := h.State.NetSendBlockToPeers(block); err != nil {
+		h.Log.Infow("mine block", "blocknum", block.Header.Number, "ERROR", err)
+	}
+
+	return web.Respond(ctx, w, block.Header, http.StatusOK)
+}
+
+// ValidateBlock takes a candidate block and reports if the block would be
+// accepted, which transactions would fail, and the resulting state root. The
+// block isn't added to the blockchain.
+func (h Handlers) ValidateBlock(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var blockData database.BlockData
+	if err := web.Decode(r, &blockData); err != nil {
+		return fmt.Errorf("unable to decode payload: %w", err)
+	}
+
+	block, err := database.ToBlock(blockData)
+	if err != nil {
+		return v1.NewRequestError(fmt.Errorf("unable to decode block: %w", err), http.StatusBadRequest)
+	}
+
+	report := h.State.DryRunBlock(block)
+
+	// The block hash that was sent isn't part of the block validation since
+	// the hash is always calculated from the header.
+	if err := blockData.ValidateHash(); err != nil && report.Err == nil {
+		report.Err = err
+	}
+
+	resp := blockReport{
+		Valid:     report.Err == nil,
+		StateRoot: report.StateRoot,
+		Txs:       make([]txReport, len(report.Txs)),
+	}
+	if report.Err != nil {
+		resp.Error = report.Err.Error()
+	}
+
+	for i, txr := range report.Txs {
+		resp.Txs[i] = txReport{
+			Hash:  txr.Tx.SignedTx.Hash(),
+			From:  txr.Tx.FromID,
+			Nonce: txr.Tx.Nonce,
+			Valid: txr.Err == nil,
+		}
+		if txr.Err != nil {
+			resp.Valid = false
+			resp.Txs[i].Error = txr.Err.Error()
+		}
+	}
+
+	return web.Respond(ctx, w, resp, http.StatusOK)
+}
+
 // SubmitNodeTransaction adds new node transactions to the mempool.
 func (h Handlers) SubmitNodeTransaction(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
 	v, err := web.GetValues(ctx)
@@ -143,6 +402,18 @@ func (h Handlers) SubmitNodeTransaction(ctx context.Context, w http.ResponseWrit
 		return fmt.Errorf("unable to decode payload: %w", err)
 	}
 
+	if err := h.checkToID(tx); err != nil {
+		return v1.NewRequestError(err, http.StatusForbidden)
+	}
+
+	if err := h.checkNonce(tx); err != nil {
+		return v1.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	if err := h.checkBalance(tx); err != nil {
+		return v1.NewRequestError(err, http.StatusBadRequest)
+	}
+
 	// Ask the state package to add this transaction to the mempool and perform
 	// any other business logic.
 	h.Log.Infow("add tran", "traceid", v.TraceID, "sig:nonce", tx, "fron", tx.FromID, "to", tx.ToID, "value", tx.Value, "tip", tx.Tip)
@@ -159,8 +430,326 @@ func (h Handlers) SubmitNodeTransaction(ctx context.Context, w http.ResponseWrit
 	return web.Respond(ctx, w, resp, http.StatusOK)
 }
 
-// Mempool returns the set of uncommitted transactions.
+// maxBatchTxs is the most transactions that can be submitted in one batch.
+const maxBatchTxs = 100
+
+// SubmitNodeTransactionBatch adds a batch of signed transactions to the
+// mempool. Each transaction is checked on its own and the response holds a
+// result for each one, so one bad transaction doesn't reject the batch.
+func (h Handlers) SubmitNodeTransactionBatch(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	v, err := web.GetValues(ctx)
+	if err != nil {
+		return web.NewShutdownError("web value missing from context")
+	}
+
+	// Decode the JSON in the post call into a set of signed transactions.
+	var signedTxs []database.SignedTx
+	if err := web.Decode(r, &signedTxs); err != nil {
+		return fmt.Errorf("unable to decode payload: %w", err)
+	}
+
+	if len(signedTxs) == 0 {
+		return v1.NewRequestError(errors.New("no transactions in the batch"), http.StatusBadRequest)
+	}
+	if len(signedTxs) > maxBatchTxs {
+		return v1.NewRequestError(fmt.Errorf("batch of %d transactions is over the max of %d", len(signedTxs), maxBatchTxs), http.StatusBadRequest)
+	}
+
+	if !h.TxLimiter.Allow(r, len(signedTxs)-1) {
+		return v1.NewRequestError(errors.New("rate limit exceeded"), http.StatusTooManyRequests)
+	}
+
+	results := make([]txResult, len(signedTxs))
+	for i, signedTx := range signedTxs {
+		results[i] = txResult{
+			Index: i,
+			Hash:  signedTx.Hash(),
+		}
+
+		if err := h.submitBatchTx(signedTx); err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		results[i].Accepted = true
+	}
+
+	h.Log.Infow("add tran batch", "traceid", v.TraceID, "count", len(signedTxs))
+
+	return web.Respond(ctx, w, results, http.StatusOK)
+}
+
+// submitBatchTx checks the signed transaction from a batch and asks the
+// state package to add it to the mempool.
+func (h Handlers) submitBatchTx(signedTx database.SignedTx) error {
+	tx := h.State.NewBlockTx(signedTx)
+
+	if err := h.checkToID(tx); err != nil {
+		return err
+	}
+
+	if err := h.checkNonce(tx); err != nil {
+		return err
+	}
+
+	if err := h.checkBalance(tx); err != nil {
+		return err
+	}
+
+	return h.State.UpsertWalletTransaction(signedTx)
+}
+
+// checkToID checks the receiving accounts are permitted. When there is an
+// allow list the accounts must be on it, and they must never be on the deny
+// list. Empty lists permit every account.
+func (h Handlers) checkToID(tx database.BlockTx) error {
+	for _, out := range tx.Payments() {
+		if len(h.AllowToIDs) > 0 && !containsAccount(h.AllowToIDs, out.ToID) {
+			return fmt.Errorf("account %s is not allowed to receive funds", out.ToID)
+		}
+
+		if containsAccount(h.DenyToIDs, out.ToID) {
+			return fmt.Errorf("account %s is denied from receiving funds", out.ToID)
+		}
+	}
+
+	return nil
+}
+
+// checkNonce rejects transactions that can never be executed because their
+// nonce has already been used by the account. When a maximum nonce gap is
+// configured, transactions too far ahead of the account are rejected too.
+func (h Handlers) checkNonce(tx database.BlockTx) error {
+
+	// An account that doesn't exist yet hasn't used any nonces.
+	var nonce uint64
+	if account, err := h.State.QueryAccount(tx.FromID); err == nil {
+		nonce = account.Nonce
+	}
+
+	if tx.Nonce <= nonce {
+		return fmt.Errorf("stale nonce, got %d, exp greater than %d", tx.Nonce, nonce)
+	}
+
+	if h.MaxNonceGap > 0 && tx.Nonce-nonce > h.MaxNonceGap {
+		return fmt.Errorf("nonce too far ahead, got %d, max %d", tx.Nonce, nonce+h.MaxNonceGap)
+	}
+
+	return nil
+}
+
+// checkBalance rejects transactions the account can't afford once the cost
+// of its other transactions already in the mempool is taken into account.
+// A transaction replacing one in the mempool with the same nonce doesn't
+// count the cost of the transaction being replaced.
+func (h Handlers) checkBalance(tx database.BlockTx) error {
+	var balance uint64
+	if account, err := h.State.QueryAccount(tx.FromID); err == nil {
+		balance = account.Balance
+	}
+
+	needed, overflow := txCost(tx)
+	if overflow {
+		return errors.New("transaction cost overflows")
+	}
+
+	for _, pending := range h.State.Mempool() {
+		if pending.FromID != tx.FromID || pending.Nonce == tx.Nonce {
+			continue
+		}
+
+		cost, overflow := txCost(pending)
+		if overflow {
+			return errors.New("pending transaction cost overflows")
+		}
+
+		var carry uint64
+		needed, carry = bits.Add64(needed, cost, 0)
+		if carry != 0 {
+			return errors.New("pending transaction cost overflows")
+		}
+	}
+
+	if needed > balance {
+		return fmt.Errorf("insufficient funds, bal %d, needed %d", balance, needed)
+	}
+
+	return nil
+}
+
+// txCost returns the most the transaction can take from the account, which
+// is the value, the tip, and the gas fee. The bool reports if the
+// calculation overflowed.
+func txCost(tx database.BlockTx) (uint64, bool) {
+	hi, fee := bits.Mul64(tx.GasPrice, tx.GasUnits)
+	if hi != 0 {
+		return 0, true
+	}
+
+	value, overflow := tx.TotalValue()
+	if overflow {
+		return 0, true
+	}
+
+	cost, carry := bits.Add64(value, tx.Tip, 0)
+	if carry != 0 {
+		return 0, true
+	}
+
+	cost, carry = bits.Add64(cost, fee, 0)
+	return cost, carry != 0
+}
+
+// Set of values for paging through the mempool.
+const (
+	defaultLimit = 100
+	maxLimit     = 1000
+)
+
+// Mempool returns a page of the uncommitted transactions ordered by account
+// and nonce. The limit and offset query parameters select the page. A format
+// of hashes returns only the transaction hashes instead of the full bodies.
 func (h Handlers) Mempool(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	limit := defaultLimit
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		var err error
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil {
+			return v1.NewRequestError(fmt.Errorf("invalid limit: %w", err), http.StatusBadRequest)
+		}
+		if limit <= 0 || limit > maxLimit {
+			return v1.NewRequestError(fmt.Errorf("limit must be between 1 and %d", maxLimit), http.StatusBadRequest)
+		}
+	}
+
+	var offset int
+	if offsetStr := r.URL.Query().Get("offset"); offsetStr != "" {
+		var err error
+		offset, err = strconv.Atoi(offsetStr)
+		if err != nil {
+			return v1.NewRequestError(fmt.Errorf("invalid offset: %w", err), http.StatusBadRequest)
+		}
+		if offset < 0 {
+			return v1.NewRequestError(errors.New("offset can't be negative"), http.StatusBadRequest)
+		}
+	}
+
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "full" && format != "hashes" {
+		return v1.NewRequestError(fmt.Errorf("invalid format %q, must be full or hashes", format), http.StatusBadRequest)
+	}
+
 	txs := h.State.Mempool()
+
+	// Sort the transactions so the pages are stable between calls.
+	sort.Slice(txs, func(i, j int) bool {
+		if txs[i].FromID != txs[j].FromID {
+			return txs[i].FromID < txs[j].FromID
+		}
+		return txs[i].Nonce < txs[j].Nonce
+	})
+
+	page := txPage{
+		Total:  len(txs),
+		Limit:  limit,
+		Offset: offset,
+		Txs:    []database.BlockTx{},
+	}
+
+	if offset < len(txs) {
+		end := offset + limit
+		if end > len(txs) {
+			end = len(txs)
+		}
+		page.Txs = txs[offset:end]
+	}
+
+	// Monitoring clients only need to know which transactions are pending,
+	// so the hashes format leaves out the transaction bodies.
+	if format == "hashes" {
+		hashes := hashPage{
+			Total:  page.Total,
+			Limit:  page.Limit,
+			Offset: page.Offset,
+			Hashes: make([]string, len(page.Txs)),
+		}
+		for i, tx := range page.Txs {
+			hashes.Hashes[i] = tx.SignedTx.Hash()
+		}
+
+		return web.Respond(ctx, w, hashes, http.StatusOK)
+	}
+
+	return web.Respond(ctx, w, page, http.StatusOK)
+}
+
+// MempoolByAccount returns the uncommitted transactions sent from the
+// specified account ordered by nonce.
+func (h Handlers) MempoolByAccount(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	accountID, err := database.ToAccountID(web.Param(r, "account"))
+	if err != nil {
+		return v1.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	txs := []database.BlockTx{}
+	for _, tx := range h.State.Mempool() {
+		if tx.FromID == accountID {
+			txs = append(txs, tx)
+		}
+	}
+
+	sort.Slice(txs, func(i, j int) bool {
+		return txs[i].Nonce < txs[j].Nonce
+	})
+
 	return web.Respond(ctx, w, txs, http.StatusOK)
 }
+
+// MempoolStats returns a summary of the uncommitted transactions so the
+// mempool can be monitored without transferring the full list.
+func (h Handlers) MempoolStats(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	txs := h.State.Mempool()
+
+	stats := txStats{
+		Count: len(txs),
+	}
+
+	if len(txs) > 0 {
+		accounts := make(map[database.AccountID]struct{})
+		tips := make([]uint64, len(txs))
+		for i, tx := range txs {
+			data, err := json.Marshal(tx)
+			if err != nil {
+				return fmt.Errorf("unable to encode transaction: %w", err)
+			}
+
+			stats.Bytes += len(data)
+			accounts[tx.FromID] = struct{}{}
+			tips[i] = tx.Tip
+		}
+
+		sort.Slice(tips, func(i, j int) bool { return tips[i] < tips[j] })
+
+		stats.Accounts = len(accounts)
+		stats.MinTip = tips[0]
+		stats.MaxTip = tips[len(tips)-1]
+		stats.MedianTip = tips[len(tips)/2]
+		if len(tips)%2 == 0 {
+			a, b := tips[len(tips)/2-1], tips[len(tips)/2]
+			stats.MedianTip = a + (b-a)/2
+		}
+	}
+
+	return web.Respond(ctx, w, stats, http.StatusOK)
+}
+
+// containsAccount reports if the account is in the list. Accounts are
+// compared without case so checksummed and lower case ids match.
+func containsAccount(accountIDs []database.AccountID, accountID database.AccountID) bool {
+	for _, id := range accountIDs {
+		if strings.EqualFold(string(id), string(accountID)) {
+			return true
+		}
+	}
+
+	return false
+}
\ No newline at end of file
@@ -0,0 +1,1244 @@
+package private_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andrewyang17/blockchain/app/services/node/handlers/v1/private"
+	v1 "github.com/andrewyang17/blockchain/business/web/v1"
+	"github.com/andrewyang17/blockchain/business/web/v1/mid"
+	"github.com/andrewyang17/blockchain/foundation/blockchain/database"
+	"github.com/andrewyang17/blockchain/foundation/blockchain/genesis"
+	"github.com/andrewyang17/blockchain/foundation/blockchain/mempool/selector"
+	"github.com/andrewyang17/blockchain/foundation/blockchain/peer"
+	"github.com/andrewyang17/blockchain/foundation/blockchain/state"
+	"github.com/andrewyang17/blockchain/foundation/blockchain/storage/memory"
+	"github.com/andrewyang17/blockchain/foundation/web"
+	"github.com/dimfeld/httptreemux/v5"
+	"github.com/ethereum/go-ethereum/crypto"
+	"go.uber.org/zap"
+)
+
+const (
+	keyKennedy = "9f332e3700d8fc2446eaf6d15034cf96e0c2745e40353deef032a5dbf1dfed93"
+
+	idKennedy = "0xF01813E4B85e178A83e29B8E7bF26BD830a25f32"
+	idPavel   = "0xdd6B972ffcc631a62CAE1BB9d80b7ff429c8ebA4"
+	idCesar   = "0xbEE6ACE826eC3DE1B6349888B9151B92522F7F76"
+)
+
+func Test_BlocksByNumber(t *testing.T) {
+	st, err := state.New(state.Config{
+		Storage:        memory.New(),
+		Genesis:        genesis.Genesis{ChainID: 1},
+		SelectStrategy: selector.StrategyTip,
+	})
+	if err != nil {
+		t.Fatalf("Should be able to construct the state: %s", err)
+	}
+
+	h := private.Handlers{State: st, MaxBlockRange: 1000}
+
+	type test struct {
+		name   string
+		from   string
+		to     string
+		status int
+	}
+
+	tt := []test{
+		{name: "oversized range", from: "1", to: "1001", status: http.StatusBadRequest},
+		{name: "inverted range", from: "10", to: "5", status: http.StatusBadRequest},
+		{name: "malformed from", from: "abc", to: "5", status: http.StatusBadRequest},
+		{name: "malformed to", from: "1", to: "-5", status: http.StatusBadRequest},
+		{name: "largest range", from: "1", to: "1000", status: http.StatusNoContent},
+		{name: "latest", from: "1", to: "latest", status: http.StatusNoContent},
+	}
+
+	for _, tst := range tt {
+		f := func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/v1/node/block/list/"+tst.from+"/"+tst.to, nil)
+			ctx := httptreemux.AddParamsToContext(context.Background(), map[string]string{"from": tst.from, "to": tst.to})
+			r = r.WithContext(ctx)
+			w := httptest.NewRecorder()
+
+			err := h.BlocksByNumber(ctx, w, r)
+
+			switch tst.status {
+			case http.StatusBadRequest:
+				if !v1.IsRequestError(err) {
+					t.Fatalf("Test %s:\tShould get back a request error: %v", tst.name, err)
+				}
+				if status := v1.GetRequestError(err).Status; status != tst.status {
+					t.Fatalf("Test %s:\tShould receive a status code of %d: got %d", tst.name, tst.status, status)
+				}
+
+			default:
+				if err != nil {
+					t.Fatalf("Test %s:\tShould be able to list the blocks: %s", tst.name, err)
+				}
+				if w.Code != tst.status {
+					t.Fatalf("Test %s:\tShould receive a status code of %d: got %d", tst.name, tst.status, w.Code)
+				}
+			}
+		}
+
+		t.Run(tst.name, f)
+	}
+}
+
+func Test_BlocksByNumberStream(t *testing.T) {
+	const numBlocks = 250
+
+	gen := genesis.Genesis{
+		ChainID:  1,
+		Balances: map[string]uint64{idKennedy: 1_000_000, idCesar: 1},
+	}
+
+	storage := memory.New()
+	writeChain(t, gen, storage, numBlocks)
+
+	st, err := state.New(state.Config{
+		Storage:        storage,
+		Genesis:        gen,
+		SelectStrategy: selector.StrategyTip,
+	})
+	if err != nil {
+		t.Fatalf("Should be able to construct the state: %s", err)
+	}
+
+	h := private.Handlers{State: st, MaxBlockRange: 1000}
+
+	r := httptest.NewRequest(http.MethodGet, "/v1/node/block/list/1/latest", nil)
+	ctx := httptreemux.AddParamsToContext(context.Background(), map[string]string{"from": "1", "to": "latest"})
+	r = r.WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	if err := h.BlocksByNumber(ctx, w, r); err != nil {
+		t.Fatalf("Should be able to list the blocks: %s", err)
+	}
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Should receive a status code of 200: got %d", w.Code)
+	}
+
+	var blocks []database.BlockData
+	if err := json.Unmarshal(w.Body.Bytes(), &blocks); err != nil {
+		t.Fatalf("Should get back a valid JSON array: %s", err)
+	}
+
+	if len(blocks) != numBlocks {
+		t.Fatalf("Should get back all the blocks: got %d, exp %d", len(blocks), numBlocks)
+	}
+
+	for i, blockData := range blocks {
+		if exp := uint64(i + 1); blockData.Header.Number != exp {
+			t.Fatalf("Should get the blocks in order: got %d, exp %d", blockData.Header.Number, exp)
+		}
+	}
+}
+
+func Test_SubmitNodeTransactionNonce(t *testing.T) {
+	st, err := state.New(state.Config{
+		Storage:        memory.New(),
+		Genesis:        genesis.Genesis{ChainID: 1, Balances: map[string]uint64{idKennedy: 1_000_000}},
+		SelectStrategy: selector.StrategyTip,
+	})
+	if err != nil {
+		t.Fatalf("Should be able to construct the state: %s", err)
+	}
+
+	log := zap.NewNop().Sugar()
+	h := private.Handlers{Log: log, State: st, MaxNonceGap: 10}
+
+	app := web.NewApp(nil, mid.Errors(log))
+	app.Handle(http.MethodPost, "v1", "/node/tx/submit", h.SubmitNodeTransaction)
+
+	type test struct {
+		name  string
+		nonce uint64
+	}
+
+	tt := []test{
+		{name: "stale nonce", nonce: 0},
+		{name: "far future nonce", nonce: 12},
+	}
+
+	for _, tst := range tt {
+		f := func(t *testing.T) {
+			w := submitTx(t, app, database.Tx{ChainID: 1, Nonce: tst.nonce, FromID: idKennedy, ToID: idCesar, Value: 1})
+
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("Test %s:\tShould receive a status code of 400: got %d", tst.name, w.Code)
+			}
+
+			if !strings.Contains(w.Body.String(), "nonce") {
+				t.Fatalf("Test %s:\tShould be rejected because of the nonce: %s", tst.name, w.Body.String())
+			}
+
+			if st.MempoolLength() != 0 {
+				t.Fatalf("Test %s:\tShould not add the transaction to the mempool", tst.name)
+			}
+		}
+
+		t.Run(tst.name, f)
+	}
+}
+
+func Test_SubmitNodeTransactionToID(t *testing.T) {
+	type test struct {
+		name  string
+		allow []database.AccountID
+		deny  []database.AccountID
+		toID  database.AccountID
+		code  int
+	}
+
+	tt := []test{
+		{name: "empty lists", toID: idCesar, code: http.StatusOK},
+		{name: "allowed", allow: []database.AccountID{idCesar}, toID: idCesar, code: http.StatusOK},
+		{name: "allowed any case", allow: []database.AccountID{database.AccountID(strings.ToLower(idCesar))}, toID: idCesar, code: http.StatusOK},
+		{name: "not allowed", allow: []database.AccountID{idPavel}, toID: idCesar, code: http.StatusForbidden},
+		{name: "denied", deny: []database.AccountID{idCesar}, toID: idCesar, code: http.StatusForbidden},
+		{name: "not denied", deny: []database.AccountID{idPavel}, toID: idCesar, code: http.StatusOK},
+		{name: "allowed and denied", allow: []database.AccountID{idCesar}, deny: []database.AccountID{idCesar}, toID: idCesar, code: http.StatusForbidden},
+	}
+
+	for _, tst := range tt {
+		f := func(t *testing.T) {
+			st, err := state.New(state.Config{
+				Storage:        memory.New(),
+				Genesis:        genesis.Genesis{ChainID: 1, Balances: map[string]uint64{idKennedy: 1_000_000}},
+				SelectStrategy: selector.StrategyTip,
+			})
+			if err != nil {
+				t.Fatalf("Test %s:\tShould be able to construct the state: %s", tst.name, err)
+			}
+			st.Worker = nopWorker{}
+
+			log := zap.NewNop().Sugar()
+			h := private.Handlers{Log: log, State: st, AllowToIDs: tst.allow, DenyToIDs: tst.deny}
+
+			app := web.NewApp(nil, mid.Errors(log))
+			app.Handle(http.MethodPost, "v1", "/node/tx/submit", h.SubmitNodeTransaction)
+
+			w := submitTx(t, app, database.Tx{ChainID: 1, Nonce: 1, FromID: idKennedy, ToID: tst.toID, Value: 1})
+			if w.Code != tst.code {
+				t.Fatalf("Test %s:\tShould receive a status code of %d: got %d: %s", tst.name, tst.code, w.Code, w.Body.String())
+			}
+
+			exp := 0
+			if tst.code == http.StatusOK {
+				exp = 1
+			}
+			if st.MempoolLength() != exp {
+				t.Fatalf("Test %s:\tShould have %d transactions in the mempool, got %d", tst.name, exp, st.MempoolLength())
+			}
+		}
+
+		t.Run(tst.name, f)
+	}
+}
+
+func Test_SubmitNodeTransactionBatch(t *testing.T) {
+	st, err := state.New(state.Config{
+		Storage:        memory.New(),
+		Genesis:        genesis.Genesis{ChainID: 1, Balances: map[string]uint64{idKennedy: 1_000_000}},
+		SelectStrategy: selector.StrategyTip,
+	})
+	if err != nil {
+		t.Fatalf("Should be able to construct the state: %s", err)
+	}
+	st.Worker = nopWorker{}
+
+	log := zap.NewNop().Sugar()
+	h := private.Handlers{Log: log, State: st, DenyToIDs: []database.AccountID{idPavel}}
+
+	app := web.NewApp(nil, mid.Errors(log))
+	app.Handle(http.MethodPost, "v1", "/node/tx/submit/batch", h.SubmitNodeTransactionBatch)
+
+	pk, err := crypto.HexToECDSA(keyKennedy)
+	if err != nil {
+		t.Fatalf("Should be able to load the private key: %s", err)
+	}
+
+	txs := []database.Tx{
+		{ChainID: 1, Nonce: 1, FromID: idKennedy, ToID: idCesar, Value: 1},
+		{ChainID: 1, Nonce: 0, FromID: idKennedy, ToID: idCesar, Value: 1},
+		{ChainID: 1, Nonce: 2, FromID: idKennedy, ToID: idPavel, Value: 1},
+		{ChainID: 1, Nonce: 3, FromID: idKennedy, ToID: idCesar, Value: 1},
+		{ChainID: 2, Nonce: 4, FromID: idKennedy, ToID: idCesar, Value: 1},
+		{ChainID: 1, Nonce: 4, FromID: idKennedy, ToID: idCesar, Value: 2_000_000},
+	}
+
+	var signedTxs []database.SignedTx
+	for _, tx := range txs {
+		signedTx, err := tx.Sign(pk)
+		if err != nil {
+			t.Fatalf("Should be able to sign transaction: %s", err)
+		}
+		signedTxs = append(signedTxs, signedTx)
+	}
+
+	data, err := json.Marshal(signedTxs)
+	if err != nil {
+		t.Fatalf("Should be able to marshal the transactions: %s", err)
+	}
+
+	r := httptest.NewRequest(http.MethodPost, "/v1/node/tx/submit/batch", bytes.NewReader(data))
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Should receive a status code of 200 for the batch: got %d: %s", w.Code, w.Body.String())
+	}
+
+	var results []struct {
+		Index    int    `json:"index"`
+		Hash     string `json:"hash"`
+		Accepted bool   `json:"accepted"`
+		Error    string `json:"error"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &results); err != nil {
+		t.Fatalf("Should be able to unmarshal the results: %s", err)
+	}
+
+	exp := []bool{true, false, false, true, false, false}
+	if len(results) != len(exp) {
+		t.Fatalf("Should get a result for each transaction: got %d, exp %d", len(results), len(exp))
+	}
+
+	for i, result := range results {
+		if result.Index != i || result.Hash != signedTxs[i].Hash() {
+			t.Fatalf("Should identify transaction %d in the result: got index %d hash %s", i, result.Index, result.Hash)
+		}
+		if result.Accepted != exp[i] {
+			t.Fatalf("Should have accepted %t for transaction %d: got %t: %s", exp[i], i, result.Accepted, result.Error)
+		}
+		if !result.Accepted && result.Error == "" {
+			t.Fatalf("Should give the reason transaction %d was rejected.", i)
+		}
+	}
+
+	if !strings.Contains(results[5].Error, "insufficient funds") {
+		t.Fatalf("Should reject the underfunded transaction because of the balance: %s", results[5].Error)
+	}
+
+	if st.MempoolLength() != 2 {
+		t.Fatalf("Should have 2 transactions in the mempool, got %d", st.MempoolLength())
+	}
+
+	// A batch is charged a token for each transaction, so a batch larger
+	// than the client has tokens left is rejected as a whole.
+	limiter := mid.NewRateLimiter(0.001, 3)
+	limited := private.Handlers{Log: log, State: st, TxLimiter: limiter}
+	app.Handle(http.MethodPost, "v1", "/node/tx/submit/limited", limited.SubmitNodeTransactionBatch, mid.RateLimit(limiter))
+
+	var batch []database.SignedTx
+	for nonce := uint64(5); nonce <= 8; nonce++ {
+		signedTx, err := database.Tx{ChainID: 1, Nonce: nonce, FromID: idKennedy, ToID: idCesar, Value: 1}.Sign(pk)
+		if err != nil {
+			t.Fatalf("Should be able to sign transaction: %s", err)
+		}
+		batch = append(batch, signedTx)
+	}
+
+	data, err = json.Marshal(batch)
+	if err != nil {
+		t.Fatalf("Should be able to marshal the transactions: %s", err)
+	}
+
+	r = httptest.NewRequest(http.MethodPost, "/v1/node/tx/submit/limited", bytes.NewReader(data))
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, r)
+
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("Should receive a status code of 429 for a batch over the rate limit: got %d: %s", w.Code, w.Body.String())
+	}
+	if st.MempoolLength() != 2 {
+		t.Fatalf("Should not add a rate limited batch to the mempool, got %d", st.MempoolLength())
+	}
+
+	// A batch over the cap is rejected as a whole.
+	data, err = json.Marshal(make([]database.SignedTx, 101))
+	if err != nil {
+		t.Fatalf("Should be able to marshal the transactions: %s", err)
+	}
+
+	r = httptest.NewRequest(http.MethodPost, "/v1/node/tx/submit/batch", bytes.NewReader(data))
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, r)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("Should receive a status code of 400 for a batch over the cap: got %d", w.Code)
+	}
+}
+
+func Test_SubmitNodeTransactionBatchGas(t *testing.T) {
+	st, err := state.New(state.Config{
+		Storage:        memory.New(),
+		Genesis:        genesis.Genesis{ChainID: 1, GasPrice: 10, Balances: map[string]uint64{idKennedy: 100}},
+		SelectStrategy: selector.StrategyTip,
+	})
+	if err != nil {
+		t.Fatalf("Should be able to construct the state: %s", err)
+	}
+	st.Worker = nopWorker{}
+
+	log := zap.NewNop().Sugar()
+	h := private.Handlers{Log: log, State: st}
+
+	app := web.NewApp(nil, mid.Errors(log))
+	app.Handle(http.MethodPost, "v1", "/node/tx/submit/batch", h.SubmitNodeTransactionBatch)
+
+	pk, err := crypto.HexToECDSA(keyKennedy)
+	if err != nil {
+		t.Fatalf("Should be able to load the private key: %s", err)
+	}
+
+	// The first transaction covers the value and tip but not the gas fee
+	// of one unit at the genesis gas price. The second leaves room for it.
+	txs := []database.Tx{
+		{ChainID: 1, Nonce: 1, FromID: idKennedy, ToID: idCesar, Value: 50, Tip: 50},
+		{ChainID: 1, Nonce: 1, FromID: idKennedy, ToID: idCesar, Value: 50, Tip: 40},
+	}
+
+	var signedTxs []database.SignedTx
+	for _, tx := range txs {
+		signedTx, err := tx.Sign(pk)
+		if err != nil {
+			t.Fatalf("Should be able to sign transaction: %s", err)
+		}
+		signedTxs = append(signedTxs, signedTx)
+	}
+
+	data, err := json.Marshal(signedTxs)
+	if err != nil {
+		t.Fatalf("Should be able to marshal the transactions: %s", err)
+	}
+
+	r := httptest.NewRequest(http.MethodPost, "/v1/node/tx/submit/batch", bytes.NewReader(data))
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Should receive a status code of 200 for the batch: got %d: %s", w.Code, w.Body.String())
+	}
+
+	var results []struct {
+		Accepted bool   `json:"accepted"`
+		Error    string `json:"error"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &results); err != nil {
+		t.Fatalf("Should be able to unmarshal the results: %s", err)
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("Should get a result for each transaction: got %d, exp 2", len(results))
+	}
+
+	if results[0].Accepted || !strings.Contains(results[0].Error, "insufficient funds") {
+		t.Fatalf("Should reject the transaction that can't pay the gas fee: %s", results[0].Error)
+	}
+
+	if !results[1].Accepted {
+		t.Fatalf("Should accept the transaction that can pay the gas fee: %s", results[1].Error)
+	}
+}
+
+func Test_SubmitNodeTransactionBalance(t *testing.T) {
+	st, err := state.New(state.Config{
+		Storage:        memory.New(),
+		Genesis:        genesis.Genesis{ChainID: 1, Balances: map[string]uint64{idKennedy: 100}},
+		SelectStrategy: selector.StrategyTip,
+	})
+	if err != nil {
+		t.Fatalf("Should be able to construct the state: %s", err)
+	}
+	st.Worker = nopWorker{}
+
+	log := zap.NewNop().Sugar()
+	h := private.Handlers{Log: log, State: st}
+
+	app := web.NewApp(nil, mid.Errors(log))
+	app.Handle(http.MethodPost, "v1", "/node/tx/submit", h.SubmitNodeTransaction)
+
+	type test struct {
+		name   string
+		tx     database.Tx
+		status int
+	}
+
+	// The tests run in order and build on the transactions accepted into
+	// the mempool by the tests before them.
+	tt := []test{
+		{name: "affordable", tx: database.Tx{Nonce: 1, Value: 50, Tip: 10}, status: http.StatusOK},
+		{name: "unaffordable", tx: database.Tx{Nonce: 2, Value: 101}, status: http.StatusBadRequest},
+		{name: "unaffordable with pending", tx: database.Tx{Nonce: 2, Value: 30, Tip: 11}, status: http.StatusBadRequest},
+		{name: "affordable with pending", tx: database.Tx{Nonce: 2, Value: 30, Tip: 10}, status: http.StatusOK},
+	}
+
+	for _, tst := range tt {
+		f := func(t *testing.T) {
+			tst.tx.ChainID = 1
+			tst.tx.FromID = idKennedy
+			tst.tx.ToID = idCesar
+
+			w := submitTx(t, app, tst.tx)
+
+			if w.Code != tst.status {
+				t.Fatalf("Test %s:\tShould receive a status code of %d: got %d: %s", tst.name, tst.status, w.Code, w.Body.String())
+			}
+
+			if tst.status == http.StatusBadRequest && !strings.Contains(w.Body.String(), "insufficient funds") {
+				t.Fatalf("Test %s:\tShould be rejected because of the balance: %s", tst.name, w.Body.String())
+			}
+		}
+
+		t.Run(tst.name, f)
+	}
+
+	if st.MempoolLength() != 2 {
+		t.Fatalf("Should have the affordable transactions in the mempool: got %d", st.MempoolLength())
+	}
+}
+
+func Test_SubmitPeer(t *testing.T) {
+	const host = "0.0.0.0:9080"
+
+	st, err := state.New(state.Config{
+		Host:           host,
+		KnownPeers:     peer.NewPeerSet(),
+		Storage:        memory.New(),
+		Genesis:        genesis.Genesis{ChainID: 1},
+		SelectStrategy: selector.StrategyTip,
+	})
+	if err != nil {
+		t.Fatalf("Should be able to construct the state: %s", err)
+	}
+
+	log := zap.NewNop().Sugar()
+	h := private.Handlers{Log: log, State: st}
+
+	app := web.NewApp(nil, mid.Errors(log))
+	app.Handle(http.MethodPost, "v1", "/node/peers", h.SubmitPeer)
+
+	type test struct {
+		name   string
+		host   string
+		status int
+	}
+
+	// The tests run in order so the duplicate is submitted after the
+	// new peer has been added.
+	tt := []test{
+		{name: "new peer", host: "10.0.0.2:9080", status: http.StatusOK},
+		{name: "duplicate", host: "10.0.0.2:9080", status: http.StatusOK},
+		{name: "missing port", host: "10.0.0.3", status: http.StatusBadRequest},
+		{name: "invalid port", host: "10.0.0.3:http", status: http.StatusBadRequest},
+		{name: "missing host", host: ":9080", status: http.StatusBadRequest},
+		{name: "self", host: host, status: http.StatusBadRequest},
+	}
+
+	for _, tst := range tt {
+		f := func(t *testing.T) {
+			data, err := json.Marshal(peer.New(tst.host))
+			if err != nil {
+				t.Fatalf("Test %s:\tShould be able to marshal the peer: %s", tst.name, err)
+			}
+
+			r := httptest.NewRequest(http.MethodPost, "/v1/node/peers", bytes.NewReader(data))
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, r)
+
+			if w.Code != tst.status {
+				t.Fatalf("Test %s:\tShould receive a status code of %d: got %d: %s", tst.name, tst.status, w.Code, w.Body.String())
+			}
+		}
+
+		t.Run(tst.name, f)
+	}
+
+	peers := st.KnownExternalPeers()
+	if len(peers) != 1 || peers[0].Host != "10.0.0.2:9080" {
+		t.Fatalf("Should only know about the new peer: got %v", peers)
+	}
+}
+
+func Test_Peers(t *testing.T) {
+	st, err := state.New(state.Config{
+		Host:           "0.0.0.0:9080",
+		KnownPeers:     peer.NewPeerSet(),
+		Storage:        memory.New(),
+		Genesis:        genesis.Genesis{ChainID: 1},
+		SelectStrategy: selector.StrategyTip,
+	})
+	if err != nil {
+		t.Fatalf("Should be able to construct the state: %s", err)
+	}
+
+	log := zap.NewNop().Sugar()
+	h := private.Handlers{Log: log, State: st}
+
+	app := web.NewApp(nil, mid.Errors(log))
+	app.Handle(http.MethodGet, "v1", "/node/peers", h.Peers)
+	app.Handle(http.MethodPost, "v1", "/node/peers", h.SubmitPeer)
+
+	hosts := []string{"10.0.0.3:9080", "10.0.0.2:9080"}
+	for _, host := range hosts {
+		data, err := json.Marshal(peer.New(host))
+		if err != nil {
+			t.Fatalf("Should be able to marshal the peer: %s", err)
+		}
+
+		r := httptest.NewRequest(http.MethodPost, "/v1/node/peers", bytes.NewReader(data))
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, r)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Should be able to submit peer %s: got %d: %s", host, w.Code, w.Body.String())
+		}
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/v1/node/peers", nil)
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Should receive a status code of 200: got %d", w.Code)
+	}
+
+	var peers []struct {
+		Host   string  `json:"host"`
+		Height *uint64 `json:"height"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &peers); err != nil {
+		t.Fatalf("Should be able to unmarshal the peers: %s", err)
+	}
+
+	if len(peers) != 2 || peers[0].Host != hosts[1] || peers[1].Host != hosts[0] {
+		t.Fatalf("Should get back both peers ordered by host: got %+v", peers)
+	}
+
+	for _, pr := range peers {
+		if pr.Height != nil {
+			t.Fatalf("Should not get a height for peer %s that hasn't reported a status", pr.Host)
+		}
+	}
+}
+
+func Test_StatusSummary(t *testing.T) {
+	gen := genesis.Genesis{
+		ChainID:  1,
+		Balances: map[string]uint64{idKennedy: 1_000_000, idCesar: 1},
+	}
+
+	storage := memory.New()
+	writeChain(t, gen, storage, 3)
+
+	st, err := state.New(state.Config{
+		Host:           "0.0.0.0:9080",
+		KnownPeers:     peer.NewPeerSet(),
+		Storage:        storage,
+		Genesis:        gen,
+		SelectStrategy: selector.StrategyTip,
+	})
+	if err != nil {
+		t.Fatalf("Should be able to construct the state: %s", err)
+	}
+
+	log := zap.NewNop().Sugar()
+	h := private.Handlers{Log: log, State: st}
+
+	app := web.NewApp(nil, mid.Errors(log))
+	app.Handle(http.MethodGet, "v1", "/node/status", h.Status)
+	app.Handle(http.MethodGet, "v1", "/node/status/summary", h.StatusSummary)
+
+	get := func(path string, v any) map[string]json.RawMessage {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, r)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Should receive a status code of 200 for %s: got %d: %s", path, w.Code, w.Body.String())
+		}
+
+		if err := json.Unmarshal(w.Body.Bytes(), v); err != nil {
+			t.Fatalf("Should be able to unmarshal %s: %s", path, err)
+		}
+
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(w.Body.Bytes(), &fields); err != nil {
+			t.Fatalf("Should be able to unmarshal the fields of %s: %s", path, err)
+		}
+
+		return fields
+	}
+
+	var status peer.PeerStatus
+	fields := get("/v1/node/status", &status)
+
+	if peers := string(fields["known_peers"]); peers != "[]" {
+		t.Fatalf("Should get an empty list of known peers: got %s", peers)
+	}
+
+	var summary struct {
+		LatestBlockNumber uint64 `json:"latest_block_number"`
+		LatestBlockHash   string `json:"latest_block_hash"`
+	}
+	fields = get("/v1/node/status/summary", &summary)
+
+	if len(fields) != 2 {
+		t.Fatalf("Should get back only the height and hash in the summary: got %d fields", len(fields))
+	}
+
+	if status.LatestBlockNumber != 3 {
+		t.Fatalf("Should get the latest block number in the status: got %d, exp 3", status.LatestBlockNumber)
+	}
+	if summary.LatestBlockNumber != status.LatestBlockNumber {
+		t.Fatalf("Should get the same block number in the summary: got %d, exp %d", summary.LatestBlockNumber, status.LatestBlockNumber)
+	}
+	if summary.LatestBlockHash != status.LatestBlockHash {
+		t.Fatalf("Should get the same block hash in the summary: got %s, exp %s", summary.LatestBlockHash, status.LatestBlockHash)
+	}
+}
+
+// =============================================================================
+
+func Test_MineBlock(t *testing.T) {
+	st, err := state.New(state.Config{
+		Storage:        memory.New(),
+		Genesis:        genesis.Genesis{ChainID: 1, Difficulty: 1, TransPerBlock: 10, Balances: map[string]uint64{idKennedy: 1_000_000, idCesar: 1}},
+		SelectStrategy: selector.StrategyTip,
+		KnownPeers:     peer.NewPeerSet(),
+	})
+	if err != nil {
+		t.Fatalf("Should be able to construct the state: %s", err)
+	}
+	st.Worker = nopWorker{}
+
+	log := zap.NewNop().Sugar()
+	h := private.Handlers{Log: log, State: st}
+
+	app := web.NewApp(nil, mid.Errors(log))
+	app.Handle(http.MethodPost, "v1", "/node/tx/submit", h.SubmitNodeTransaction)
+	app.Handle(http.MethodPost, "v1", "/node/mine", h.MineBlock, mid.Authenticate("token"))
+
+	mine := func(auth string) (*httptest.ResponseRecorder, database.BlockHeader) {
+		r := httptest.NewRequest(http.MethodPost, "/v1/node/mine", nil)
+		if auth != "" {
+			r.Header.Set("Authorization", auth)
+		}
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, r)
+
+		var header database.BlockHeader
+		if w.Code == http.StatusOK {
+			if err := json.Unmarshal(w.Body.Bytes(), &header); err != nil {
+				t.Fatalf("Should be able to unmarshal the header: %s", err)
+			}
+		}
+
+		return w, header
+	}
+
+	if w, _ := mine(""); w.Code != http.StatusUnauthorized {
+		t.Fatalf("Should receive a status code of 401 without the token: got %d", w.Code)
+	}
+
+	w, header := mine("Bearer token")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Should receive a status code of 200 with an empty mempool: got %d: %s", w.Code, w.Body.String())
+	}
+	if header.Number != 0 {
+		t.Fatalf("Should return the current head with an empty mempool: got block %d", header.Number)
+	}
+
+	if w := submitTx(t, app, database.Tx{ChainID: 1, Nonce: 1, FromID: idKennedy, ToID: idCesar, Value: 10}); w.Code != http.StatusOK {
+		t.Fatalf("Should be able to submit the transaction: got %d: %s", w.Code, w.Body.String())
+	}
+
+	w, header = mine("Bearer token")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Should receive a status code of 200 for mining: got %d: %s", w.Code, w.Body.String())
+	}
+	if header.Number != 1 {
+		t.Fatalf("Should return the header of the mined block: got block %d", header.Number)
+	}
+
+	block, err := st.QueryBlockByNumber(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("Should be able to read the mined block: %s", err)
+	}
+
+	txs := block.MerkleTree.Values()
+	if len(txs) != 1 || txs[0].FromID != idKennedy || txs[0].Nonce != 1 {
+		t.Fatalf("Should have mined the submitted transaction: %v", txs)
+	}
+
+	if st.MempoolLength() != 0 {
+		t.Fatalf("Should have removed the mined transaction from the mempool, got %d", st.MempoolLength())
+	}
+}
+
+func Test_ValidateBlock(t *testing.T) {
+	gen := genesis.Genesis{
+		ChainID:  1,
+		Balances: map[string]uint64{idKennedy: 1_000, idCesar: 1},
+	}
+
+	st, err := state.New(state.Config{
+		Storage:        memory.New(),
+		Genesis:        gen,
+		SelectStrategy: selector.StrategyTip,
+	})
+	if err != nil {
+		t.Fatalf("Should be able to construct the state: %s", err)
+	}
+
+	log := zap.NewNop().Sugar()
+	app := web.NewApp(nil, mid.Errors(log))
+	app.Handle(http.MethodPost, "v1", "/node/block/validate", private.Handlers{Log: log, State: st}.ValidateBlock)
+
+	// The database mirrors the state, so the expected state root can be
+	// calculated.
+	ev := func(v string, args ...any) {}
+	db, err := database.New(gen, memory.New(), ev)
+	if err != nil {
+		t.Fatalf("Should be able to construct the database: %s", err)
+	}
+
+	pk, err := crypto.HexToECDSA(keyKennedy)
+	if err != nil {
+		t.Fatalf("Should be able to load the private key: %s", err)
+	}
+
+	var trans []database.BlockTx
+	for nonce := uint64(1); nonce <= 2; nonce++ {
+		tx := database.Tx{ChainID: 1, Nonce: nonce, FromID: idKennedy, ToID: idCesar, Value: 10}
+
+		signedTx, err := tx.Sign(pk)
+		if err != nil {
+			t.Fatalf("Should be able to sign transaction: %s", err)
+		}
+		trans = append(trans, database.NewBlockTx(signedTx, 0, 0))
+	}
+
+	validate := func(t *testing.T, trans []database.BlockTx) (database.Block, blockReport) {
+		block, err := database.POW(context.Background(), database.POWArgs{
+			BeneficiaryID: idPavel,
+			MiningReward:  100,
+			PrevBlock:     db.LatestBlock(),
+			StateRoot:     db.HashState(),
+			Trans:         trans,
+			EvHandler:     ev,
+		})
+		if err != nil {
+			t.Fatalf("Should be able to mine the block: %s", err)
+		}
+
+		body, err := json.Marshal(database.NewBlockData(block))
+		if err != nil {
+			t.Fatalf("Should be able to marshal the block: %s", err)
+		}
+
+		r := httptest.NewRequest(http.MethodPost, "/v1/node/block/validate", bytes.NewReader(body))
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, r)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Should receive a status code of 200: got %d: %s", w.Code, w.Body.String())
+		}
+
+		var report blockReport
+		if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+			t.Fatalf("Should be able to decode the report: %s", err)
+		}
+
+		if st.LatestBlock().Header.Number != 0 || st.Accounts()[idKennedy].Balance != 1_000 {
+			t.Fatalf("Should not change the state when validating a block.")
+		}
+
+		return block, report
+	}
+
+	t.Run("valid block", func(t *testing.T) {
+		block, report := validate(t, trans)
+
+		if !report.Valid || report.Error != "" {
+			t.Fatalf("Should report the block as valid: %+v", report)
+		}
+		if len(report.Txs) != 2 || !report.Txs[0].Valid || !report.Txs[1].Valid {
+			t.Fatalf("Should report both transactions as valid: %+v", report.Txs)
+		}
+
+		clone := db.Clone()
+		for _, tx := range block.MerkleTree.Values() {
+			if err := clone.ApplyTransaction(block, tx); err != nil {
+				t.Fatalf("Should be able to apply the transaction: %s", err)
+			}
+		}
+		clone.ApplyMiningReward(block)
+
+		if report.StateRoot != clone.HashState() {
+			t.Fatalf("Should report the state root after the block: got %s, exp %s", report.StateRoot, clone.HashState())
+		}
+	})
+
+	t.Run("bad transaction", func(t *testing.T) {
+		bad := append([]database.BlockTx{}, trans...)
+		bad[1].Value = 500
+
+		_, report := validate(t, bad)
+
+		if report.Valid || report.Error != "" {
+			t.Fatalf("Should report the block as invalid only because of a transaction: %+v", report)
+		}
+		if len(report.Txs) != 2 || !report.Txs[0].Valid {
+			t.Fatalf("Should report the first transaction as valid: %+v", report.Txs)
+		}
+		if report.Txs[1].Valid || !strings.Contains(report.Txs[1].Error, database.ErrBadSignature.Error()) {
+			t.Fatalf("Should report the bad signature for the second transaction: %+v", report.Txs[1])
+		}
+	})
+}
+
+// blockReport is the response from the validate block route.
+type blockReport struct {
+	Valid     bool   `json:"valid"`
+	Error     string `json:"error"`
+	StateRoot string `json:"state_root"`
+	Txs       []struct {
+		Hash  string `json:"hash"`
+		Nonce uint64 `json:"nonce"`
+		Valid bool   `json:"valid"`
+		Error string `json:"error"`
+	} `json:"transactions"`
+}
+
+func Test_MempoolFormat(t *testing.T) {
+	st, err := state.New(state.Config{
+		Storage:        memory.New(),
+		Genesis:        genesis.Genesis{ChainID: 1, Balances: map[string]uint64{idKennedy: 1000}},
+		SelectStrategy: selector.StrategyTip,
+	})
+	if err != nil {
+		t.Fatalf("Should be able to construct the state: %s", err)
+	}
+	st.Worker = nopWorker{}
+
+	log := zap.NewNop().Sugar()
+	h := private.Handlers{Log: log, State: st}
+
+	app := web.NewApp(nil, mid.Errors(log))
+	app.Handle(http.MethodPost, "v1", "/node/tx/submit", h.SubmitNodeTransaction)
+	app.Handle(http.MethodGet, "v1", "/node/tx/list", h.Mempool)
+
+	for nonce := uint64(1); nonce <= 2; nonce++ {
+		tx := database.Tx{ChainID: 1, Nonce: nonce, FromID: idKennedy, ToID: idCesar, Value: 10}
+		if w := submitTx(t, app, tx); w.Code != http.StatusOK {
+			t.Fatalf("Should be able to submit transaction %d: %d: %s", nonce, w.Code, w.Body.String())
+		}
+	}
+
+	list := func(query string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/v1/node/tx/list"+query, nil)
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, r)
+		return w
+	}
+
+	w := list("")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Should receive a status code of 200 for full bodies: got %d: %s", w.Code, w.Body.String())
+	}
+
+	var full struct {
+		Total int                `json:"total"`
+		Txs   []database.BlockTx `json:"transactions"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &full); err != nil {
+		t.Fatalf("Should be able to decode the full bodies: %s", err)
+	}
+	if full.Total != 2 || len(full.Txs) != 2 {
+		t.Fatalf("Should get back both full transactions: total %d, got %d", full.Total, len(full.Txs))
+	}
+
+	w = list("?format=hashes")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Should receive a status code of 200 for hashes: got %d: %s", w.Code, w.Body.String())
+	}
+	if strings.Contains(w.Body.String(), "transactions") {
+		t.Fatalf("Should not get back the transaction bodies: %s", w.Body.String())
+	}
+
+	var hashes struct {
+		Total  int      `json:"total"`
+		Hashes []string `json:"hashes"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &hashes); err != nil {
+		t.Fatalf("Should be able to decode the hashes: %s", err)
+	}
+	if hashes.Total != 2 || len(hashes.Hashes) != 2 {
+		t.Fatalf("Should get back both hashes: total %d, got %d", hashes.Total, len(hashes.Hashes))
+	}
+	for i, tx := range full.Txs {
+		if exp := tx.SignedTx.Hash(); hashes.Hashes[i] != exp {
+			t.Fatalf("Should get back the transaction hash at %d: got %s, exp %s", i, hashes.Hashes[i], exp)
+		}
+	}
+
+	if w := list("?format=bodies"); w.Code != http.StatusBadRequest {
+		t.Fatalf("Should receive a status code of 400 for an unknown format: got %d", w.Code)
+	}
+}
+
+func Test_ErrorResponse(t *testing.T) {
+	st, err := state.New(state.Config{
+		Storage:        memory.New(),
+		Genesis:        genesis.Genesis{ChainID: 1},
+		SelectStrategy: selector.StrategyTip,
+	})
+	if err != nil {
+		t.Fatalf("Should be able to construct the state: %s", err)
+	}
+
+	log := zap.NewNop().Sugar()
+	h := private.Handlers{Log: log, State: st, MaxBlockRange: 1000}
+
+	app := web.NewApp(nil, mid.Errors(log))
+	app.Handle(http.MethodGet, "v1", "/node/tx/list", h.Mempool)
+	app.Handle(http.MethodGet, "v1", "/node/block/list/:from/:to", h.BlocksByNumber)
+
+	type test struct {
+		name   string
+		path   string
+		status int
+	}
+
+	tt := []test{
+		{name: "malformed limit", path: "/v1/node/tx/list?limit=abc", status: http.StatusBadRequest},
+		{name: "negative offset", path: "/v1/node/tx/list?offset=-1", status: http.StatusBadRequest},
+		{name: "inverted range", path: "/v1/node/block/list/10/5", status: http.StatusBadRequest},
+	}
+
+	for _, tst := range tt {
+		f := func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tst.path, nil)
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, r)
+
+			if w.Code != tst.status {
+				t.Fatalf("Test %s:\tShould receive a status code of %d: got %d: %s", tst.name, tst.status, w.Code, w.Body.String())
+			}
+
+			if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+				t.Fatalf("Test %s:\tShould get a JSON content type: got %q", tst.name, ct)
+			}
+
+			var body map[string]any
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("Test %s:\tShould get back a JSON body: %s", tst.name, err)
+			}
+			if msg, ok := body["error"].(string); len(body) != 1 || !ok || msg == "" {
+				t.Fatalf("Test %s:\tShould get back only an error message: got %v", tst.name, body)
+			}
+		}
+
+		t.Run(tst.name, f)
+	}
+}
+
+func Test_TransactionsByAccount(t *testing.T) {
+	const idBill = "0x6Fe6CF3c8fF57c58d24BfC869668F48BCbDb3BD9"
+
+	gen := genesis.Genesis{
+		ChainID:  1,
+		Balances: map[string]uint64{idKennedy: 1_000_000},
+	}
+
+	multi, err := database.NewMultiTx(1, 3, idKennedy, []database.Output{{ToID: idCesar, Value: 1}, {ToID: idBill, Value: 1}}, 0, 0, 0, nil, "")
+	if err != nil {
+		t.Fatalf("Should be able to construct the multi output transaction: %s", err)
+	}
+
+	storage := memory.New()
+	writeBlocks(t, gen, storage, [][]database.Tx{
+		{{ChainID: 1, Nonce: 1, FromID: idKennedy, ToID: idCesar, Value: 1}},
+		{{ChainID: 1, Nonce: 2, FromID: idKennedy, ToID: idBill, Value: 1}},
+		{multi},
+	})
+
+	st, err := state.New(state.Config{
+		Storage:        storage,
+		Genesis:        gen,
+		SelectStrategy: selector.StrategyTip,
+	})
+	if err != nil {
+		t.Fatalf("Should be able to construct the state: %s", err)
+	}
+
+	log := zap.NewNop().Sugar()
+	h := private.Handlers{Log: log, State: st}
+
+	app := web.NewApp(nil, mid.Errors(log))
+	app.Handle(http.MethodGet, "v1", "/node/tx/account/:id/:from/:to", h.TransactionsByAccount)
+
+	type test struct {
+		name   string
+		id     string
+		from   string
+		to     string
+		status int
+		blocks []uint64
+	}
+
+	tt := []test{
+		{name: "sender", id: idKennedy, from: "1", to: "latest", status: http.StatusOK, blocks: []uint64{1, 2, 3}},
+		{name: "receiver", id: idBill, from: "1", to: "latest", status: http.StatusOK, blocks: []uint64{2, 3}},
+		{name: "multi output", id: idCesar, from: "0", to: "3", status: http.StatusOK, blocks: []uint64{1, 3}},
+		{name: "part of range", id: idCesar, from: "2", to: "3", status: http.StatusOK, blocks: []uint64{3}},
+		{name: "past the head", id: idBill, from: "3", to: "999", status: http.StatusOK, blocks: []uint64{3}},
+		{name: "no match", id: idBill, from: "1", to: "1", status: http.StatusNoContent},
+		{name: "not involved", id: idPavel, from: "1", to: "latest", status: http.StatusNoContent},
+		{name: "invalid account", id: "0xbad", from: "1", to: "3", status: http.StatusBadRequest},
+		{name: "from greater than to", id: idCesar, from: "3", to: "1", status: http.StatusBadRequest},
+		{name: "malformed from", id: idCesar, from: "abc", to: "3", status: http.StatusBadRequest},
+		{name: "over the cap", id: idCesar, from: "1", to: "1001", status: http.StatusBadRequest},
+		{name: "largest range", id: idCesar, from: "1", to: "1000", status: http.StatusOK, blocks: []uint64{1, 3}},
+	}
+
+	for _, tst := range tt {
+		f := func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/v1/node/tx/account/"+tst.id+"/"+tst.from+"/"+tst.to, nil)
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, r)
+
+			if w.Code != tst.status {
+				t.Fatalf("Test %s:\tShould receive a status code of %d: got %d: %s", tst.name, tst.status, w.Code, w.Body.String())
+			}
+
+			if tst.status != http.StatusOK {
+				return
+			}
+
+			var txs []struct {
+				BlockNumber uint64 `json:"block_number"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &txs); err != nil {
+				t.Fatalf("Test %s:\tShould be able to unmarshal the transactions: %s", tst.name, err)
+			}
+
+			var got []uint64
+			for _, tx := range txs {
+				got = append(got, tx.BlockNumber)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tst.blocks) {
+				t.Fatalf("Test %s:\tShould get the transactions from blocks %v: got %v", tst.name, tst.blocks, got)
+			}
+		}
+
+		t.Run(tst.name, f)
+	}
+}
+
+// nopWorker satisfies the state Worker interface without doing any work.
+type nopWorker struct{}
+
+func (nopWorker) Shutdown()                      {}
+func (nopWorker) Sync()                          {}
+func (nopWorker) SignalStartMining()             {}
+func (nopWorker) SignalCancelMining()            {}
+func (nopWorker) SignalShareTx(database.BlockTx) {}
+
+// submitTx signs the transaction with Kennedy's key and submits it to the
+// node transaction submit route.
+func submitTx(t *testing.T, app *web.App, tx database.Tx) *httptest.ResponseRecorder {
+	pk, err := crypto.HexToECDSA(keyKennedy)
+	if err != nil {
+		t.Fatalf("Should be able to load the private key: %s", err)
+	}
+
+	signedTx, err := tx.Sign(pk)
+	if err != nil {
+		t.Fatalf("Should be able to sign transaction: %s", err)
+	}
+
+	data, err := json.Marshal(database.NewBlockTx(signedTx, 0, 0))
+	if err != nil {
+		t.Fatalf("Should be able to marshal the transaction: %s", err)
+	}
+
+	r := httptest.NewRequest(http.MethodPost, "/v1/node/tx/submit", bytes.NewReader(data))
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, r)
+
+	return w
+}
+
+// writeChain mines the specified number of blocks, each with a single
+// transaction, and writes them to the storage.
+func writeChain(t *testing.T, gen genesis.Genesis, storage database.Storage, numBlocks int) {
+	blocks := make([][]database.Tx, numBlocks)
+	for i := range blocks {
+		blocks[i] = []database.Tx{{ChainID: gen.ChainID, Nonce: uint64(i + 1), FromID: idKennedy, ToID: idCesar, Value: 1}}
+	}
+
+	writeBlocks(t, gen, storage, blocks)
+}
+
+// writeBlocks mines a block for each set of transactions, signed with
+// Kennedy's key, and writes them to the storage.
+func writeBlocks(t *testing.T, gen genesis.Genesis, storage database.Storage, blocks [][]database.Tx) {
+	ev := func(v string, args ...any) {}
+
+	db, err := database.New(gen, storage, ev)
+	if err != nil {
+		t.Fatalf("Should be able to construct the database: %s", err)
+	}
+
+	pk, err := crypto.HexToECDSA(keyKennedy)
+	if err != nil {
+		t.Fatalf("Should be able to load the private key: %s", err)
+	}
+
+	for i, txs := range blocks {
+		var trans []database.BlockTx
+		for _, tx := range txs {
+			signedTx, err := tx.Sign(pk)
+			if err != nil {
+				t.Fatalf("Should be able to sign transaction: %s", err)
+			}
+			trans = append(trans, database.NewBlockTx(signedTx, 0, 0))
+		}
+
+		block, err := database.POW(context.Background(), database.POWArgs{
+			BeneficiaryID: idPavel,
+			PrevBlock:     db.LatestBlock(),
+			StateRoot:     db.HashState(),
+			Trans:         trans,
+			EvHandler:     ev,
+		})
+		if err != nil {
+			t.Fatalf("Should be able to mine block %d: %s", i+1, err)
+		}
+
+		if err := db.Write(context.Background(), block); err != nil {
+			t.Fatalf("Should be able to write block %d: %s", i+1, err)
+		}
+		db.UpdateLatestBlock(block)
+
+		for _, tx := range block.MerkleTree.Values() {
+			if err := db.ApplyTransaction(block, tx); err != nil {
+				t.Fatalf("Should be able to apply the transaction in block %d: %s", i+1, err)
+			}
+		}
+		db.ApplyMiningReward(block)
+	}
+}
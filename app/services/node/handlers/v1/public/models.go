@@ -2,6 +2,12 @@ package public
 
 import "github.com/andrewyang17/blockchain/foundation/blockchain/database"
 
+type health struct {
+	Status string `json:"status"`
+	Height uint64 `json:"height"`
+	Synced bool   `json:"synced"`
+}
+
 type act struct {
 	Account database.AccountID `json:"account"`
 	Name    string             `json:"name"`
@@ -9,6 +15,12 @@ type act struct {
 	Nonce   uint64             `json:"nonce"`
 }
 
+type actBalance struct {
+	Account database.AccountID `json:"account"`
+	Balance uint64             `json:"balance"`
+	Nonce   uint64             `json:"nonce"`
+}
+
 type actInfo struct {
 	LastestBlock string `json:"lastest_block"`
 	Uncommitted  int    `json:"uncommitted"`
@@ -23,7 +35,10 @@ type tx struct {
 	ChainID     uint16             `json:"chain_id"`
 	Nonce       uint64             `json:"nonce"`
 	Value       uint64             `json:"value"`
+	Outputs     []database.Output  `json:"outputs,omitempty"`
 	Tip         uint64             `json:"tip"`
+	Memo        string             `json:"memo,omitempty"`
+	Priority    uint8              `json:"priority,omitempty"`
 	Data        []byte             `json:"data"`
 	TimeStamp   uint64             `json:"timestamp"`
 	GasPrice    uint64             `json:"gas_price"`
@@ -45,3 +60,26 @@ type block struct {
 	Nonce         uint64             `json:"nonce"`
 	Transactions  []tx               `json:"txs"`
 }
+
+type txStatus struct {
+	Hash          string           `json:"hash"`
+	Status        string           `json:"status"`
+	BlockNumber   uint64           `json:"block_number,omitempty"`
+	Confirmations uint64           `json:"confirmations"`
+	Final         bool             `json:"final"`
+	Tx            database.BlockTx `json:"tx"`
+}
+
+type blockHeader struct {
+	Hash   string               `json:"hash"`
+	Header database.BlockHeader `json:"header"`
+}
+
+type txEstimate struct {
+	Success  bool   `json:"success"`
+	Error    string `json:"error,omitempty"`
+	Balance  uint64 `json:"balance"`
+	GasPrice uint64 `json:"gas_price"`
+	GasUnits uint64 `json:"gas_units"`
+	Fee      uint64 `json:"fee"`
+}
\ No newline at end of file
@@ -3,8 +3,12 @@ package public
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	v1 "github.com/andrewyang17/blockchain/business/web/v1"
@@ -18,13 +22,18 @@ import (
 	"go.uber.org/zap"
 )
 
+// blockEventPrefix is the prefix of the event sent by the state package
+// when a new block is written to the blockchain.
+const blockEventPrefix = "viewer: block: "
+
 // Handlers manages the set of bar ledger endpoints.
 type Handlers struct {
-	Log   *zap.SugaredLogger
-	State *state.State
-	NS    *nameservice.NameService
-	WS    websocket.Upgrader
-	Evts  *events.Events
+	Log           *zap.SugaredLogger
+	State         *state.State
+	NS            *nameservice.NameService
+	WS            websocket.Upgrader
+	Evts          *events.Events
+	FinalityDepth uint64
 }
 
 // Events handles a web socket to provide events to a client.
@@ -73,6 +82,128 @@ func (h Handlers) Events(ctx context.Context, w http.ResponseWriter, r *http.Req
 	}
 }
 
+// BlocksStream handles a web socket to push the header of every new block
+// to a client. The from query parameter can be used to backfill the headers
+// starting at that block number before new blocks are pushed.
+func (h Handlers) BlocksStream(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	v, err := web.GetValues(ctx)
+	if err != nil {
+		return web.NewShutdownError("web value missing from context")
+	}
+
+	var from uint64
+	if fromStr := r.URL.Query().Get("from"); fromStr != "" {
+		from, err = strconv.ParseUint(fromStr, 10, 64)
+		if err != nil {
+			return v1.NewRequestError(fmt.Errorf("invalid from: %w", err), http.StatusBadRequest)
+		}
+	}
+
+	// Need this to handle CORS on the websocket.
+	h.WS.CheckOrigin = func(r *http.Request) bool { return true }
+
+	// This upgrades the HTTP connection to a websocket connection.
+	c, err := h.WS.Upgrade(w, r, nil)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	// Acquire the events channel before the backfill so no new block is
+	// missed between the backfill and the live stream.
+	ch := h.Evts.Acquire(v.TraceID)
+	defer h.Evts.Release(v.TraceID)
+
+	// The client never sends data, but reading is the only way to know the
+	// client has gone away. The goroutine ends once the connection is closed.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			if _, _, err := c.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	var last uint64
+	send := func(blockData database.BlockData) error {
+		last = blockData.Header.Number
+		return c.WriteJSON(blockHeader{Hash: blockData.Hash, Header: blockData.Header})
+	}
+
+	if from > 0 {
+		for _, block := range h.State.QueryBlocksByNumber(ctx, from, state.QueryLastest) {
+			if err := send(database.NewBlockData(block)); err != nil {
+				return nil
+			}
+		}
+	}
+
+	// Starting a ticker to send a ping message over the websocket.
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	// Block waiting for new blocks from the blockchain, ticker or the client
+	// to go away.
+	for {
+		select {
+		case msg, wd := <-ch:
+
+			// If the channel is closed, release the websocket.
+			if !wd {
+				return nil
+			}
+
+			if !strings.HasPrefix(msg, blockEventPrefix) {
+				continue
+			}
+
+			var blockData database.BlockData
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(msg, blockEventPrefix)), &blockData); err != nil {
+				continue
+			}
+
+			// Skip any blocks already sent as part of the backfill.
+			if blockData.Header.Number <= last {
+				continue
+			}
+
+			if err := send(blockData); err != nil {
+				return nil
+			}
+
+		case <-ticker.C:
+			if err := c.WriteMessage(websocket.PingMessage, []byte("ping")); err != nil {
+				return nil
+			}
+
+		case <-done:
+			return nil
+		}
+	}
+}
+
+// Health returns the height of the chain and if the node has caught up with
+// its peers. A node that is still syncing responds with a 503 so it doesn't
+// receive any traffic.
+func (h Handlers) Health(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	height, synced := h.State.SyncStatus()
+
+	resp := health{
+		Status: "ok",
+		Height: height,
+		Synced: synced,
+	}
+
+	if !synced {
+		resp.Status = "syncing"
+		return web.Respond(ctx, w, resp, http.StatusServiceUnavailable)
+	}
+
+	return web.Respond(ctx, w, resp, http.StatusOK)
+}
+
 // Genesis returns the genesis information.
 func (h Handlers) Genesis(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
 	gen := h.State.Genesis()
@@ -120,6 +251,60 @@ func (h Handlers) Accounts(ctx context.Context, w http.ResponseWriter, r *http.R
 	return web.Respond(ctx, w, ai, http.StatusOK)
 }
 
+// Account returns the current balance and nonce for the specified account.
+// An account the node doesn't know about has a zero balance.
+func (h Handlers) Account(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	accountID := database.AccountID(web.Param(r, "id"))
+	if !accountID.IsAccountID() {
+		return v1.NewRequestError(errors.New("invalid account format"), http.StatusBadRequest)
+	}
+
+	resp := actBalance{
+		Account: accountID,
+	}
+
+	// The only error is that the account does not exist.
+	if account, err := h.State.QueryAccount(accountID); err == nil {
+		resp.Balance = account.Balance
+		resp.Nonce = account.Nonce
+	}
+
+	return web.Respond(ctx, w, resp, http.StatusOK)
+}
+
+// Transaction returns the status of the transaction with the specified hash.
+// The transaction is either pending in the mempool or confirmed in a block.
+func (h Handlers) Transaction(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	hash := web.Param(r, "hash")
+
+	tx, blockNumber, err := h.State.QueryTransactionByHash(ctx, hash)
+	if err != nil {
+		if errors.Is(err, state.ErrTxNotFound) {
+			return v1.NewRequestError(err, http.StatusNotFound)
+		}
+		return err
+	}
+
+	resp := txStatus{
+		Hash:        tx.SignedTx.Hash(),
+		Status:      "pending",
+		BlockNumber: blockNumber,
+		Tx:          tx,
+	}
+	if blockNumber != 0 {
+		resp.Status = "confirmed"
+
+		// The block holding the transaction is its first confirmation.
+		head := h.State.LatestBlock().Header.Number
+		if head >= blockNumber {
+			resp.Confirmations = head - blockNumber + 1
+		}
+		resp.Final = resp.Confirmations >= h.FinalityDepth
+	}
+
+	return web.Respond(ctx, w, resp, http.StatusOK)
+}
+
 // BlocksByAccount returns all the blocks and their details.
 func (h Handlers) BlocksByAccount(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
 	var accountID database.AccountID
@@ -132,7 +317,7 @@ func (h Handlers) BlocksByAccount(ctx context.Context, w http.ResponseWriter, r
 		}
 	}
 
-	dbBlocks, err := h.State.QueryBlocksByAccount(accountID)
+	dbBlocks, err := h.State.QueryBlocksByAccount(ctx, accountID)
 	if err != nil {
 		return err
 	}
@@ -165,7 +350,10 @@ func (h Handlers) BlocksByAccount(ctx context.Context, w http.ResponseWriter, r
 				ChainID:     tran.ChainID,
 				Nonce:       tran.Nonce,
 				Value:       tran.Value,
+				Outputs:     tran.Outputs,
 				Tip:         tran.Tip,
+				Memo:        tran.Memo,
+				Priority:    tran.Priority,
 				Data:        tran.Data,
 				TimeStamp:   tran.TimeStamp,
 				GasPrice:    tran.GasPrice,
@@ -203,7 +391,7 @@ func (h Handlers) Mempool(ctx context.Context, w http.ResponseWriter, r *http.Re
 
 	trans := []tx{}
 	for _, tran := range mempool {
-		if acct != "" && ((acct != string(tran.FromID)) && (acct != string(tran.ToID))) {
+		if acct != "" && ((acct != string(tran.FromID)) && !tran.Pays(database.AccountID(acct))) {
 			continue
 		}
 
@@ -215,7 +403,10 @@ func (h Handlers) Mempool(ctx context.Context, w http.ResponseWriter, r *http.Re
 			ChainID:     tran.ChainID,
 			Nonce:       tran.Nonce,
 			Value:       tran.Value,
+			Outputs:     tran.Outputs,
 			Tip:         tran.Tip,
+			Memo:        tran.Memo,
+			Priority:    tran.Priority,
 			Data:        tran.Data,
 			TimeStamp:   tran.TimeStamp,
 			GasPrice:    tran.GasPrice,
@@ -247,7 +438,7 @@ func (h Handlers) SubmitWalletTransaction(ctx context.Context, w http.ResponseWr
 	// It's up to the wallet to make sure the account has a proper balance and
 	// nonce. Fees will be taken if this transaction is mined into a block.
 	if err := h.State.UpsertWalletTransaction(signedTx); err != nil {
-		return v1.NewRequestError(err, http.StatusBadRequest)
+		return v1.NewRequestError(err, txErrorStatus(err))
 	}
 
 	resp := struct {
@@ -257,4 +448,44 @@ func (h Handlers) SubmitWalletTransaction(ctx context.Context, w http.ResponseWr
 	}
 
 	return web.Respond(ctx, w, resp, http.StatusOK)
+}
+
+// EstimateTransaction reports if an unsigned transaction would succeed if it
+// was mined into the next block, the balance of the from account afterwards,
+// and the gas it would pay. Nothing is added to the mempool.
+func (h Handlers) EstimateTransaction(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var tx database.Tx
+	if err := web.Decode(r, &tx); err != nil {
+		return v1.NewRequestError(fmt.Errorf("unable to decode payload: %w", err), http.StatusBadRequest)
+	}
+
+	estimate := h.State.EstimateTransaction(tx)
+
+	resp := txEstimate{
+		Success:  estimate.Err == nil,
+		Balance:  estimate.Balance,
+		GasPrice: estimate.GasPrice,
+		GasUnits: estimate.GasUnits,
+		Fee:      estimate.Fee,
+	}
+	if estimate.Err != nil {
+		resp.Error = estimate.Err.Error()
+	}
+
+	return web.Respond(ctx, w, resp, http.StatusOK)
+}
+
+// txErrorStatus maps a transaction validation error to the status code
+// returned to the wallet.
+func txErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, database.ErrBadSignature):
+		return http.StatusUnauthorized
+
+	case errors.Is(err, database.ErrSelfTransfer):
+		return http.StatusUnprocessableEntity
+
+	default:
+		return http.StatusBadRequest
+	}
 }
\ No newline at end of file
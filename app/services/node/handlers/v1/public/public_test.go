@@ -0,0 +1,349 @@
+package public_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andrewyang17/blockchain/app/services/node/handlers/v1/public"
+	"github.com/andrewyang17/blockchain/business/web/v1/mid"
+	"github.com/andrewyang17/blockchain/foundation/blockchain/database"
+	"github.com/andrewyang17/blockchain/foundation/blockchain/genesis"
+	"github.com/andrewyang17/blockchain/foundation/blockchain/mempool/selector"
+	"github.com/andrewyang17/blockchain/foundation/blockchain/state"
+	"github.com/andrewyang17/blockchain/foundation/blockchain/storage/memory"
+	"github.com/andrewyang17/blockchain/foundation/web"
+	"github.com/ethereum/go-ethereum/crypto"
+	"go.uber.org/zap"
+)
+
+func Test_Genesis(t *testing.T) {
+	gen := genesis.Genesis{
+		ChainID:       42,
+		TransPerBlock: 10,
+		Difficulty:    6,
+		MiningReward:  700,
+		GasPrice:      15,
+		Balances: map[string]uint64{
+			"0xF01813E4B85e178A83e29B8E7bF26BD830a25f32": 1_000_000,
+		},
+	}
+
+	st, err := state.New(state.Config{
+		Storage:        memory.New(),
+		Genesis:        gen,
+		SelectStrategy: selector.StrategyTip,
+	})
+	if err != nil {
+		t.Fatalf("Should be able to construct the state: %s", err)
+	}
+
+	h := public.Handlers{State: st}
+
+	r := httptest.NewRequest(http.MethodGet, "/v1/genesis", nil)
+	w := httptest.NewRecorder()
+	if err := h.Genesis(context.Background(), w, r); err != nil {
+		t.Fatalf("Should be able to get the genesis: %s", err)
+	}
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Should receive a status code of 200: got %d", w.Code)
+	}
+
+	var got genesis.Genesis
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatalf("Should be able to decode the response: %s", err)
+	}
+
+	if got.ChainID != gen.ChainID {
+		t.Fatalf("Should get back the configured chain id: got %d, exp %d", got.ChainID, gen.ChainID)
+	}
+	if got.Balances["0xF01813E4B85e178A83e29B8E7bF26BD830a25f32"] != 1_000_000 {
+		t.Fatalf("Should get back the configured balances: got %v", got.Balances)
+	}
+}
+
+func Test_EstimateTransaction(t *testing.T) {
+	const (
+		idKennedy = "0xF01813E4B85e178A83e29B8E7bF26BD830a25f32"
+		idPavel   = "0xdd6B972ffcc631a62CAE1BB9d80b7ff429c8ebA4"
+	)
+
+	type table struct {
+		name    string
+		tx      database.Tx
+		success bool
+		balance uint64
+	}
+
+	tt := []table{
+		{
+			name:    "fundable",
+			tx:      database.Tx{ChainID: 1, Nonce: 1, FromID: idKennedy, ToID: idPavel, Value: 100, Tip: 10},
+			success: true,
+			balance: 1_000 - 15 - 100 - 10,
+		},
+		{
+			name:    "unfundable",
+			tx:      database.Tx{ChainID: 1, Nonce: 1, FromID: idKennedy, ToID: idPavel, Value: 5_000, Tip: 10},
+			success: false,
+			balance: 1_000 - 15,
+		},
+	}
+
+	for _, tst := range tt {
+		f := func(t *testing.T) {
+			gen := genesis.Genesis{
+				ChainID:       1,
+				TransPerBlock: 10,
+				Difficulty:    1,
+				GasPrice:      15,
+				Balances: map[string]uint64{
+					idKennedy: 1_000,
+				},
+			}
+
+			st, err := state.New(state.Config{
+				BeneficiaryID:  "0x1111111111111111111111111111111111111111",
+				Storage:        memory.New(),
+				Genesis:        gen,
+				SelectStrategy: selector.StrategyTip,
+			})
+			if err != nil {
+				t.Fatalf("Test %s:\tShould be able to construct the state: %s", tst.name, err)
+			}
+
+			h := public.Handlers{State: st}
+
+			body, err := json.Marshal(tst.tx)
+			if err != nil {
+				t.Fatalf("Test %s:\tShould be able to marshal the transaction: %s", tst.name, err)
+			}
+
+			r := httptest.NewRequest(http.MethodPost, "/v1/tx/estimate", bytes.NewReader(body))
+			w := httptest.NewRecorder()
+			if err := h.EstimateTransaction(context.Background(), w, r); err != nil {
+				t.Fatalf("Test %s:\tShould be able to estimate the transaction: %s", tst.name, err)
+			}
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("Test %s:\tShould receive a status code of 200: got %d", tst.name, w.Code)
+			}
+
+			var got struct {
+				Success  bool   `json:"success"`
+				Error    string `json:"error"`
+				Balance  uint64 `json:"balance"`
+				GasPrice uint64 `json:"gas_price"`
+				GasUnits uint64 `json:"gas_units"`
+				Fee      uint64 `json:"fee"`
+			}
+			if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+				t.Fatalf("Test %s:\tShould be able to decode the response: %s", tst.name, err)
+			}
+
+			if got.Success != tst.success {
+				t.Fatalf("Test %s:\tShould get a success of %t: got %t, error %q", tst.name, tst.success, got.Success, got.Error)
+			}
+			if !tst.success && got.Error == "" {
+				t.Fatalf("Test %s:\tShould get the reason the transaction would fail.", tst.name)
+			}
+			if got.Balance != tst.balance {
+				t.Fatalf("Test %s:\tShould get the resulting balance: got %d, exp %d", tst.name, got.Balance, tst.balance)
+			}
+			if got.GasPrice != 15 || got.GasUnits != 1 || got.Fee != 15 {
+				t.Fatalf("Test %s:\tShould get the gas of a legacy transaction: got %d, %d, %d", tst.name, got.GasPrice, got.GasUnits, got.Fee)
+			}
+
+			// Nothing in the node may change.
+			account, err := st.QueryAccount(idKennedy)
+			if err != nil {
+				t.Fatalf("Test %s:\tShould be able to query the account: %s", tst.name, err)
+			}
+			if account.Balance != 1_000 || account.Nonce != 0 {
+				t.Fatalf("Test %s:\tShould not change the account: got balance %d, nonce %d", tst.name, account.Balance, account.Nonce)
+			}
+			if n := st.MempoolLength(); n != 0 {
+				t.Fatalf("Test %s:\tShould not add to the mempool: got %d", tst.name, n)
+			}
+		}
+
+		t.Run(tst.name, f)
+	}
+}
+
+func Test_TransactionConfirmations(t *testing.T) {
+	const (
+		keyKennedy = "9f332e3700d8fc2446eaf6d15034cf96e0c2745e40353deef032a5dbf1dfed93"
+		idKennedy  = "0xF01813E4B85e178A83e29B8E7bF26BD830a25f32"
+		idPavel    = "0xdd6B972ffcc631a62CAE1BB9d80b7ff429c8ebA4"
+	)
+
+	st, err := state.New(state.Config{
+		BeneficiaryID:  "0x1111111111111111111111111111111111111111",
+		Storage:        memory.New(),
+		Genesis:        genesis.Genesis{ChainID: 1, Difficulty: 1, TransPerBlock: 10, Balances: map[string]uint64{idKennedy: 1_000_000}},
+		SelectStrategy: selector.StrategyTip,
+	})
+	if err != nil {
+		t.Fatalf("Should be able to construct the state: %s", err)
+	}
+	st.Worker = nopWorker{}
+
+	pk, err := crypto.HexToECDSA(keyKennedy)
+	if err != nil {
+		t.Fatalf("Should be able to load the private key: %s", err)
+	}
+
+	var nonce uint64
+	submit := func() database.SignedTx {
+		nonce++
+		signedTx, err := database.Tx{ChainID: 1, Nonce: nonce, FromID: idKennedy, ToID: idPavel, Value: 10}.Sign(pk)
+		if err != nil {
+			t.Fatalf("Should be able to sign the transaction: %s", err)
+		}
+		if err := st.UpsertWalletTransaction(signedTx); err != nil {
+			t.Fatalf("Should be able to submit the transaction: %s", err)
+		}
+		return signedTx
+	}
+
+	mine := func() {
+		if _, err := st.MineNewBlock(context.Background()); err != nil {
+			t.Fatalf("Should be able to mine a block: %s", err)
+		}
+	}
+
+	log := zap.NewNop().Sugar()
+	h := public.Handlers{Log: log, State: st, FinalityDepth: 3}
+
+	app := web.NewApp(nil, mid.Errors(log))
+	app.Handle(http.MethodGet, "v1", "/tx/:hash", h.Transaction)
+
+	hash := submit().Hash()
+
+	type table struct {
+		name          string
+		head          uint64
+		status        string
+		confirmations uint64
+		final         bool
+	}
+
+	tt := []table{
+		{name: "pending", head: 0, status: "pending", confirmations: 0, final: false},
+		{name: "head", head: 1, status: "confirmed", confirmations: 1, final: false},
+		{name: "one below head", head: 2, status: "confirmed", confirmations: 2, final: false},
+		{name: "at depth", head: 3, status: "confirmed", confirmations: 3, final: true},
+		{name: "past depth", head: 5, status: "confirmed", confirmations: 5, final: true},
+	}
+
+	// The cases run in order since each one mines the chain up to its head.
+	for _, tst := range tt {
+		for st.LatestBlock().Header.Number < tst.head {
+			if st.LatestBlock().Header.Number > 0 {
+				submit()
+			}
+			mine()
+		}
+
+		r := httptest.NewRequest(http.MethodGet, "/v1/tx/"+hash, nil)
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, r)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Test %s:\tShould receive a status code of 200: got %d: %s", tst.name, w.Code, w.Body.String())
+		}
+
+		var got struct {
+			Status        string `json:"status"`
+			BlockNumber   uint64 `json:"block_number"`
+			Confirmations uint64 `json:"confirmations"`
+			Final         bool   `json:"final"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+			t.Fatalf("Test %s:\tShould be able to decode the response: %s", tst.name, err)
+		}
+
+		if got.Status != tst.status {
+			t.Fatalf("Test %s:\tShould get a status of %q: got %q", tst.name, tst.status, got.Status)
+		}
+		if got.Confirmations != tst.confirmations {
+			t.Fatalf("Test %s:\tShould get %d confirmations: got %d", tst.name, tst.confirmations, got.Confirmations)
+		}
+		if got.Final != tst.final {
+			t.Fatalf("Test %s:\tShould get a final of %t: got %t", tst.name, tst.final, got.Final)
+		}
+	}
+}
+
+func Test_ErrorResponse(t *testing.T) {
+	st, err := state.New(state.Config{
+		Storage:        memory.New(),
+		Genesis:        genesis.Genesis{ChainID: 1},
+		SelectStrategy: selector.StrategyTip,
+	})
+	if err != nil {
+		t.Fatalf("Should be able to construct the state: %s", err)
+	}
+
+	log := zap.NewNop().Sugar()
+	h := public.Handlers{Log: log, State: st}
+
+	app := web.NewApp(nil, mid.Errors(log))
+	app.Handle(http.MethodGet, "v1", "/tx/:hash", h.Transaction)
+	app.Handle(http.MethodPost, "v1", "/tx/estimate", h.EstimateTransaction)
+
+	type test struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+	}
+
+	tt := []test{
+		{name: "unknown transaction", method: http.MethodGet, path: "/v1/tx/0x1234", status: http.StatusNotFound},
+		{name: "bad payload", method: http.MethodPost, path: "/v1/tx/estimate", body: "{bad", status: http.StatusBadRequest},
+	}
+
+	for _, tst := range tt {
+		f := func(t *testing.T) {
+			r := httptest.NewRequest(tst.method, tst.path, strings.NewReader(tst.body))
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, r)
+
+			if w.Code != tst.status {
+				t.Fatalf("Test %s:\tShould receive a status code of %d: got %d: %s", tst.name, tst.status, w.Code, w.Body.String())
+			}
+
+			if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+				t.Fatalf("Test %s:\tShould get a JSON content type: got %q", tst.name, ct)
+			}
+
+			var body map[string]any
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("Test %s:\tShould get back a JSON body: %s", tst.name, err)
+			}
+			if msg, ok := body["error"].(string); len(body) != 1 || !ok || msg == "" {
+				t.Fatalf("Test %s:\tShould get back only an error message: got %v", tst.name, body)
+			}
+		}
+
+		t.Run(tst.name, f)
+	}
+}
+
+// =============================================================================
+
+type nopWorker struct{}
+
+func (nopWorker) Shutdown()                      {}
+func (nopWorker) Sync()                          {}
+func (nopWorker) SignalStartMining()             {}
+func (nopWorker) SignalCancelMining()            {}
+func (nopWorker) SignalShareTx(database.BlockTx) {}
\ No newline at end of file
@@ -7,6 +7,8 @@ import (
 
 	"github.com/andrewyang17/blockchain/app/services/node/handlers/v1/private"
 	"github.com/andrewyang17/blockchain/app/services/node/handlers/v1/public"
+	"github.com/andrewyang17/blockchain/business/web/v1/mid"
+	"github.com/andrewyang17/blockchain/foundation/blockchain/database"
 	"github.com/andrewyang17/blockchain/foundation/blockchain/state"
 	"github.com/andrewyang17/blockchain/foundation/events"
 	"github.com/andrewyang17/blockchain/foundation/nameservice"
@@ -23,42 +25,95 @@ type Config struct {
 	State *state.State
 	NS    *nameservice.NameService
 	Evts  *events.Events
+
+	// TxSubmitRate and TxSubmitBurst set the token bucket used to limit
+	// the transactions a single client can submit. A burst of zero turns
+	// off the rate limiting.
+	TxSubmitRate  float64
+	TxSubmitBurst int
+
+	// MaxBlockRange is the largest number of blocks returned by a single
+	// block list call.
+	MaxBlockRange uint64
+
+	// MaxNonceGap is how far past an account's nonce a submitted transaction
+	// nonce can be. A gap of zero turns off the check.
+	MaxNonceGap uint64
+
+	// AllowToIDs and DenyToIDs restrict the accounts a submitted node
+	// transaction can send funds to. Empty lists permit every account.
+	AllowToIDs []database.AccountID
+	DenyToIDs  []database.AccountID
+
+	// MineToken is the bearer token required to mine a block on demand. An
+	// empty token leaves the route off.
+	MineToken string
+
+	// FinalityDepth is the number of confirmations a transaction needs before
+	// it's reported as final.
+	FinalityDepth uint64
 }
 
 // PublicRoutes binds all the version 1 public routes.
 func PublicRoutes(app *web.App, cfg Config) {
 	pbl := public.Handlers{
-		Log:   cfg.Log,
-		State: cfg.State,
-		NS:    cfg.NS,
-		WS:    websocket.Upgrader{},
-		Evts:  cfg.Evts,
+		Log:           cfg.Log,
+		State:         cfg.State,
+		NS:            cfg.NS,
+		WS:            websocket.Upgrader{},
+		Evts:          cfg.Evts,
+		FinalityDepth: cfg.FinalityDepth,
 	}
 
+	app.Handle(http.MethodGet, version, "/health", pbl.Health)
 	app.Handle(http.MethodGet, version, "/events", pbl.Events)
+	app.Handle(http.MethodGet, version, "/genesis", pbl.Genesis)
 	app.Handle(http.MethodGet, version, "/genesis/list", pbl.Genesis)
 	app.Handle(http.MethodGet, version, "/accounts/list", pbl.Accounts)
 	app.Handle(http.MethodGet, version, "/accounts/list/:account", pbl.Accounts)
+	app.Handle(http.MethodGet, version, "/accounts/:id", pbl.Account)
+	app.Handle(http.MethodGet, version, "/blocks/stream", pbl.BlocksStream)
 	app.Handle(http.MethodGet, version, "/blocks/list", pbl.BlocksByAccount)
 	app.Handle(http.MethodGet, version, "/blocks/list/:account", pbl.BlocksByAccount)
 	app.Handle(http.MethodGet, version, "/tx/uncommitted/list", pbl.Mempool)
 	app.Handle(http.MethodGet, version, "/tx/uncommitted/list/:account", pbl.Mempool)
 	app.Handle(http.MethodPost, version, "/tx/submit", pbl.SubmitWalletTransaction)
+	app.Handle(http.MethodPost, version, "/tx/estimate", pbl.EstimateTransaction)
+	app.Handle(http.MethodGet, version, "/tx/:hash", pbl.Transaction)
 	app.Handle(http.MethodPost, version, "/tx/proof/:block/", pbl.SubmitWalletTransaction)
 }
 
 // PrivateRoutes binds all the version 1 private routes.
 func PrivateRoutes(app *web.App, cfg Config) {
 	prv := private.Handlers{
-		Log:   cfg.Log,
-		State: cfg.State,
-		NS:    cfg.NS,
+		Log:           cfg.Log,
+		State:         cfg.State,
+		NS:            cfg.NS,
+		MaxBlockRange: cfg.MaxBlockRange,
+		MaxNonceGap:   cfg.MaxNonceGap,
+		AllowToIDs:    cfg.AllowToIDs,
+		DenyToIDs:     cfg.DenyToIDs,
 	}
 
+	// The submit routes share one rate limiter, so a client can't get around
+	// the limit by switching routes.
+	txLimiter := mid.NewRateLimiter(cfg.TxSubmitRate, cfg.TxSubmitBurst)
+	prv.TxLimiter = txLimiter
+
+	app.Handle(http.MethodGet, version, "/node/peers", prv.Peers)
 	app.Handle(http.MethodPost, version, "/node/peers", prv.SubmitPeer)
 	app.Handle(http.MethodGet, version, "/node/status", prv.Status)
+	app.Handle(http.MethodGet, version, "/node/status/summary", prv.StatusSummary)
 	app.Handle(http.MethodGet, version, "/node/block/list/:from/:to", prv.BlocksByNumber)
 	app.Handle(http.MethodPost, version, "/node/block/propose", prv.ProposeBlock)
-	app.Handle(http.MethodPost, version, "/node/tx/submit", prv.SubmitNodeTransaction)
+	app.Handle(http.MethodPost, version, "/node/block/validate", prv.ValidateBlock)
+	if cfg.MineToken != "" {
+		app.Handle(http.MethodPost, version, "/node/mine", prv.MineBlock, mid.Authenticate(cfg.MineToken))
+	}
+	app.Handle(http.MethodPost, version, "/node/tx/submit", prv.SubmitNodeTransaction, mid.RateLimit(txLimiter))
+	app.Handle(http.MethodPost, version, "/node/tx/submit/batch", prv.SubmitNodeTransactionBatch, mid.RateLimit(txLimiter))
 	app.Handle(http.MethodGet, version, "/node/tx/list", prv.Mempool)
+	app.Handle(http.MethodGet, version, "/node/tx/list/:account", prv.MempoolByAccount)
+	app.Handle(http.MethodGet, version, "/node/tx/stats", prv.MempoolStats)
+	app.Handle(http.MethodGet, version, "/node/tx/account/:id/:from/:to", prv.TransactionsByAccount)
 }
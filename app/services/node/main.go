@@ -15,7 +15,9 @@ import (
 	"github.com/andrewyang17/blockchain/foundation/blockchain/database"
 	"github.com/andrewyang17/blockchain/foundation/blockchain/genesis"
 	"github.com/andrewyang17/blockchain/foundation/blockchain/peer"
+	"github.com/andrewyang17/blockchain/foundation/blockchain/signature"
 	"github.com/andrewyang17/blockchain/foundation/blockchain/state"
+	"github.com/andrewyang17/blockchain/foundation/blockchain/storage/cache"
 	"github.com/andrewyang17/blockchain/foundation/blockchain/storage/disk"
 	"github.com/andrewyang17/blockchain/foundation/blockchain/worker"
 	"github.com/andrewyang17/blockchain/foundation/events"
@@ -65,13 +67,36 @@ func run(log *zap.SugaredLogger) error {
 			DebugHost       string        `conf:"default:0.0.0.0:7080"`
 			PublicHost      string        `conf:"default:0.0.0.0:8080"`
 			PrivateHost     string        `conf:"default:0.0.0.0:9080"`
+			AllowedOrigins  []string      `conf:"help:Origins allowed to call the public api from a browser"`
+			TxSubmitRate    float64       `conf:"default:100,help:Transactions per second a client can submit"`
+			TxSubmitBurst   int           `conf:"default:200,help:Transactions a client can submit in a burst"`
+			MaxBlockRange   uint64        `conf:"default:1000,help:Most blocks returned by a single block list call"`
+			MaxNonceGap     uint64        `conf:"default:100,help:Most a submitted nonce can be ahead of the account nonce"`
+			AllowToIDs      []string      `conf:"help:Accounts node transactions can send funds to, empty allows all"`
+			DenyToIDs       []string      `conf:"help:Accounts node transactions can't send funds to"`
+			MineToken       string        `conf:"mask,help:Bearer token for mining a block on demand, empty turns the route off"`
+			FinalityDepth   uint64        `conf:"default:6,help:Confirmations before a transaction is reported as final"`
 		}
 		State struct {
 			Beneficiary    string   `conf:"default:miner1"`
 			DBPath         string   `conf:"default:zblock/miner1/"`
+			DBCompress     bool     `conf:"default:false"`
+			DBCompact      bool     `conf:"default:false"`
+			DBCodec        string   `conf:"default:json"`
+			DBWAL          bool     `conf:"default:false,help:Log block writes so a write cut short by a crash is settled at startup"`
+			DBCacheSize    int      `conf:"default:128,help:Most recently read blocks kept in memory"`
+			GenesisPath    string   `conf:"default:zblock/genesis.json"`
 			SelectStrategy string   `conf:"default:Tip"`
+			MaxTxsPerAcct  int      `conf:"default:64,help:Most transactions from one account considered for a block"`
+			MaxTxDataSize  int      `conf:"default:4096,help:Most bytes of data a transaction can carry"`
+			MinTipPerByte  uint64   `conf:"default:0,help:Least tip for each byte of a transaction, zero turns off the check"`
+			RecoveryOffset uint64   `conf:"default:29,help:Offset added to signature recovery ids, 29 for Ardan or 27 for Ethereum"`
+			TipAgeFactor   uint64   `conf:"default:1,help:Tip gained per second in the mempool by the tip_age strategy"`
+			MaxBlockBytes  int      `conf:"default:0,help:Most bytes of transactions the knapsack strategy packs into a block, zero means no budget"`
+			MempoolPath    string   `conf:"help:File the mempool is saved to on shutdown and restored from on startup, empty turns it off"`
 			OriginPeers    []string `conf:"default:0.0.0.0:9080"` //
 			Consensus      string   `conf:"default:POW"`          // Change to POA to run Proof of Authority
+			ForkChains     []uint16 `conf:"help:Extra chain ids accepted during a hard fork"`
 		}
 		NameService struct {
 			Folder string `conf:"default:zblock/accounts/"`
@@ -164,13 +189,42 @@ func run(log *zap.SugaredLogger) error {
 	}
 
 	// Construct the use of disk storage.
-	storage, err := disk.New(cfg.State.DBPath)
+	codec, err := disk.RetrieveCodec(cfg.State.DBCodec)
 	if err != nil {
 		return err
 	}
+	storage, err := disk.NewWithOptions(cfg.State.DBPath, disk.Options{
+		Codec:    codec,
+		Compress: cfg.State.DBCompress,
+		Compact:  cfg.State.DBCompact,
+		WAL:      cfg.State.DBWAL,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Settle any block write left unfinished by a crash before the chain is
+	// loaded. This does nothing when there is no write ahead log.
+	if err := storage.Recover(); err != nil {
+		return fmt.Errorf("recovering storage: %w", err)
+	}
+
+	// Set the largest data a transaction can carry and the least tip for its
+	// size before any transactions are validated.
+	database.MaxDataSize = cfg.State.MaxTxDataSize
+	database.MinTipPerByte = cfg.State.MinTipPerByte
+
+	// Set the signature recovery id offset before any transactions are
+	// signed or validated.
+	switch cfg.State.RecoveryOffset {
+	case signature.ArdanRecoveryOffset, signature.EthereumRecoveryOffset:
+		signature.RecoveryOffset = cfg.State.RecoveryOffset
+	default:
+		return fmt.Errorf("recovery offset must be %d or %d, got %d", signature.ArdanRecoveryOffset, signature.EthereumRecoveryOffset, cfg.State.RecoveryOffset)
+	}
 
 	// Load the genesis file for blockchain settings and origin balances.
-	genesis, err := genesis.Load()
+	genesis, err := genesis.LoadFile(cfg.State.GenesisPath)
 	if err != nil {
 		return err
 	}
@@ -178,18 +232,39 @@ func run(log *zap.SugaredLogger) error {
 	// The state value represents the blockchain node and manages the blockchain
 	// database and provides an API for application support.
 	state, err := state.New(state.Config{
-		BeneficiaryID:  database.PublicKeyToAccountID(privateKey.PublicKey),
+		BeneficiaryID:  database.AccountIDFromPrivateKey(privateKey),
 		Host:           cfg.Web.PrivateHost,
-		Storage:        storage,
+		Storage:        cache.New(storage, cfg.State.DBCacheSize),
 		Genesis:        genesis,
 		SelectStrategy: cfg.State.SelectStrategy,
+		MaxTxsPerAcct:  cfg.State.MaxTxsPerAcct,
+		TipAgeFactor:   cfg.State.TipAgeFactor,
+		MaxBlockBytes:  cfg.State.MaxBlockBytes,
 		KnownPeers:     peerSet,
 		Consensus:      cfg.State.Consensus,
+		ForkChainIDs:   cfg.State.ForkChains,
 		EvHandler:      ev,
 	})
 	if err != nil {
 		return err
 	}
+
+	// Restore the transactions that were pending when the node last shut
+	// down and save them again on the way out. The save is deferred before
+	// the state shutdown so it runs after the worker has stopped.
+	if cfg.State.MempoolPath != "" {
+		restored, dropped, err := state.RestoreMempool(cfg.State.MempoolPath)
+		if err != nil {
+			return fmt.Errorf("restoring mempool: %w", err)
+		}
+		log.Infow("startup", "status", "mempool restored", "restored", restored, "dropped", dropped)
+
+		defer func() {
+			if err := state.PersistMempool(cfg.State.MempoolPath); err != nil {
+				log.Errorw("shutdown", "status", "mempool not saved", "ERROR", err)
+			}
+		}()
+	}
 	defer state.Shutdown()
 
 	// The worker package implements the different workflows such as mining,
@@ -206,7 +281,7 @@ func run(log *zap.SugaredLogger) error {
 	// related endpoints. This includes the standard library endpoints.
 
 	// Construct the mux for the debug calls.
-	debugMux := handlers.DebugMux(build, log)
+	debugMux := handlers.DebugMux(build, log, state)
 
 	// Start the service listening for debug requests.
 	// Not concerned with shutting this down with load shedding.
@@ -235,11 +310,13 @@ func run(log *zap.SugaredLogger) error {
 
 	// Construct the mux for the public API calls.
 	publicMux := handlers.PublicMux(handlers.MuxConfig{
-		Shutdown: shutdown,
-		Log:      log,
-		State:    state,
-		NS:       ns,
-		Evts:     evts,
+		Shutdown:       shutdown,
+		Log:            log,
+		State:          state,
+		NS:             ns,
+		Evts:           evts,
+		AllowedOrigins: cfg.Web.AllowedOrigins,
+		FinalityDepth:  cfg.Web.FinalityDepth,
 	})
 
 	// Construct a server to service the requests against the mux.
@@ -263,11 +340,29 @@ func run(log *zap.SugaredLogger) error {
 
 	log.Infow("startup", "status", "initializing V1 private API support")
 
+	// Convert the accounts node transactions are restricted to or from
+	// sending funds to.
+	allowToIDs, err := toAccountIDs(cfg.Web.AllowToIDs)
+	if err != nil {
+		return fmt.Errorf("allow to ids: %w", err)
+	}
+	denyToIDs, err := toAccountIDs(cfg.Web.DenyToIDs)
+	if err != nil {
+		return fmt.Errorf("deny to ids: %w", err)
+	}
+
 	// Construct the mux for the private API calls.
 	privateMux := handlers.PrivateMux(handlers.MuxConfig{
-		Shutdown: shutdown,
-		Log:      log,
-		State:    state,
+		Shutdown:      shutdown,
+		Log:           log,
+		State:         state,
+		TxSubmitRate:  cfg.Web.TxSubmitRate,
+		TxSubmitBurst: cfg.Web.TxSubmitBurst,
+		MaxBlockRange: cfg.Web.MaxBlockRange,
+		MaxNonceGap:   cfg.Web.MaxNonceGap,
+		AllowToIDs:    allowToIDs,
+		DenyToIDs:     denyToIDs,
+		MineToken:     cfg.Web.MineToken,
 	})
 
 	// Construct a server to service the requests against the mux.
@@ -323,3 +418,17 @@ func run(log *zap.SugaredLogger) error {
 
 	return nil
 }
+
+// toAccountIDs converts the configured accounts into account ids.
+func toAccountIDs(accounts []string) ([]database.AccountID, error) {
+	accountIDs := make([]database.AccountID, len(accounts))
+	for i, account := range accounts {
+		accountID, err := database.ToAccountID(account)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", account, err)
+		}
+		accountIDs[i] = accountID
+	}
+
+	return accountIDs, nil
+}
\ No newline at end of file
@@ -25,6 +25,6 @@ func accountRun(cmd *cobra.Command, args []string) {
 		log.Fatal(err)
 	}
 
-	accountID := database.PublicKeyToAccountID(privateKey.PublicKey)
+	accountID := database.AccountIDFromPrivateKey(privateKey)
 	fmt.Println(accountID)
 }
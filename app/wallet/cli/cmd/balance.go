@@ -39,7 +39,7 @@ func balanceRun(cmd *cobra.Command, args []string) {
 		log.Fatal(err)
 	}
 
-	accountID := database.PublicKeyToAccountID(privateKey.PublicKey)
+	accountID := database.AccountIDFromPrivateKey(privateKey)
 	fmt.Println("For Account:", accountID)
 
 	resp, err := http.Get(fmt.Sprintf("%s/v1/accounts/list/%s", url, accountID))
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/andrewyang17/blockchain/foundation/blockchain/signature"
 	"github.com/spf13/cobra"
 )
 
@@ -22,6 +23,7 @@ func init() {
 	rootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
 	rootCmd.PersistentFlags().StringVarP(&accountName, "account", "a", "private.ecdsa", "The account to use.")
 	rootCmd.PersistentFlags().StringVarP(&accountPath, "account-path", "p", "zblock/accounts/", "Path to the directory with private keys.")
+	rootCmd.PersistentFlags().Uint64Var(&signature.RecoveryOffset, "recovery-offset", signature.ArdanRecoveryOffset, "Offset added to signature recovery ids, must match the node.")
 }
 
 var rootCmd = &cobra.Command{
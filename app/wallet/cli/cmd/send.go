@@ -14,13 +14,16 @@ import (
 )
 
 var (
-	url   string
-	nonce uint64
-	from  string
-	to    string
-	value uint64
-	tip   uint64
-	data  []byte
+	url      string
+	nonce    uint64
+	from     string
+	to       string
+	value    uint64
+	tip      uint64
+	gasPrice uint64
+	gasUnits uint64
+	memo     string
+	data     []byte
 )
 
 var sendCmd = &cobra.Command{
@@ -37,6 +40,9 @@ func init() {
 	sendCmd.Flags().StringVarP(&to, "to", "t", "", "Who is receiving the transaction.")
 	sendCmd.Flags().Uint64VarP(&value, "value", "v", 0, "Value to send.")
 	sendCmd.Flags().Uint64VarP(&tip, "tip", "c", 0, "Tip to send.")
+	sendCmd.Flags().Uint64Var(&gasPrice, "gas-price", 0, "Price to pay for one unit of gas.")
+	sendCmd.Flags().Uint64Var(&gasUnits, "gas-units", 0, "Units of gas to pay for.")
+	sendCmd.Flags().StringVar(&memo, "memo", "", "Reference for the payment.")
 	sendCmd.Flags().BytesHexVarP(&data, "data", "d", nil, "Data to send.")
 }
 
@@ -61,7 +67,7 @@ func sendWithDetails(privateKey *ecdsa.PrivateKey) {
 	}
 
 	const chainID = 1
-	tx, err := database.NewTx(chainID, nonce, fromAccount, toAccount, value, tip, data)
+	tx, err := database.NewTx(chainID, nonce, fromAccount, toAccount, value, tip, gasPrice, gasUnits, data, memo)
 	if err != nil {
 		log.Fatal(err)
 	}
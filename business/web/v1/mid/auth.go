@@ -0,0 +1,39 @@
+package mid
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+
+	v1Web "github.com/andrewyang17/blockchain/business/web/v1"
+	"github.com/andrewyang17/blockchain/foundation/web"
+)
+
+// Authenticate requires the request to carry the specified token as a bearer
+// token in the Authorization header. Requests without the token are rejected
+// with a 401. An empty token rejects every request.
+func Authenticate(token string) web.Middleware {
+
+	// This is the actual middleware function to be executed.
+	m := func(handler web.Handler) web.Handler {
+
+		// Create the handler that will be attached in the middleware chain.
+		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			const prefix = "Bearer "
+
+			got := r.Header.Get("Authorization")
+			if token == "" || !strings.HasPrefix(got, prefix) || subtle.ConstantTimeCompare([]byte(got[len(prefix):]), []byte(token)) != 1 {
+				return v1Web.NewRequestError(errors.New("not authorized"), http.StatusUnauthorized)
+			}
+
+			// Call the next handler.
+			return handler(ctx, w, r)
+		}
+
+		return h
+	}
+
+	return m
+}
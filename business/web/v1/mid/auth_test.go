@@ -0,0 +1,60 @@
+package mid_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	v1Web "github.com/andrewyang17/blockchain/business/web/v1"
+	"github.com/andrewyang17/blockchain/business/web/v1/mid"
+)
+
+func Test_Authenticate(t *testing.T) {
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return nil
+	}
+
+	type test struct {
+		name   string
+		token  string
+		header string
+		valid  bool
+	}
+
+	tt := []test{
+		{name: "valid", token: "secret", header: "Bearer secret", valid: true},
+		{name: "missing", token: "secret", header: "", valid: false},
+		{name: "wrong", token: "secret", header: "Bearer other", valid: false},
+		{name: "no scheme", token: "secret", header: "secret", valid: false},
+		{name: "no token configured", token: "", header: "Bearer ", valid: false},
+	}
+
+	for _, tst := range tt {
+		f := func(t *testing.T) {
+			h := mid.Authenticate(tst.token)(handler)
+
+			r := httptest.NewRequest(http.MethodPost, "/v1/node/mine", nil)
+			if tst.header != "" {
+				r.Header.Set("Authorization", tst.header)
+			}
+
+			err := h(context.Background(), httptest.NewRecorder(), r)
+			if tst.valid {
+				if err != nil {
+					t.Fatalf("Test %s:\tShould allow the request: %s", tst.name, err)
+				}
+				return
+			}
+
+			if !v1Web.IsRequestError(err) {
+				t.Fatalf("Test %s:\tShould reject the request: %v", tst.name, err)
+			}
+			if status := v1Web.GetRequestError(err).Status; status != http.StatusUnauthorized {
+				t.Fatalf("Test %s:\tShould receive a status code of 401: got %d", tst.name, status)
+			}
+		}
+
+		t.Run(tst.name, f)
+	}
+}
@@ -8,7 +8,15 @@ import (
 )
 
 // Cors sets the response headers needed for Cross-Origin Resource Sharing
-func Cors(origin string) web.Middleware {
+// when the request origin is in the allowed list of origins. The origin "*"
+// allows any origin. Requests from any other origin receive no CORS headers
+// so the browser denies them. Preflight requests are answered here and never
+// reach the handler.
+func Cors(allowedOrigins []string) web.Middleware {
+	allowed := make(map[string]bool)
+	for _, origin := range allowedOrigins {
+		allowed[origin] = true
+	}
 
 	// This is the actual middleware function to be executed.
 	m := func(handler web.Handler) web.Handler {
@@ -16,10 +24,20 @@ func Cors(origin string) web.Middleware {
 		// Create the handler that will be attached in the middleware chain.
 		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
 
-			// Set the CORS headers to the response.
-			w.Header().Set("Access-Control-Allow-Origin", origin)
-			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PATCH, PUT, DELETE, OPTIONS")
-			w.Header().Set("Access-Control-Allow-Headers", "Origin, Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
+			// Set the CORS headers to the response, echoing back the origin
+			// only when it's allowed.
+			origin := r.Header.Get("Origin")
+			if origin != "" && (allowed[origin] || allowed["*"]) {
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+				w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PATCH, PUT, DELETE, OPTIONS")
+				w.Header().Set("Access-Control-Allow-Headers", "Origin, Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
+				w.Header().Add("Vary", "Origin")
+			}
+
+			// Answer the preflight request.
+			if r.Method == http.MethodOptions {
+				return web.Respond(ctx, w, nil, http.StatusNoContent)
+			}
 
 			// Call the next handler.
 			return handler(ctx, w, r)
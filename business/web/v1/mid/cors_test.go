@@ -0,0 +1,78 @@
+package mid_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/andrewyang17/blockchain/business/web/v1/mid"
+)
+
+func Test_Cors(t *testing.T) {
+	var called bool
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		called = true
+		return nil
+	}
+
+	h := mid.Cors([]string{"https://wallet.example.com"})(handler)
+
+	type test struct {
+		name   string
+		origin string
+		exp    string
+	}
+
+	tt := []test{
+		{name: "allowed", origin: "https://wallet.example.com", exp: "https://wallet.example.com"},
+		{name: "denied", origin: "https://evil.example.com", exp: ""},
+		{name: "no origin", origin: "", exp: ""},
+	}
+
+	for _, tst := range tt {
+		f := func(t *testing.T) {
+			called = false
+
+			r := httptest.NewRequest(http.MethodOptions, "/v1/genesis", nil)
+			r.Header.Set("Origin", tst.origin)
+			r.Header.Set("Access-Control-Request-Method", http.MethodGet)
+			w := httptest.NewRecorder()
+
+			if err := h(context.Background(), w, r); err != nil {
+				t.Fatalf("Test %s:\tShould be able to handle the preflight: %s", tst.name, err)
+			}
+
+			if w.Code != http.StatusNoContent {
+				t.Fatalf("Test %s:\tShould receive a status code of 204: got %d", tst.name, w.Code)
+			}
+			if called {
+				t.Fatalf("Test %s:\tShould not call the handler for a preflight", tst.name)
+			}
+
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != tst.exp {
+				t.Fatalf("Test %s:\tShould get the right allowed origin: got %q, exp %q", tst.name, got, tst.exp)
+			}
+
+			methods := w.Header().Get("Access-Control-Allow-Methods")
+			if tst.exp != "" && methods == "" {
+				t.Fatalf("Test %s:\tShould get the allowed methods", tst.name)
+			}
+			if tst.exp == "" && methods != "" {
+				t.Fatalf("Test %s:\tShould not get the allowed methods: got %q", tst.name, methods)
+			}
+		}
+
+		t.Run(tst.name, f)
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/v1/genesis", nil)
+	r.Header.Set("Origin", "https://wallet.example.com")
+	w := httptest.NewRecorder()
+	if err := h(context.Background(), w, r); err != nil {
+		t.Fatalf("Should be able to handle the request: %s", err)
+	}
+	if !called {
+		t.Fatalf("Should call the handler for a non preflight request")
+	}
+}
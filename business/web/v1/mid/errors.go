@@ -5,7 +5,6 @@ import (
 	"errors"
 	"net/http"
 
-	"github.com/andrewyang17/blockchain/business/sys/validate"
 	v1Web "github.com/andrewyang17/blockchain/business/web/v1"
 	"github.com/andrewyang17/blockchain/foundation/web"
 	"go.uber.org/zap"
@@ -35,39 +34,20 @@ func Errors(log *zap.SugaredLogger) web.Middleware {
 				// Log the error.
 				log.Errorw("ERROR", "traceid", v.TraceID, "ERROR", err)
 
-				// Build out the error response.
-				var er v1Web.ErrorResponse
-				var status int
-				switch {
-				case validate.IsFieldErrors(err):
-					fieldErrors := validate.GetFieldErrors(err)
-					er = v1Web.ErrorResponse{
-						Error:  "data validation error",
-						Fields: fieldErrors.Fields(),
-					}
-					status = http.StatusBadRequest
-
-				case v1Web.IsRequestError(err):
-					reqErr := v1Web.GetRequestError(err)
-					er = v1Web.ErrorResponse{
-						Error: reqErr.Error(),
-					}
-					status = reqErr.Status
-
-				default:
-					er = v1Web.ErrorResponse{
-						Error: http.StatusText(http.StatusInternalServerError),
-					}
-					status = http.StatusInternalServerError
-				}
+				// A handler that fails part way through streaming a response
+				// has already sent the status, so there is nothing left to
+				// tell the client. Writing the error would only corrupt the
+				// body that was already sent.
+				if v.StatusCode == 0 {
 
-				// Respond with the error back to the client.
-				if err := web.Respond(ctx, w, er, status); err != nil {
+					// Respond with the error back to the client.
+					if err := v1Web.RespondError(ctx, w, err); err != nil {
 
-					// If we get this error, it means the event handler
-					// has completed.
-					if !errors.Is(err, http.ErrHijacked) {
-						return err
+						// If we get this error, it means the event handler
+						// has completed.
+						if !errors.Is(err, http.ErrHijacked) {
+							return err
+						}
 					}
 				}
 
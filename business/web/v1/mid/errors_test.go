@@ -0,0 +1,93 @@
+package mid_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	v1Web "github.com/andrewyang17/blockchain/business/web/v1"
+	"github.com/andrewyang17/blockchain/business/web/v1/mid"
+	"github.com/andrewyang17/blockchain/foundation/web"
+	"go.uber.org/zap"
+)
+
+func Test_Errors(t *testing.T) {
+	type test struct {
+		name   string
+		err    error
+		status int
+		msg    string
+	}
+
+	tt := []test{
+		{name: "request error", err: v1Web.NewRequestError(errors.New("not found"), http.StatusNotFound), status: http.StatusNotFound, msg: "not found"},
+		{name: "zero status", err: v1Web.NewRequestError(errors.New("no status"), 0), status: http.StatusInternalServerError, msg: "no status"},
+		{name: "success status", err: v1Web.NewRequestError(errors.New("looks fine"), http.StatusOK), status: http.StatusInternalServerError, msg: "looks fine"},
+		{name: "unexpected error", err: errors.New("database is down"), status: http.StatusInternalServerError, msg: http.StatusText(http.StatusInternalServerError)},
+	}
+
+	for _, tst := range tt {
+		f := func(t *testing.T) {
+			handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+				return tst.err
+			}
+
+			app := web.NewApp(nil, mid.Errors(zap.NewNop().Sugar()))
+			app.Handle(http.MethodGet, "v1", "/fail", handler)
+
+			r := httptest.NewRequest(http.MethodGet, "/v1/fail", nil)
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, r)
+
+			if w.Code != tst.status {
+				t.Fatalf("Test %s:\tShould receive a status code of %d: got %d", tst.name, tst.status, w.Code)
+			}
+
+			if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+				t.Fatalf("Test %s:\tShould get a JSON content type: got %q", tst.name, ct)
+			}
+
+			var body map[string]any
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("Test %s:\tShould get back a JSON body: %s", tst.name, err)
+			}
+			if len(body) != 1 || body["error"] != tst.msg {
+				t.Fatalf("Test %s:\tShould get back only the error %q: got %v", tst.name, tst.msg, body)
+			}
+		}
+
+		t.Run(tst.name, f)
+	}
+}
+
+func Test_ErrorsAfterResponse(t *testing.T) {
+
+	// The handler fails after it has started streaming a response.
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		web.SetStatusCode(ctx, http.StatusOK)
+		w.WriteHeader(http.StatusOK)
+		if _, err := io.WriteString(w, "["); err != nil {
+			return err
+		}
+		return errors.New("block missing")
+	}
+
+	app := web.NewApp(nil, mid.Errors(zap.NewNop().Sugar()))
+	app.Handle(http.MethodGet, "v1", "/stream", handler)
+
+	r := httptest.NewRequest(http.MethodGet, "/v1/stream", nil)
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Should keep the status that was already sent: got %d", w.Code)
+	}
+
+	if body := w.Body.String(); body != "[" {
+		t.Fatalf("Should not write an error into the streamed body: got %q", body)
+	}
+}
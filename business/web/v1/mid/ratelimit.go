@@ -0,0 +1,142 @@
+package mid
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	v1Web "github.com/andrewyang17/blockchain/business/web/v1"
+	"github.com/andrewyang17/blockchain/foundation/web"
+)
+
+// Set of values for keeping the number of client buckets in check.
+const (
+	maxBuckets    = 10_000           // Buckets kept before a sweep is forced.
+	sweepInterval = time.Minute      // How often the buckets are swept.
+	maxIdle       = 10 * time.Minute // How long an unused bucket is kept.
+)
+
+// RateLimit restricts the number of requests a client can make using the
+// token buckets of the rate limiter. Each request takes one token. Requests
+// made when the client's bucket is empty are rejected with a 429.
+func RateLimit(rl *RateLimiter) web.Middleware {
+
+	// This is the actual middleware function to be executed.
+	m := func(handler web.Handler) web.Handler {
+
+		// Create the handler that will be attached in the middleware chain.
+		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			if !rl.Allow(r, 1) {
+				return v1Web.NewRequestError(errors.New("rate limit exceeded"), http.StatusTooManyRequests)
+			}
+
+			// Call the next handler.
+			return handler(ctx, w, r)
+		}
+
+		return h
+	}
+
+	return m
+}
+
+// =============================================================================
+
+// bucket represents the tokens a single client has left.
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// RateLimiter maintains a token bucket for each client keyed by the client
+// IP. Each bucket holds up to burst tokens and is refilled at rate tokens per
+// second. A single rate limiter can be shared by several routes so they draw
+// from the same buckets. A burst of zero turns off the rate limiting.
+type RateLimiter struct {
+	mu        sync.Mutex
+	rate      float64
+	burst     float64
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// NewRateLimiter constructs a rate limiter for use.
+func NewRateLimiter(rate float64, burst int) *RateLimiter {
+	return &RateLimiter{
+		rate:    rate,
+		burst:   float64(burst),
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// Allow takes n tokens from the bucket of the client making the request and
+// reports if the client had that many tokens left. Nothing is taken when the
+// client doesn't have enough tokens.
+func (rl *RateLimiter) Allow(r *http.Request, n int) bool {
+	if rl == nil || rl.burst == 0 {
+		return true
+	}
+
+	return rl.allow(clientIP(r), n, time.Now())
+}
+
+// allow refills the client's bucket based on the time since the last request
+// and takes n tokens if they are available.
+func (rl *RateLimiter) allow(key string, n int, now time.Time) bool {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	{
+		if len(rl.buckets) >= maxBuckets || now.Sub(rl.lastSweep) >= sweepInterval {
+			rl.sweep(now)
+		}
+
+		b, exists := rl.buckets[key]
+		if !exists {
+			b = &bucket{tokens: rl.burst, last: now}
+			rl.buckets[key] = b
+		}
+
+		b.tokens += now.Sub(b.last).Seconds() * rl.rate
+		if b.tokens > rl.burst {
+			b.tokens = rl.burst
+		}
+		b.last = now
+
+		if b.tokens < float64(n) {
+			return false
+		}
+
+		b.tokens -= float64(n)
+		return true
+	}
+}
+
+// sweep removes the buckets that would be full by now, since those clients
+// are treated the same as a new client, and the buckets that haven't been
+// used for maxIdle. Dropping idle buckets keeps the map from growing without
+// bound when a slow refill rate keeps the buckets from filling up, at the
+// cost of a client that stays away that long starting over with a full
+// bucket.
+func (rl *RateLimiter) sweep(now time.Time) {
+	rl.lastSweep = now
+
+	for key, b := range rl.buckets {
+		idle := now.Sub(b.last)
+		if idle >= maxIdle || b.tokens+idle.Seconds()*rl.rate >= rl.burst {
+			delete(rl.buckets, key)
+		}
+	}
+}
+
+// clientIP returns the IP address of the client making the request.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
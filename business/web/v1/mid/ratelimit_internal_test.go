@@ -0,0 +1,37 @@
+package mid
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_RateLimitSweep(t *testing.T) {
+	now := time.Now()
+
+	// The refill rate is so slow an empty bucket never refills in the test.
+	rl := NewRateLimiter(0.000001, 5)
+
+	if !rl.allow("10.0.0.1", 5, now) {
+		t.Fatal("Should allow taking the full burst.")
+	}
+
+	// A sweep before the bucket has been idle long enough keeps it.
+	if !rl.allow("10.0.0.2", 1, now.Add(sweepInterval)) {
+		t.Fatal("Should allow a request from a new client.")
+	}
+	if _, exists := rl.buckets["10.0.0.1"]; !exists {
+		t.Fatal("Should keep an empty bucket that hasn't been idle long enough.")
+	}
+
+	// A sweep after the bucket has been idle too long removes it even
+	// though it hasn't refilled.
+	if !rl.allow("10.0.0.3", 1, now.Add(maxIdle)) {
+		t.Fatal("Should allow a request from a new client.")
+	}
+	if _, exists := rl.buckets["10.0.0.1"]; exists {
+		t.Fatal("Should remove a bucket that has been idle too long.")
+	}
+	if _, exists := rl.buckets["10.0.0.3"]; !exists {
+		t.Fatal("Should keep the bucket of the client making the request.")
+	}
+}
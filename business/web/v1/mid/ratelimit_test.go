@@ -0,0 +1,98 @@
+package mid_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	v1Web "github.com/andrewyang17/blockchain/business/web/v1"
+	"github.com/andrewyang17/blockchain/business/web/v1/mid"
+)
+
+func Test_RateLimit(t *testing.T) {
+	const burst = 5
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return nil
+	}
+
+	h := mid.RateLimit(mid.NewRateLimiter(0.001, burst))(handler)
+
+	send := func(remoteAddr string) error {
+		r := httptest.NewRequest(http.MethodPost, "/v1/node/tx/submit", nil)
+		r.RemoteAddr = remoteAddr
+		return h(context.Background(), httptest.NewRecorder(), r)
+	}
+
+	for i := 0; i < burst; i++ {
+		if err := send("10.0.0.1:4000"); err != nil {
+			t.Fatalf("Should allow request %d within the burst: %s", i, err)
+		}
+	}
+
+	err := send("10.0.0.1:4001")
+	if !v1Web.IsRequestError(err) {
+		t.Fatalf("Should reject the request after the burst: %v", err)
+	}
+	if status := v1Web.GetRequestError(err).Status; status != http.StatusTooManyRequests {
+		t.Fatalf("Should receive a status code of 429: got %d", status)
+	}
+
+	if err := send("10.0.0.2:4000"); err != nil {
+		t.Fatalf("Should allow a request from a different client: %s", err)
+	}
+}
+
+func Test_RateLimitShared(t *testing.T) {
+	const burst = 5
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return nil
+	}
+
+	rl := mid.NewRateLimiter(0.001, burst)
+	submit := mid.RateLimit(rl)(handler)
+	batch := mid.RateLimit(rl)(handler)
+
+	request := func(path string) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, path, nil)
+		r.RemoteAddr = "10.0.0.1:4000"
+		return r
+	}
+
+	for i := 0; i < burst; i++ {
+		if err := submit(context.Background(), httptest.NewRecorder(), request("/v1/node/tx/submit")); err != nil {
+			t.Fatalf("Should allow request %d within the burst: %s", i, err)
+		}
+	}
+
+	err := batch(context.Background(), httptest.NewRecorder(), request("/v1/node/tx/submit/batch"))
+	if !v1Web.IsRequestError(err) {
+		t.Fatalf("Should reject a request on the other route once the shared burst is used: %v", err)
+	}
+}
+
+func Test_RateLimitAllow(t *testing.T) {
+	rl := mid.NewRateLimiter(0.001, 5)
+
+	r := httptest.NewRequest(http.MethodPost, "/v1/node/tx/submit/batch", nil)
+	r.RemoteAddr = "10.0.0.1:4000"
+
+	if !rl.Allow(r, 3) {
+		t.Fatal("Should allow taking 3 tokens from a full bucket.")
+	}
+	if rl.Allow(r, 3) {
+		t.Fatal("Should not allow taking 3 tokens with 2 left.")
+	}
+	if !rl.Allow(r, 2) {
+		t.Fatal("Should allow taking the 2 tokens left after a rejected request.")
+	}
+	if rl.Allow(r, 1) {
+		t.Fatal("Should not allow taking a token from an empty bucket.")
+	}
+
+	if !mid.NewRateLimiter(0.001, 0).Allow(r, 1000) {
+		t.Fatal("Should allow every request with a burst of zero.")
+	}
+}
@@ -1,7 +1,14 @@
 // Package v1 represents types used by the web application for v1.
 package v1
 
-import "errors"
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/andrewyang17/blockchain/business/sys/validate"
+	"github.com/andrewyang17/blockchain/foundation/web"
+)
 
 // ErrorResponse is the form used for API responses from failures in the API.
 type ErrorResponse struct {
@@ -42,3 +49,41 @@ func GetRequestError(err error) *RequestError {
 	}
 	return re
 }
+
+// RespondError sends the error to the client as an ErrorResponse. The status
+// is always a client or server error status so a failure can never look like
+// a success, and errors that aren't expected hide their details behind a 500.
+func RespondError(ctx context.Context, w http.ResponseWriter, err error) error {
+	var er ErrorResponse
+	var status int
+	switch {
+	case validate.IsFieldErrors(err):
+		fieldErrors := validate.GetFieldErrors(err)
+		er = ErrorResponse{
+			Error:  "data validation error",
+			Fields: fieldErrors.Fields(),
+		}
+		status = http.StatusBadRequest
+
+	case IsRequestError(err):
+		reqErr := GetRequestError(err)
+		er = ErrorResponse{
+			Error: reqErr.Error(),
+		}
+		status = reqErr.Status
+
+	default:
+		er = ErrorResponse{
+			Error: http.StatusText(http.StatusInternalServerError),
+		}
+		status = http.StatusInternalServerError
+	}
+
+	// A request error created with a status that isn't an error would send
+	// the client a success, so treat it as a server error instead.
+	if status < http.StatusBadRequest || status > 599 {
+		status = http.StatusInternalServerError
+	}
+
+	return web.Respond(ctx, w, er, status)
+}
\ No newline at end of file
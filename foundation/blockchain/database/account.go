@@ -2,7 +2,6 @@ package database
 
 import (
 	"crypto/ecdsa"
-	"errors"
 
 	"github.com/ethereum/go-ethereum/crypto"
 )
@@ -43,7 +42,19 @@ func (a AccountID) IsAccountID() bool {
 
 // PublicKeyToAccountID converts the public key to an account value.
 func PublicKeyToAccountID(pk ecdsa.PublicKey) AccountID {
-	return AccountID(crypto.PubkeyToAddress(pk).String())
+	return AccountIDFromPubKey(&pk)
+}
+
+// AccountIDFromPubKey derives the account for the public key. The account
+// is the checksummed hex encoding of the last 20 bytes of the Keccak256 hash
+// of the public key, which is the format IsAccountID accepts.
+func AccountIDFromPubKey(pk *ecdsa.PublicKey) AccountID {
+	return AccountID(crypto.PubkeyToAddress(*pk).String())
+}
+
+// AccountIDFromPrivateKey derives the account for the private key.
+func AccountIDFromPrivateKey(pk *ecdsa.PrivateKey) AccountID {
+	return AccountIDFromPubKey(&pk.PublicKey)
 }
 
 // ToAccountID converts a hex-encoded string to an account and validates the
@@ -51,7 +62,7 @@ func PublicKeyToAccountID(pk ecdsa.PublicKey) AccountID {
 func ToAccountID(hex string) (AccountID, error) {
 	a := AccountID(hex)
 	if !a.IsAccountID() {
-		return "", errors.New("invalid account format")
+		return "", ErrMalformedAccount
 	}
 
 	return a, nil
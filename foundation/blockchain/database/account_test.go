@@ -0,0 +1,37 @@
+package database_test
+
+import (
+	"testing"
+
+	"github.com/andrewyang17/blockchain/foundation/blockchain/database"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func Test_AccountIDFromKey(t *testing.T) {
+	pk, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Should be able to generate a private key: %s", err)
+	}
+
+	accountID := database.AccountIDFromPrivateKey(pk)
+	if !accountID.IsAccountID() {
+		t.Fatalf("Should derive a valid account: %s", accountID)
+	}
+
+	if got := database.AccountIDFromPubKey(&pk.PublicKey); got != accountID {
+		t.Fatalf("Should derive the same account from the public key: got %s, exp %s", got, accountID)
+	}
+
+	if got, err := database.ToAccountID(string(accountID)); err != nil || got != accountID {
+		t.Fatalf("Should round trip the account through ToAccountID: got %s, %v", got, err)
+	}
+
+	pk, err = crypto.HexToECDSA(keyKennedy)
+	if err != nil {
+		t.Fatalf("Should be able to load the private key: %s", err)
+	}
+
+	if got := database.AccountIDFromPrivateKey(pk); got != idKennedy {
+		t.Fatalf("Should derive the known account for the key: got %s, exp %s", got, idKennedy)
+	}
+}
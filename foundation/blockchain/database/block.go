@@ -52,6 +52,59 @@ func ToBlock(blockData BlockData) (Block, error) {
 	return block, nil
 }
 
+// ValidateHash recomputes the merkle root of the transactions and the hash
+// of the header and checks them against the values stored in the block.
+func (bd BlockData) ValidateHash() error {
+	block, err := ToBlock(bd)
+	if err != nil {
+		return fmt.Errorf("unable to build merkle tree: %w", err)
+	}
+
+	if root := block.MerkleTree.RootHex(); root != bd.Header.TransRoot {
+		return fmt.Errorf("merkle root does not match transactions, got %s, exp %s", root, bd.Header.TransRoot)
+	}
+
+	if hash := block.Hash(); hash != bd.Hash {
+		return fmt.Errorf("block hash does not match header, got %s, exp %s", hash, bd.Hash)
+	}
+
+	return nil
+}
+
+// Copy makes a deep copy of the block so changes made to the copy are not
+// shared with the original. Storage implementations that keep blocks in
+// memory use this to hand out and store their own copies.
+func (bd BlockData) Copy() BlockData {
+	cpy := bd
+
+	if bd.Trans != nil {
+		cpy.Trans = make([]BlockTx, len(bd.Trans))
+		for i, tx := range bd.Trans {
+			if tx.Data != nil {
+				tx.Data = append([]byte{}, tx.Data...)
+			}
+			if tx.Outputs != nil {
+				tx.Outputs = append([]Output{}, tx.Outputs...)
+			}
+			tx.V = copyBigInt(tx.V)
+			tx.R = copyBigInt(tx.R)
+			tx.S = copyBigInt(tx.S)
+
+			cpy.Trans[i] = tx
+		}
+	}
+
+	return cpy
+}
+
+// copyBigInt returns a copy of the value or nil when the value is nil.
+func copyBigInt(v *big.Int) *big.Int {
+	if v == nil {
+		return nil
+	}
+	return new(big.Int).Set(v)
+}
+
 // =============================================================================
 
 // BlockHeader represents common information required for each block.
@@ -73,15 +126,18 @@ type Block struct {
 	MerkleTree *merkle.Tree[BlockTx]
 }
 
-// POWArgs represents the set of arguments required to run POW.
+// POWArgs represents the set of arguments required to run POW. When the
+// TargetBlockTime is set, the Difficulty is only used for the first block
+// and later blocks adjust the difficulty of their parent.
 type POWArgs struct {
-	BeneficiaryID AccountID
-	Difficulty    uint16
-	MiningReward  uint64
-	PrevBlock     Block
-	StateRoot     string
-	Trans         []BlockTx
-	EvHandler     func(v string, args ...any)
+	BeneficiaryID   AccountID
+	Difficulty      uint16
+	TargetBlockTime time.Duration
+	MiningReward    uint64
+	PrevBlock       Block
+	StateRoot       string
+	Trans           []BlockTx
+	EvHandler       func(v string, args ...any)
 }
 
 // POW constructs a new Block and performs the work to find a nonce that
@@ -97,13 +153,25 @@ func POW(ctx context.Context, args POWArgs) (Block, error) {
 		return Block{}, err
 	}
 
+	// The timestamp must be after the parent's, even when the parent was
+	// mined within the same millisecond.
+	timeStamp := uint64(time.Now().UTC().UnixMilli())
+	if timeStamp <= args.PrevBlock.Header.TimeStamp {
+		timeStamp = args.PrevBlock.Header.TimeStamp + 1
+	}
+
+	difficulty := args.Difficulty
+	if args.TargetBlockTime > 0 && args.PrevBlock.Header.Number > 0 {
+		difficulty = AdjustDifficulty(args.PrevBlock.Header, blockTime(args.PrevBlock.Header, timeStamp), args.TargetBlockTime)
+	}
+
 	block := Block{
 		Header: BlockHeader{
 			Number:        args.PrevBlock.Header.Number + 1,
 			PrevBlockHash: prevBlockHash,
-			TimeStamp:     uint64(time.Now().UTC().UnixMilli()),
+			TimeStamp:     timeStamp,
 			BeneficiaryID: args.BeneficiaryID,
-			Difficulty:    args.Difficulty,
+			Difficulty:    difficulty,
 			MiningReward:  args.MiningReward,
 			StateRoot:     args.StateRoot,
 			TransRoot:     tree.RootHex(),
@@ -188,7 +256,7 @@ func (b Block) Hash() string {
 }
 
 // ValidateBlock takes a block and validates it to be included into the blockchain.
-func (b Block) ValidateBlock(previousBlock Block, stateRoot string, evHandler func(v string, args ...any)) error {
+func (b Block) ValidateBlock(previousBlock Block, stateRoot string, targetBlockTime time.Duration, evHandler func(v string, args ...any)) error {
 	evHandler("database: ValidateBlock: validate: blk[%d]: check: chain is not forked", b.Header.Number)
 
 	// The node who sent this block has a chain that is two or more blocks ahead
@@ -198,10 +266,21 @@ func (b Block) ValidateBlock(previousBlock Block, stateRoot string, evHandler fu
 		return ErrChainForked
 	}
 
-	evHandler("database: ValidateBlock: validate: blk[%d]: check: block difficulty is the same or greater than parent block difficulty", b.Header.Number)
+	switch {
+	case targetBlockTime > 0 && previousBlock.Header.Number > 0:
+		evHandler("database: ValidateBlock: validate: blk[%d]: check: block difficulty is the parent block difficulty adjusted for the block time", b.Header.Number)
+
+		exp := AdjustDifficulty(previousBlock.Header, blockTime(previousBlock.Header, b.Header.TimeStamp), targetBlockTime)
+		if b.Header.Difficulty != exp {
+			return fmt.Errorf("block difficulty is not the adjusted parent block difficulty, got %d, exp %d", b.Header.Difficulty, exp)
+		}
+
+	default:
+		evHandler("database: ValidateBlock: validate: blk[%d]: check: block difficulty is the same or greater than parent block difficulty", b.Header.Number)
 
-	if b.Header.Difficulty < previousBlock.Header.Difficulty {
-		return fmt.Errorf("block difficulty is less than previous block difficulty, parent %d, block %d", previousBlock.Header.Difficulty, b.Header.Difficulty)
+		if b.Header.Difficulty < previousBlock.Header.Difficulty {
+			return fmt.Errorf("block difficulty is less than previous block difficulty, parent %d, block %d", previousBlock.Header.Difficulty, b.Header.Difficulty)
+		}
 	}
 
 	evHandler("database: ValidateBlock: validate: blk[%d]: check: block hash has been solved", b.Header.Number)
@@ -223,23 +302,10 @@ func (b Block) ValidateBlock(previousBlock Block, stateRoot string, evHandler fu
 		return fmt.Errorf("parent block hash doesn't match our known parent, got %s, exp %s", b.Header.PrevBlockHash, previousBlock.Hash())
 	}
 
-	if previousBlock.Header.TimeStamp > 0 {
-		evHandler("database: ValidateBlock: validate: blk[%d]: check: block's timestamp is greater than parent block's timestamp", b.Header.Number)
-
-		parentTime := time.Unix(int64(previousBlock.Header.TimeStamp), 0)
-		blockTime := time.Unix(int64(b.Header.TimeStamp), 0)
-		if blockTime.Before(parentTime) {
-			return fmt.Errorf("block timestamp is before parent block, parent %s, block %s", parentTime, blockTime)
-		}
-
-		// This is a check that Ethereum does but we can't because we don't run all the time.
+	evHandler("database: ValidateBlock: validate: blk[%d]: check: block's timestamp is after parent block's timestamp and not too far in the future", b.Header.Number)
 
-		// evHandler("database: ValidateBlock: validate: blk[%d]: check: block is less than 15 minutes apart from parent block", b.Header.Number)
-
-		// dur := blockTime.Sub(parentTime)
-		// if dur.Seconds() > time.Duration(15*time.Second).Seconds() {
-		// 	return fmt.Errorf("block is older than 15 minutes, duration %v", dur)
-		// }
+	if err := ValidateTimestamp(previousBlock.Header, b.Header, MaxTimestampDrift); err != nil {
+		return err
 	}
 
 	evHandler("database: ValidateBlock: validate: blk[%d]: check: state root hash does match current database", b.Header.Number)
@@ -257,6 +323,68 @@ func (b Block) ValidateBlock(previousBlock Block, stateRoot string, evHandler fu
 	return nil
 }
 
+// MaxDifficulty is the highest difficulty a block can have. The difficulty is
+// the number of leading zeros the block hash needs.
+const MaxDifficulty = 17
+
+// AdjustDifficulty returns the difficulty for the block after prev. The
+// difficulty goes up by one when prev's block was mined faster than the
+// target block time and down by one when it was slower, staying between 1
+// and MaxDifficulty. A target of zero or less keeps the difficulty of prev.
+func AdjustDifficulty(prev BlockHeader, actualBlockTime, targetBlockTime time.Duration) uint16 {
+	if targetBlockTime <= 0 {
+		return prev.Difficulty
+	}
+
+	difficulty := int(prev.Difficulty)
+	switch {
+	case actualBlockTime < targetBlockTime:
+		difficulty++
+	case actualBlockTime > targetBlockTime:
+		difficulty--
+	}
+
+	switch {
+	case difficulty < 1:
+		return 1
+	case difficulty > MaxDifficulty:
+		return MaxDifficulty
+	}
+
+	return uint16(difficulty)
+}
+
+// blockTime returns the time between the parent block and the timestamp of
+// the block being mined or validated.
+func blockTime(prev BlockHeader, timeStamp uint64) time.Duration {
+	if timeStamp <= prev.TimeStamp {
+		return 0
+	}
+	return time.Duration(timeStamp-prev.TimeStamp) * time.Millisecond
+}
+
+// MaxTimestampDrift is how far into the future of the node's clock a block
+// timestamp can be and still be accepted.
+const MaxTimestampDrift = 2 * time.Hour
+
+// ValidateTimestamp checks the current block's timestamp is strictly after
+// its parent's timestamp and not more than maxDrift past the node's clock.
+// A parent without a timestamp, like the genesis parent, isn't compared.
+func ValidateTimestamp(prev, current BlockHeader, maxDrift time.Duration) error {
+	blockTime := time.UnixMilli(int64(current.TimeStamp))
+
+	if prev.TimeStamp > 0 && current.TimeStamp <= prev.TimeStamp {
+		parentTime := time.UnixMilli(int64(prev.TimeStamp))
+		return fmt.Errorf("block timestamp is not after parent block, parent %s, block %s", parentTime, blockTime)
+	}
+
+	if limit := time.Now().Add(maxDrift); blockTime.After(limit) {
+		return fmt.Errorf("block timestamp is too far in the future, block %s, max %s", blockTime, limit)
+	}
+
+	return nil
+}
+
 // isHashSolved checks the hash to make sure it complies with
 // the POW rules. We need to match a difficulty number of 0's.
 func isHashSolved(difficulty uint16, hash string) bool {
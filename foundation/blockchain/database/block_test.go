@@ -0,0 +1,208 @@
+package database_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/andrewyang17/blockchain/foundation/blockchain/database"
+)
+
+func Test_ValidateHash(t *testing.T) {
+	blockData := newBlockData(t)
+
+	if err := blockData.ValidateHash(); err != nil {
+		t.Fatalf("Should be able to validate the block: %s", err)
+	}
+
+	tampered := newBlockData(t)
+	tampered.Trans[0].Value = 1_000_000
+
+	if err := tampered.ValidateHash(); err == nil {
+		t.Fatalf("Should not be able to validate a block with a tampered transaction")
+	}
+
+	tampered = newBlockData(t)
+	tampered.Header.Nonce++
+
+	if err := tampered.ValidateHash(); err == nil {
+		t.Fatalf("Should not be able to validate a block with a tampered header")
+	}
+}
+
+func Test_Copy(t *testing.T) {
+	blockData := database.BlockData{
+		Trans: []database.BlockTx{
+			{
+				SignedTx: database.SignedTx{
+					Tx: database.Tx{
+						Data:    []byte("data"),
+						Outputs: []database.Output{{ToID: idPavel, Value: 10}},
+					},
+					V: big.NewInt(1),
+				},
+			},
+		},
+	}
+
+	cpy := blockData.Copy()
+	cpy.Trans[0].Value = 100
+	cpy.Trans[0].Data[0] = 'x'
+	cpy.Trans[0].Outputs[0].Value = 20
+	cpy.Trans[0].V.SetInt64(2)
+
+	tx := blockData.Trans[0]
+	if tx.Value != 0 {
+		t.Fatalf("Should not share the transaction with the copy: got value %d", tx.Value)
+	}
+	if string(tx.Data) != "data" {
+		t.Fatalf("Should not share the data with the copy: got %q", tx.Data)
+	}
+	if tx.Outputs[0].Value != 10 {
+		t.Fatalf("Should not share the outputs with the copy: got value %d", tx.Outputs[0].Value)
+	}
+	if tx.V.Int64() != 1 {
+		t.Fatalf("Should not share the signature with the copy: got v %d", tx.V.Int64())
+	}
+}
+
+// newBlockData mines a block with two transactions at no difficulty.
+func newBlockData(t *testing.T) database.BlockData {
+	var trans []database.BlockTx
+	for _, signedTx := range signedTxs(t, 2) {
+		trans = append(trans, database.NewBlockTx(signedTx, 0, 0))
+	}
+
+	block, err := database.POW(context.Background(), database.POWArgs{
+		BeneficiaryID: idPavel,
+		PrevBlock:     database.Block{},
+		Trans:         trans,
+		EvHandler:     func(v string, args ...any) {},
+	})
+	if err != nil {
+		t.Fatalf("Should be able to mine the block: %s", err)
+	}
+
+	return database.NewBlockData(block)
+}
+
+func Test_ValidateTimestamp(t *testing.T) {
+	now := uint64(time.Now().UnixMilli())
+
+	type table struct {
+		name    string
+		prev    uint64
+		current uint64
+		valid   bool
+	}
+
+	tt := []table{
+		{name: "in order", prev: now - 1_000, current: now, valid: true},
+		{name: "no parent timestamp", prev: 0, current: now, valid: true},
+		{name: "same timestamp", prev: now, current: now, valid: false},
+		{name: "backward", prev: now, current: now - 1_000, valid: false},
+		{name: "within drift", prev: now, current: now + uint64(time.Minute.Milliseconds()), valid: true},
+		{name: "far future", prev: now, current: now + uint64((3 * time.Hour).Milliseconds()), valid: false},
+	}
+
+	for _, tst := range tt {
+		f := func(t *testing.T) {
+			prev := database.BlockHeader{Number: 1, TimeStamp: tst.prev}
+			current := database.BlockHeader{Number: 2, TimeStamp: tst.current}
+
+			err := database.ValidateTimestamp(prev, current, database.MaxTimestampDrift)
+			if tst.valid && err != nil {
+				t.Fatalf("Test %s:\tShould accept the timestamp: %s", tst.name, err)
+			}
+			if !tst.valid && err == nil {
+				t.Fatalf("Test %s:\tShould reject the timestamp.", tst.name)
+			}
+		}
+
+		t.Run(tst.name, f)
+	}
+}
+
+func Test_AdjustDifficulty(t *testing.T) {
+	const target = 10 * time.Second
+
+	type table struct {
+		name       string
+		difficulty uint16
+		actual     time.Duration
+		target     time.Duration
+		exp        uint16
+	}
+
+	tt := []table{
+		{name: "faster", difficulty: 4, actual: 2 * time.Second, target: target, exp: 5},
+		{name: "slower", difficulty: 4, actual: 30 * time.Second, target: target, exp: 3},
+		{name: "on target", difficulty: 4, actual: target, target: target, exp: 4},
+		{name: "never zero", difficulty: 1, actual: time.Hour, target: target, exp: 1},
+		{name: "zero parent", difficulty: 0, actual: time.Hour, target: target, exp: 1},
+		{name: "max", difficulty: database.MaxDifficulty, actual: time.Millisecond, target: target, exp: database.MaxDifficulty},
+		{name: "no target", difficulty: 4, actual: time.Millisecond, target: 0, exp: 4},
+	}
+
+	for _, tst := range tt {
+		f := func(t *testing.T) {
+			prev := database.BlockHeader{Number: 1, Difficulty: tst.difficulty}
+
+			if got := database.AdjustDifficulty(prev, tst.actual, tst.target); got != tst.exp {
+				t.Fatalf("Test %s:\tShould get a difficulty of %d, got %d", tst.name, tst.exp, got)
+			}
+		}
+
+		t.Run(tst.name, f)
+	}
+}
+
+func Test_ValidateDifficulty(t *testing.T) {
+	const target = time.Hour
+	ev := func(v string, args ...any) {}
+
+	trans := []database.BlockTx{database.NewBlockTx(signedTxs(t, 1)[0], 0, 0)}
+
+	parent, err := database.POW(context.Background(), database.POWArgs{
+		BeneficiaryID:   idPavel,
+		Difficulty:      1,
+		TargetBlockTime: target,
+		Trans:           trans,
+		EvHandler:       ev,
+	})
+	if err != nil {
+		t.Fatalf("Should be able to mine the parent block: %s", err)
+	}
+
+	block, err := database.POW(context.Background(), database.POWArgs{
+		BeneficiaryID:   idPavel,
+		Difficulty:      1,
+		TargetBlockTime: target,
+		PrevBlock:       parent,
+		Trans:           trans,
+		EvHandler:       ev,
+	})
+	if err != nil {
+		t.Fatalf("Should be able to mine the block: %s", err)
+	}
+
+	if block.Header.Difficulty != 2 {
+		t.Fatalf("Should raise the difficulty for a block mined faster than the target, got %d", block.Header.Difficulty)
+	}
+
+	if err := block.ValidateBlock(parent, "", target, ev); err != nil {
+		t.Fatalf("Should be able to validate the block: %s", err)
+	}
+
+	// Without the target the parent difficulty is only a minimum.
+	if err := block.ValidateBlock(parent, "", 0, ev); err != nil {
+		t.Fatalf("Should be able to validate the block without a target: %s", err)
+	}
+
+	// A block that keeps the parent difficulty isn't following the target.
+	block.Header.Difficulty = 1
+	if err := block.ValidateBlock(parent, "", target, ev); err == nil {
+		t.Fatalf("Should not be able to validate a block with the wrong difficulty.")
+	}
+}
\ No newline at end of file
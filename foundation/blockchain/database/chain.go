@@ -0,0 +1,57 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/andrewyang17/blockchain/foundation/blockchain/signature"
+)
+
+// ErrBrokenChain is returned by VerifyChain when a block doesn't link to the
+// block before it. The error is returned wrapped with the block number.
+var ErrBrokenChain = errors.New("broken chain")
+
+// VerifyChain walks every block in the storage and checks the blocks are
+// numbered one after the other and each block's parent hash is the hash of
+// the block before it. Block 1 must have the zero hash as its parent. The
+// first block of a pruned chain can't be checked against its parent, so the
+// check starts with the block after it. The first broken link is returned
+// wrapped with ErrBrokenChain.
+//
+// CORE NOTE: Only the block headers are checked, the proof of work and the
+// transactions are not validated. The blocks are checked in the order the
+// storage iterates them, so a storage whose iterator stops at a missing block
+// only has the blocks before the missing one checked.
+func VerifyChain(storage Storage) error {
+	var prev BlockHeader
+
+	iter := storage.ForEach(context.Background())
+	for blockData, err := iter.Next(); !iter.Done(); blockData, err = iter.Next() {
+		if err != nil {
+			return fmt.Errorf("reading blocks: %w", err)
+		}
+
+		header := blockData.Header
+
+		switch {
+		case prev.Number == 0 && header.Number == 1:
+			if header.PrevBlockHash != signature.ZeroHash {
+				return fmt.Errorf("%w: block %d: parent hash is not the zero hash, got %s", ErrBrokenChain, header.Number, header.PrevBlockHash)
+			}
+
+		case prev.Number != 0:
+			if header.Number != prev.Number+1 {
+				return fmt.Errorf("%w: block %d: not the next number, exp %d", ErrBrokenChain, header.Number, prev.Number+1)
+			}
+
+			if exp := (Block{Header: prev}).Hash(); header.PrevBlockHash != exp {
+				return fmt.Errorf("%w: block %d: parent hash doesn't match block %d, got %s, exp %s", ErrBrokenChain, header.Number, prev.Number, header.PrevBlockHash, exp)
+			}
+		}
+
+		prev = header
+	}
+
+	return nil
+}
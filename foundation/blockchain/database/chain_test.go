@@ -0,0 +1,98 @@
+package database_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/andrewyang17/blockchain/foundation/blockchain/database"
+	"github.com/andrewyang17/blockchain/foundation/blockchain/signature"
+	"github.com/andrewyang17/blockchain/foundation/blockchain/storage/memory"
+)
+
+func Test_VerifyChain(t *testing.T) {
+	type table struct {
+		name    string
+		numbers []uint64
+		change  func(headers map[uint64]*database.BlockHeader)
+		broken  uint64
+	}
+
+	tt := []table{
+		{
+			name:    "well formed",
+			numbers: []uint64{1, 2, 3, 4},
+		},
+		{
+			name:    "pruned",
+			numbers: []uint64{3, 4, 5},
+		},
+		{
+			name:    "gap",
+			numbers: []uint64{1, 2, 4, 5},
+			broken:  4,
+		},
+		{
+			name:    "mismatched parent hash",
+			numbers: []uint64{1, 2, 3, 4},
+			change: func(headers map[uint64]*database.BlockHeader) {
+				headers[3].PrevBlockHash = signature.ZeroHash
+			},
+			broken: 3,
+		},
+		{
+			name:    "first block parent hash",
+			numbers: []uint64{1, 2},
+			change: func(headers map[uint64]*database.BlockHeader) {
+				headers[1].PrevBlockHash = "0x01"
+			},
+			broken: 1,
+		},
+	}
+
+	for _, tst := range tt {
+		f := func(t *testing.T) {
+			headers := make(map[uint64]*database.BlockHeader)
+
+			// Link each block to the block numbered before it, even across
+			// a gap, so only the gap is wrong.
+			prevHash := signature.ZeroHash
+			for _, num := range tst.numbers {
+				header := database.BlockHeader{Number: num, PrevBlockHash: prevHash, Nonce: num}
+				headers[num] = &header
+				prevHash = database.Block{Header: header}.Hash()
+			}
+
+			if tst.change != nil {
+				tst.change(headers)
+			}
+
+			storage := memory.New()
+			for _, num := range tst.numbers {
+				header := *headers[num]
+				if err := storage.Write(context.Background(), database.BlockData{Hash: database.Block{Header: header}.Hash(), Header: header}); err != nil {
+					t.Fatalf("Test %s:\tShould be able to write block %d: %s", tst.name, num, err)
+				}
+			}
+
+			err := database.VerifyChain(storage)
+			if tst.broken == 0 {
+				if err != nil {
+					t.Fatalf("Test %s:\tShould verify the chain: %s", tst.name, err)
+				}
+				return
+			}
+
+			if !errors.Is(err, database.ErrBrokenChain) {
+				t.Fatalf("Test %s:\tShould get ErrBrokenChain: got %v", tst.name, err)
+			}
+			if exp := "block " + strconv.FormatUint(tst.broken, 10) + ":"; !strings.Contains(err.Error(), exp) {
+				t.Fatalf("Test %s:\tShould report block %d as the broken link: got %v", tst.name, tst.broken, err)
+			}
+		}
+
+		t.Run(tst.name, f)
+	}
+}
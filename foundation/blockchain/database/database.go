@@ -3,6 +3,7 @@
 package database
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"sort"
@@ -12,23 +13,48 @@ import (
 	"github.com/andrewyang17/blockchain/foundation/blockchain/signature"
 )
 
+// StorageVersion is the version of the Storage interface. It is bumped when
+// the interface changes in a way that breaks existing implementations.
+// Version 2 added a context to the reads, writes, and iteration. Version 3
+// added the health check.
+const StorageVersion = 3
+
 // Storage interface represents the behavior required to be implemented by any
-// package providing support reading and writing the blockchain.
+// package providing support reading and writing the blockchain. The context
+// passed to ForEach is honored by every call to the iterator's Next.
 type Storage interface {
-	Write(blockData BlockData) error
-	GetBlock(num uint64) (BlockData, error)
-	ForEach() Iterator
+	Write(ctx context.Context, blockData BlockData) error
+	WriteBatch(ctx context.Context, blocks []BlockData) error
+	GetBlock(ctx context.Context, num uint64) (BlockData, error)
+	GetBlockByHash(ctx context.Context, hash string) (BlockData, error)
+	ForEach(ctx context.Context) Iterator
+	Healthy(ctx context.Context) error
 	Close() error
 	Reset() error
 }
 
+// ErrEndOfChain is returned by the Next method of an iterator once there are
+// no more blocks, which is the normal end of an iteration.
+var ErrEndOfChain = errors.New("end of chain")
+
 // Iterator interface represents the behavior required to be implemented by any
-// package providing support to iterate over the blocks.
+// package providing support to iterate over the blocks. Done reports true once
+// Next has reached the end of the blocks, after which Next returns
+// ErrEndOfChain. A Next that fails, like when the context is cancelled, returns
+// that error once and then the iteration is done.
 type Iterator interface {
 	Next() (BlockData, error)
 	Done() bool
 }
 
+// HeaderIterator interface represents the behavior required to iterate over
+// the block headers without the transactions. It ends the same way as an
+// Iterator.
+type HeaderIterator interface {
+	Next() (BlockHeader, error)
+	Done() bool
+}
+
 // =============================================================================
 
 // Database manages data related to accounts who have transacted on the blockchain.
@@ -59,14 +85,14 @@ func New(genesis genesis.Genesis, storage Storage, evHandler func(v string, args
 	}
 
 	// Read all the blocks from storage.
-	iter := db.ForEach()
+	iter := db.ForEach(context.Background())
 	for block, err := iter.Next(); !iter.Done(); block, err = iter.Next() {
 		if err != nil {
 			return nil, err
 		}
 
 		// Validate the block values and cryptographic audit trail.
-		if err := block.ValidateBlock(db.latestBlock, db.HashState(), evHandler); err != nil {
+		if err := block.ValidateBlock(db.latestBlock, db.HashState(), genesis.TargetBlockTime(), evHandler); err != nil {
 			return nil, err
 		}
 
@@ -90,6 +116,11 @@ func (db *Database) Close() {
 	db.storage.Close()
 }
 
+// Healthy checks the storage is able to take writes.
+func (db *Database) Healthy(ctx context.Context) error {
+	return db.storage.Healthy(ctx)
+}
+
 // Reset re-initializes the database back to the genesis state.
 func (db *Database) Reset() error {
 	db.mu.Lock()
@@ -138,6 +169,45 @@ func (db *Database) Query(accountID AccountID) (Account, error) {
 	}
 }
 
+// Nonces returns the current nonce for each of the specified accounts that
+// exists in the database, taking the lock once for the whole set.
+func (db *Database) Nonces(accountIDs []AccountID) map[AccountID]uint64 {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	{
+		nonces := make(map[AccountID]uint64, len(accountIDs))
+		for _, accountID := range accountIDs {
+			if account, exists := db.accounts[accountID]; exists {
+				nonces[accountID] = account.Nonce
+			}
+		}
+
+		return nonces
+	}
+}
+
+// Clone returns a copy of the accounts and latest block that isn't backed by
+// storage. Blocks and transactions can be applied to the copy to see their
+// effect without changing the database. The copy can't be written to.
+func (db *Database) Clone() *Database {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	{
+		accounts := make(map[AccountID]Account, len(db.accounts))
+		for accountID, account := range db.accounts {
+			accounts[accountID] = account
+		}
+
+		clone := Database{
+			genesis:     db.genesis,
+			latestBlock: db.latestBlock,
+			accounts:    accounts,
+		}
+
+		return &clone
+	}
+}
+
 // Copy makes a copy of the current accounts in the database.
 func (db *Database) Copy() map[AccountID]Account {
 	db.mu.RLock()
@@ -182,7 +252,8 @@ func (db *Database) ApplyMiningReward(block Block) {
 }
 
 // ApplyTransaction performs the business logic for applying a transaction
-// to the database.
+// to the database. Every payment of a multi output transaction is applied,
+// or none are when the account can't fund the total.
 func (db *Database) ApplyTransaction(block Block, tx BlockTx) error {
 	db.mu.Lock()
 	defer db.mu.Unlock()
@@ -193,11 +264,6 @@ func (db *Database) ApplyTransaction(block Block, tx BlockTx) error {
 			from = newAccount(tx.FromID, 0)
 		}
 
-		to, exists := db.accounts[tx.ToID]
-		if !exists {
-			from = newAccount(tx.ToID, 0)
-		}
-
 		bnfc, exists := db.accounts[block.Header.BeneficiaryID]
 		if !exists {
 			bnfc = newAccount(block.Header.BeneficiaryID, 0)
@@ -217,31 +283,45 @@ func (db *Database) ApplyTransaction(block Block, tx BlockTx) error {
 		db.accounts[tx.FromID] = from
 		db.accounts[block.Header.BeneficiaryID] = bnfc
 
+		// Read the sender back in case it's also the beneficiary.
+		from = db.accounts[tx.FromID]
+
 		// Perform basic accounting checks.
+		value, overflow := tx.TotalValue()
 		{
 			if tx.Nonce != (from.Nonce + 1) {
 				return fmt.Errorf("transaction invalid, wrong nonce, got %d, exp %d", tx.Nonce, from.Nonce+1)
 			}
 
-			if from.Balance == 0 || from.Balance < (tx.Value+tx.Tip) {
-				return fmt.Errorf("transaction invalid, insufficient funds, bal %d, needed %d", from.Balance, tx.Value+tx.Tip)
+			if overflow || value+tx.Tip < value {
+				return errors.New("transaction invalid, value plus tip overflows")
+			}
+
+			if from.Balance == 0 || from.Balance < (value+tx.Tip) {
+				return fmt.Errorf("transaction invalid, insufficient funds, bal %d, needed %d", from.Balance, value+tx.Tip)
 			}
 		}
 
-		// Update the balances between the two parties.
-		from.Balance -= tx.Value
-		to.Balance += tx.Value
+		// Take the value and the tip from the sender and update the nonce
+		// for the next transaction check.
+		from.Balance -= value + tx.Tip
+		from.Nonce = tx.Nonce
+		db.accounts[tx.FromID] = from
+
+		// Pay each of the receiving accounts. The accounts are read back
+		// from the map each time since an account can be paid more than once.
+		for _, out := range tx.Payments() {
+			to, exists := db.accounts[out.ToID]
+			if !exists {
+				to = newAccount(out.ToID, 0)
+			}
+			to.Balance += out.Value
+			db.accounts[out.ToID] = to
+		}
 
 		// Give the beneficiary the tip.
-		from.Balance -= tx.Tip
+		bnfc = db.accounts[block.Header.BeneficiaryID]
 		bnfc.Balance += tx.Tip
-
-		// Update the nonce for the next transaction check.
-		from.Nonce = tx.Nonce
-
-		// Update the final changes to these accounts.
-		db.accounts[tx.FromID] = from
-		db.accounts[tx.ToID] = to
 		db.accounts[block.Header.BeneficiaryID] = bnfc
 	}
 
@@ -267,20 +347,31 @@ func (db *Database) LatestBlock() Block {
 }
 
 // Write adds a new block to the chain.
-func (db *Database) Write(block Block) error {
-	return db.storage.Write(NewBlockData(block))
+func (db *Database) Write(ctx context.Context, block Block) error {
+	return db.storage.Write(ctx, NewBlockData(block))
 }
 
 // ForEach returns an iterator to walk through all the blocks
 // starting with block number 1.
-func (db *Database) ForEach() DatabaseIterator {
-	return DatabaseIterator{iterator: db.storage.ForEach()}
+func (db *Database) ForEach(ctx context.Context) DatabaseIterator {
+	return DatabaseIterator{iterator: db.storage.ForEach(ctx)}
 }
 
 // GetBlock searches the blockchain on disk to locate and return the
 // contents of the specified block by number.
-func (db *Database) GetBlock(num uint64) (Block, error) {
-	blockData, err := db.storage.GetBlock(num)
+func (db *Database) GetBlock(ctx context.Context, num uint64) (Block, error) {
+	blockData, err := db.storage.GetBlock(ctx, num)
+	if err != nil {
+		return Block{}, err
+	}
+
+	return ToBlock(blockData)
+}
+
+// GetBlockByHash searches the blockchain on disk to locate and return the
+// contents of the specified block by hash.
+func (db *Database) GetBlockByHash(ctx context.Context, hash string) (Block, error) {
+	blockData, err := db.storage.GetBlockByHash(ctx, hash)
 	if err != nil {
 		return Block{}, err
 	}
@@ -300,7 +391,7 @@ type DatabaseIterator struct {
 func (di *DatabaseIterator) Next() (Block, error) {
 	blockData, err := di.iterator.Next()
 	if err != nil {
-		return Block{}, nil
+		return Block{}, err
 	}
 	return ToBlock(blockData)
 }
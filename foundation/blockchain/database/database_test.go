@@ -0,0 +1,116 @@
+package database_test
+
+import (
+	"testing"
+
+	"github.com/andrewyang17/blockchain/foundation/blockchain/database"
+	"github.com/andrewyang17/blockchain/foundation/blockchain/genesis"
+	"github.com/andrewyang17/blockchain/foundation/blockchain/storage/memory"
+)
+
+func Test_ApplyMultiOutput(t *testing.T) {
+	const (
+		idEd    = "0xa988b1866EaBF72B4c53b592c97aAD8e4b9bDCC0"
+		idMiner = "0x1111111111111111111111111111111111111111"
+	)
+
+	type table struct {
+		name     string
+		balance  uint64
+		outputs  []database.Output
+		tip      uint64
+		success  bool
+		balances map[database.AccountID]uint64
+	}
+
+	outputs := []database.Output{
+		{ToID: idPavel, Value: 100},
+		{ToID: idCesar, Value: 200},
+		{ToID: idEd, Value: 300},
+	}
+
+	tt := []table{
+		{
+			name:    "three recipients",
+			balance: 1000,
+			outputs: outputs,
+			tip:     10,
+			success: true,
+			balances: map[database.AccountID]uint64{
+				idKennedy: 390,
+				idPavel:   100,
+				idCesar:   200,
+				idEd:      300,
+				idMiner:   10,
+			},
+		},
+		{
+			name:    "same recipient twice",
+			balance: 1000,
+			outputs: []database.Output{{ToID: idPavel, Value: 100}, {ToID: idPavel, Value: 50}},
+			success: true,
+			balances: map[database.AccountID]uint64{
+				idKennedy: 850,
+				idPavel:   150,
+			},
+		},
+		{
+			name:    "total exceeds balance",
+			balance: 600,
+			outputs: outputs,
+			tip:     10,
+			success: false,
+			balances: map[database.AccountID]uint64{
+				idKennedy: 600,
+				idPavel:   0,
+				idCesar:   0,
+				idEd:      0,
+				idMiner:   0,
+			},
+		},
+	}
+
+	for _, tst := range tt {
+		f := func(t *testing.T) {
+			gen := genesis.Genesis{ChainID: 1, Balances: map[string]uint64{idKennedy: tst.balance}}
+
+			db, err := database.New(gen, memory.New(), func(v string, args ...any) {})
+			if err != nil {
+				t.Fatalf("Test %s:\tShould be able to create the database: %s", tst.name, err)
+			}
+
+			tx, err := database.NewMultiTx(1, 1, idKennedy, tst.outputs, tst.tip, 0, 0, nil, "")
+			if err != nil {
+				t.Fatalf("Test %s:\tShould be able to construct transaction: %s", tst.name, err)
+			}
+
+			signedTx, err := sign(keyKennedy, tx)
+			if err != nil {
+				t.Fatalf("Test %s:\tShould be able to sign transaction: %s", tst.name, err)
+			}
+
+			if err := signedTx.Validate(1, 0); err != nil {
+				t.Fatalf("Test %s:\tShould be a valid transaction: %s", tst.name, err)
+			}
+
+			block := database.Block{Header: database.BlockHeader{BeneficiaryID: idMiner}}
+
+			err = db.ApplyTransaction(block, database.NewBlockTx(signedTx, 0, 0))
+			if tst.success && err != nil {
+				t.Fatalf("Test %s:\tShould be able to apply the transaction: %s", tst.name, err)
+			}
+			if !tst.success && err == nil {
+				t.Fatalf("Test %s:\tShould not be able to apply the transaction.", tst.name)
+			}
+
+			accounts := db.Copy()
+			for accountID, exp := range tst.balances {
+				if got := accounts[accountID].Balance; got != exp {
+					t.Fatalf("Test %s:\tShould have a balance of %d for %s, got %d", tst.name, exp, accountID, got)
+				}
+			}
+		}
+
+		t.Run(tst.name, f)
+	}
+}
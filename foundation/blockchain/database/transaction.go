@@ -7,6 +7,9 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"math/bits"
+	"runtime"
+	"sync"
 	"time"
 
 	"github.com/andrewyang17/blockchain/foundation/blockchain/signature"
@@ -14,38 +17,336 @@ import (
 
 // Tx is the transactional information between two parties.
 type Tx struct {
-	ChainID uint16    `json:"chain_id"`
-	Nonce   uint64    `json:"nonce"`
-	FromID  AccountID `json:"from"`
-	ToID    AccountID `json:"to"`
-	Value   uint64    `json:"value"`
-	Tip     uint64    `json:"tip"`
-	Data    []byte    `json:"data"`
+	ChainID  uint16    `json:"chain_id"`
+	Nonce    uint64    `json:"nonce"`
+	FromID   AccountID `json:"from"`
+	ToID     AccountID `json:"to"`
+	Value    uint64    `json:"value"`
+	Tip      uint64    `json:"tip"`
+	GasPrice uint64    `json:"tx_gas_price,omitempty"` // The price of one unit of gas the sender agrees to pay.
+	GasUnits uint64    `json:"tx_gas_units,omitempty"` // The number of units of gas the sender agrees to pay for.
+	Deadline uint64    `json:"deadline,omitempty"`     // Unix time after which the transaction expires, zero never expires.
+	Memo     string    `json:"memo,omitempty"`         // A reference for the payment such as an invoice number.
+	Priority uint8     `json:"priority,omitempty"`     // Selected ahead of any tip by priority aware strategies, zero is normal.
+	Outputs  []Output  `json:"outputs,omitempty"`      // Pays many accounts in place of the to and value, see NewMultiTx.
+	Data     []byte    `json:"data"`
 }
 
+// Output is one of the payments made by a multi output transaction.
+type Output struct {
+	ToID  AccountID `json:"to"`
+	Value uint64    `json:"value"`
+}
+
+// MaxOutputs is the maximum number of outputs allowed in a transaction.
+const MaxOutputs = 256
+
+// MaxMemoSize is the maximum number of bytes allowed in a transaction memo.
+const MaxMemoSize = 256
+
+// MaxDataSize is the maximum number of bytes allowed in the data of a
+// transaction. It can be changed when the node starts, before any
+// transactions are validated.
+var MaxDataSize = 4 * 1024
+
+// MinTipPerByte is the least tip a transaction must pay for each byte of its
+// binary wire encoding, so larger transactions pay more for the block space
+// they use. Zero turns off the check. It can be changed when the node starts,
+// before any transactions are validated.
+var MinTipPerByte uint64
+
+// Set of error variables for validating transactions. The errors are returned
+// wrapped with the details, so use errors.Is to check for them.
+var (
+	ErrInvalidChainID   = errors.New("invalid chain id")
+	ErrMalformedAccount = errors.New("account is not properly formatted")
+	ErrSelfTransfer     = errors.New("transaction invalid, sending money to yourself")
+	ErrBadSignature     = errors.New("invalid signature")
+)
+
+// CORE NOTE: The gas fields on the Tx use their own JSON names since the
+// BlockTx type declares gas fields with the gas_price and gas_units names.
+// Sharing the names would hide the signed values when a BlockTx is encoded.
+// The fields are omitted when empty so legacy transactions are encoded,
+// signed, and hashed exactly as before. The same goes for the priority and
+// the outputs.
+
 // NewTx constructs a new transaction.
-func NewTx(chainID uint16, nonce uint64, fromID AccountID, toID AccountID, value uint64, tip uint64, data []byte) (Tx, error) {
+func NewTx(chainID uint16, nonce uint64, fromID AccountID, toID AccountID, value uint64, tip uint64, gasPrice uint64, gasUnits uint64, data []byte, memo string) (Tx, error) {
 	if !fromID.IsAccountID() {
-		return Tx{}, errors.New("from account is not properly formatted")
+		return Tx{}, fmt.Errorf("from %w", ErrMalformedAccount)
 	}
 	if !toID.IsAccountID() {
-		return Tx{}, errors.New("to account is not properly formatted")
+		return Tx{}, fmt.Errorf("to %w", ErrMalformedAccount)
 	}
 
 	tx := Tx{
-		ChainID: chainID,
-		Nonce:   nonce,
-		FromID:  fromID,
-		ToID:    toID,
-		Value:   value,
-		Tip:     tip,
-		Data:    data,
+		ChainID:  chainID,
+		Nonce:    nonce,
+		FromID:   fromID,
+		ToID:     toID,
+		Value:    value,
+		Tip:      tip,
+		GasPrice: gasPrice,
+		GasUnits: gasUnits,
+		Memo:     memo,
+		Data:     data,
+	}
+
+	if err := tx.validateValue(); err != nil {
+		return Tx{}, err
+	}
+
+	if err := tx.validateMemo(); err != nil {
+		return Tx{}, err
+	}
+
+	if err := tx.validateData(); err != nil {
+		return Tx{}, err
 	}
 
 	return tx, nil
 }
 
-// Sign uses the specified private key to sign the transaction.
+// NewMultiTx constructs a new transaction paying each of the outputs from
+// the one account. The payments are applied together, so either every
+// output is paid or none are.
+func NewMultiTx(chainID uint16, nonce uint64, fromID AccountID, outputs []Output, tip uint64, gasPrice uint64, gasUnits uint64, data []byte, memo string) (Tx, error) {
+	if !fromID.IsAccountID() {
+		return Tx{}, fmt.Errorf("from %w", ErrMalformedAccount)
+	}
+
+	tx := Tx{
+		ChainID:  chainID,
+		Nonce:    nonce,
+		FromID:   fromID,
+		Tip:      tip,
+		GasPrice: gasPrice,
+		GasUnits: gasUnits,
+		Outputs:  outputs,
+		Memo:     memo,
+		Data:     data,
+	}
+
+	if err := tx.validateRecipients(); err != nil {
+		return Tx{}, err
+	}
+
+	if err := tx.validateValue(); err != nil {
+		return Tx{}, err
+	}
+
+	if err := tx.validateMemo(); err != nil {
+		return Tx{}, err
+	}
+
+	if err := tx.validateData(); err != nil {
+		return Tx{}, err
+	}
+
+	return tx, nil
+}
+
+// IsMultiOutput reports if the transaction pays its outputs in place of a
+// single to account.
+func (tx Tx) IsMultiOutput() bool {
+	return len(tx.Outputs) > 0
+}
+
+// Payments returns the accounts paid by the transaction and how much each is
+// paid. A single recipient transaction returns one payment for the to and
+// value.
+func (tx Tx) Payments() []Output {
+	if tx.IsMultiOutput() {
+		return tx.Outputs
+	}
+
+	return []Output{{ToID: tx.ToID, Value: tx.Value}}
+}
+
+// Pays reports if the specified account receives any of the payments.
+func (tx Tx) Pays(accountID AccountID) bool {
+	for _, out := range tx.Payments() {
+		if out.ToID == accountID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// TotalValue returns the sum of the values paid by the transaction, not
+// including the tip. The bool reports if the sum overflowed.
+func (tx Tx) TotalValue() (uint64, bool) {
+	var total uint64
+	for _, out := range tx.Payments() {
+		var carry uint64
+		if total, carry = bits.Add64(total, out.Value, 0); carry != 0 {
+			return 0, true
+		}
+	}
+
+	return total, false
+}
+
+// validateRecipients checks the accounts being paid are properly formatted
+// and not the from account. A multi output transaction can't also set the
+// to or value, and each output must pay something.
+func (tx Tx) validateRecipients() error {
+	if !tx.IsMultiOutput() {
+		if !tx.ToID.IsAccountID() {
+			return fmt.Errorf("to %w", ErrMalformedAccount)
+		}
+
+		if tx.FromID == tx.ToID {
+			return fmt.Errorf("%w, from %s, to %s", ErrSelfTransfer, tx.FromID, tx.ToID)
+		}
+
+		return nil
+	}
+
+	if len(tx.Outputs) > MaxOutputs {
+		return fmt.Errorf("transaction invalid, too many outputs, got %d, max %d", len(tx.Outputs), MaxOutputs)
+	}
+
+	if tx.ToID != "" || tx.Value != 0 {
+		return errors.New("transaction invalid, multi output transaction can't set to or value")
+	}
+
+	for i, out := range tx.Outputs {
+		if !out.ToID.IsAccountID() {
+			return fmt.Errorf("output %d to %w", i, ErrMalformedAccount)
+		}
+
+		if tx.FromID == out.ToID {
+			return fmt.Errorf("%w, from %s, output %d to %s", ErrSelfTransfer, tx.FromID, i, out.ToID)
+		}
+
+		if out.Value == 0 {
+			return fmt.Errorf("transaction invalid, output %d has zero value", i)
+		}
+	}
+
+	return nil
+}
+
+// validateValue checks the transaction transfers a value unless it carries
+// data and that the value plus the tip can be represented. The value of a
+// multi output transaction is the sum of the outputs.
+func (tx Tx) validateValue() error {
+	value, overflow := tx.TotalValue()
+	if overflow {
+		return errors.New("transaction invalid, sum of outputs overflows")
+	}
+
+	if value == 0 && len(tx.Data) == 0 {
+		return errors.New("transaction invalid, zero value transfer without data")
+	}
+
+	if _, carry := bits.Add64(value, tx.Tip, 0); carry != 0 {
+		return fmt.Errorf("transaction invalid, value plus tip overflows, value %d, tip %d", value, tx.Tip)
+	}
+
+	return nil
+}
+
+// validateMemo checks the memo is not larger than the maximum memo size.
+func (tx Tx) validateMemo() error {
+	if len(tx.Memo) > MaxMemoSize {
+		return fmt.Errorf("transaction invalid, memo too large, size %d, max %d", len(tx.Memo), MaxMemoSize)
+	}
+
+	return nil
+}
+
+// validateData checks the data is not larger than the maximum data size.
+func (tx Tx) validateData() error {
+	if len(tx.Data) > MaxDataSize {
+		return fmt.Errorf("transaction invalid, data too large, size %d, max %d", len(tx.Data), MaxDataSize)
+	}
+
+	return nil
+}
+
+// IsExpired reports if the transaction has a deadline and the specified
+// time is past that deadline.
+func (tx Tx) IsExpired(now time.Time) bool {
+	return tx.Deadline != 0 && uint64(now.Unix()) > tx.Deadline
+}
+
+// ValidateFields checks the fields of an unsigned transaction, which is every
+// check ValidateChainIDs makes except for the signature. The checks are done
+// in this order:
+//
+//   - the chain id is one of the specified chain ids
+//   - the from and to accounts are properly formatted and not the same, for
+//     a multi output transaction each output is checked in place of the to
+//   - the value is not zero without data and the value plus tip can't overflow
+//   - the memo and data are within their maximum sizes
+//   - the deadline has not passed
+//   - a transaction providing gas pays at least minFee
+func (tx Tx) ValidateFields(chainIDs []uint16, minFee uint64) error {
+	var validChainID bool
+	for _, chainID := range chainIDs {
+		if tx.ChainID == chainID {
+			validChainID = true
+			break
+		}
+	}
+	if !validChainID {
+		return fmt.Errorf("%w, got[%d] exp%v", ErrInvalidChainID, tx.ChainID, chainIDs)
+	}
+
+	if !tx.FromID.IsAccountID() {
+		return fmt.Errorf("from %w", ErrMalformedAccount)
+	}
+
+	if err := tx.validateRecipients(); err != nil {
+		return err
+	}
+
+	if err := tx.validateValue(); err != nil {
+		return err
+	}
+
+	if err := tx.validateMemo(); err != nil {
+		return err
+	}
+
+	if err := tx.validateData(); err != nil {
+		return err
+	}
+
+	if tx.IsExpired(time.Now()) {
+		return fmt.Errorf("transaction invalid, deadline has passed, deadline %d", tx.Deadline)
+	}
+
+	if !tx.IsLegacy() {
+		fee, overflow := tx.Fee()
+		if overflow || fee < minFee {
+			return fmt.Errorf("transaction invalid, fee too low, fee %d, min %d", fee, minFee)
+		}
+	}
+
+	return nil
+}
+
+// IsLegacy reports if the transaction doesn't provide any gas information
+// and only pays a tip.
+func (tx Tx) IsLegacy() bool {
+	return tx.GasPrice == 0 && tx.GasUnits == 0
+}
+
+// Fee returns the fee implied by the gas price and units of the transaction.
+// The bool reports if the calculation overflowed.
+func (tx Tx) Fee() (uint64, bool) {
+	hi, fee := bits.Mul64(tx.GasPrice, tx.GasUnits)
+	return fee, hi != 0
+}
+
+// Sign uses the specified private key to sign the transaction. Every field of
+// the transaction is signed, including the chain id, so a transaction signed
+// for one chain can't be replayed on another.
 func (tx Tx) Sign(privateKey *ecdsa.PrivateKey) (SignedTx, error) {
 
 	// Sign the transaction with the private key to produce a signature.
@@ -70,44 +371,163 @@ func (tx Tx) Sign(privateKey *ecdsa.PrivateKey) (SignedTx, error) {
 // a wallet provide transactions for inclusion into the blockchain.
 type SignedTx struct {
 	Tx
-	V *big.Int `json:"v"` // Ethereum: Recovery identifier, either 29 or 30 with ardanID.
+	V *big.Int `json:"v"` // Ethereum: Recovery identifier plus signature.RecoveryOffset, 29 or 30 by default.
 	R *big.Int `json:"r"` // Ethereum: First coordinate of the ECDSA signature.
 	S *big.Int `json:"s"` // Ethereum: Second coordinate of the ECDSA signature.
+
+	from fromCache // The recovered from address, never encoded.
+}
+
+// fromCache remembers the address recovered from the signature along with
+// the hash of the signed transaction it was recovered from.
+type fromCache struct {
+	hash    string
+	address string
+}
+
+// Validate checks the transaction has a proper signature, the from matches
+// the signature, and the fields are properly formatted. Transactions that
+// provide gas must pay a fee of at least minFee. Transactions without any
+// gas are treated as legacy tip only transactions and skip the fee check.
+func (tx *SignedTx) Validate(chainID uint16, minFee uint64) error {
+	return tx.ValidateChainIDs([]uint16{chainID}, minFee)
 }
 
-func (tx SignedTx) Validate(chainID uint16) error {
-	if tx.ChainID != chainID {
-		return fmt.Errorf("invalid chain id, got[%d] exp[%d]", tx.ChainID, chainID)
+// ValidateChainIDs works like Validate but accepts the transaction for any of
+// the specified chain ids. This allows a node to accept transactions for both
+// the old and new chain id during a hard fork window. The checks done by
+// ValidateFields are made first, then the tip is checked against
+// MinTipPerByte, followed by the signature checks done by
+// VerifySignatureOnly.
+func (tx *SignedTx) ValidateChainIDs(chainIDs []uint16, minFee uint64) error {
+	if err := tx.ValidateFields(chainIDs, minFee); err != nil {
+		return err
 	}
 
-	if !tx.FromID.IsAccountID() {
-		return errors.New("from account is not properly formatted")
+	if err := tx.validateTipPerByte(MinTipPerByte); err != nil {
+		return err
+	}
+
+	return tx.verifySignature()
+}
+
+// validateTipPerByte checks the tip is at least the rate times the size of
+// the binary wire encoding of the transaction. A rate of zero skips the check.
+func (tx SignedTx) validateTipPerByte(rate uint64) error {
+	if rate == 0 {
+		return nil
 	}
 
-	if !tx.ToID.IsAccountID() {
-		return errors.New("to account is not properly formatted")
+	data, err := tx.MarshalWire()
+	if err != nil {
+		return err
 	}
 
-	if tx.FromID == tx.ToID {
-		return fmt.Errorf("transaction invalid, sending money to yourself, from %s, to %s", tx.FromID, tx.ToID)
+	hi, minTip := bits.Mul64(rate, uint64(len(data)))
+	if hi != 0 || tx.Tip < minTip {
+		return fmt.Errorf("transaction invalid, tip too low for size, tip %d, min %d, size %d", tx.Tip, minTip, len(data))
 	}
 
+	return nil
+}
+
+// VerifySignatureOnly checks the signature values are valid, with a recovery
+// id of 29 or 30 and a low s value, and the signature was produced by the
+// account the transaction is from. None of the other checks Validate does
+// are made. This is for transactions in a block that was already accepted,
+// like blocks replayed during a resync, where only the signature needs to be
+// checked again. Any failure is reported as ErrBadSignature.
+func (tx *SignedTx) VerifySignatureOnly() error {
+	return tx.verifySignature()
+}
+
+// verifySignature checks the signature is valid and was produced by the
+// account the transaction is from. Any failure is reported as a bad
+// signature.
+func (tx *SignedTx) verifySignature() error {
 	if err := signature.VerifySignature(tx.V, tx.R, tx.S); err != nil {
-		return err
+		return fmt.Errorf("%w: %s", ErrBadSignature, err)
 	}
 
-	address, err := signature.FromAddress(tx.Tx, tx.V, tx.R, tx.S)
+	address, err := tx.FromAddress()
 	if err != nil {
-		return err
+		return fmt.Errorf("%w: %s", ErrBadSignature, err)
 	}
 
 	if address != string(tx.FromID) {
-		return errors.New("signature address doesn't match from address")
+		return fmt.Errorf("%w: signature address doesn't match from address", ErrBadSignature)
 	}
 
 	return nil
 }
 
+// FromAddress recovers the address of the account that signed the
+// transaction. The address is remembered with the hash of the signed
+// transaction so later calls skip the recovery, unless the transaction
+// or signature has been changed since.
+func (tx *SignedTx) FromAddress() (string, error) {
+	hash := tx.Hash()
+	if tx.from.address != "" && tx.from.hash == hash {
+		return tx.from.address, nil
+	}
+
+	address, err := signature.FromAddress(tx.Tx, tx.V, tx.R, tx.S)
+	if err != nil {
+		return "", err
+	}
+
+	tx.from = fromCache{hash: hash, address: address}
+
+	return address, nil
+}
+
+// PubKey recovers the public key of the account that signed the
+// transaction.
+func (tx SignedTx) PubKey() (*ecdsa.PublicKey, error) {
+	return signature.FromPubKey(tx.Tx, tx.V, tx.R, tx.S)
+}
+
+// VerifyBatch checks the chain id and signature of each transaction, which
+// is the expensive part of validation, spreading the work across the
+// available CPUs. The returned errors are in the same order as the
+// transactions with a nil error for each transaction that passes. The
+// recovered from addresses are remembered in the transactions.
+func VerifyBatch(txs []SignedTx, chainID uint16) []error {
+	errs := make([]error, len(txs))
+
+	// Limit the number of signatures being verified at the same time.
+	sem := make(chan struct{}, runtime.GOMAXPROCS(0))
+
+	var wg sync.WaitGroup
+	wg.Add(len(txs))
+	for i := range txs {
+		sem <- struct{}{}
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			if txs[i].ChainID != chainID {
+				errs[i] = fmt.Errorf("%w, got[%d] exp[%d]", ErrInvalidChainID, txs[i].ChainID, chainID)
+				return
+			}
+
+			errs[i] = txs[i].verifySignature()
+		}(i)
+	}
+	wg.Wait()
+
+	return errs
+}
+
+// Hash returns a unique hash for the signed transaction that can be used
+// to identify the transaction in the mempool and blocks. The signature is
+// part of the hash so the same transaction signed differently is unique.
+func (tx SignedTx) Hash() string {
+	return signature.Hash(tx)
+}
+
 // SignatureString returns the signature as a string.
 func (tx SignedTx) SignatureString() string {
 	return signature.SignatureString(tx.V, tx.R, tx.S)
@@ -0,0 +1,837 @@
+package database_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andrewyang17/blockchain/foundation/blockchain/database"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	keyKennedy = "9f332e3700d8fc2446eaf6d15034cf96e0c2745e40353deef032a5dbf1dfed93"
+	keyPavel   = "fae85851bdf5c9f49923722ce38f3c1defcfd3619ef5453230a58ad805499959"
+
+	idKennedy = "0xF01813E4B85e178A83e29B8E7bF26BD830a25f32"
+	idPavel   = "0xdd6B972ffcc631a62CAE1BB9d80b7ff429c8ebA4"
+	idCesar   = "0xbEE6ACE826eC3DE1B6349888B9151B92522F7F76"
+)
+
+func Test_SignedTxHash(t *testing.T) {
+	tx := database.Tx{ChainID: 1, Nonce: 1, FromID: idKennedy, ToID: idCesar, Value: 100, Tip: 10}
+
+	signedTx, err := sign(keyKennedy, tx)
+	if err != nil {
+		t.Fatalf("Should be able to sign transaction: %s", err)
+	}
+
+	hash := signedTx.Hash()
+	if len(hash) != 66 || hash[:2] != "0x" {
+		t.Fatalf("Should get back a 0x prefixed 32 byte hash, got %s", hash)
+	}
+
+	data, err := json.Marshal(signedTx)
+	if err != nil {
+		t.Fatalf("Should be able to marshal transaction: %s", err)
+	}
+
+	var remarshaled database.SignedTx
+	if err := json.Unmarshal(data, &remarshaled); err != nil {
+		t.Fatalf("Should be able to unmarshal transaction: %s", err)
+	}
+
+	if remarshaled.Hash() != hash {
+		t.Fatalf("Should get the same hash after re-marshaling: got %s, exp %s", remarshaled.Hash(), hash)
+	}
+
+	otherTx, err := sign(keyPavel, tx)
+	if err != nil {
+		t.Fatalf("Should be able to sign transaction: %s", err)
+	}
+
+	if otherTx.Hash() == hash {
+		t.Fatalf("Should get a different hash for a different signature")
+	}
+}
+
+func Test_ValidateFee(t *testing.T) {
+	type table struct {
+		name     string
+		gasPrice uint64
+		gasUnits uint64
+		minFee   uint64
+		valid    bool
+	}
+
+	tt := []table{
+		{name: "legacy", gasPrice: 0, gasUnits: 0, minFee: 15, valid: true},
+		{name: "enough", gasPrice: 5, gasUnits: 3, minFee: 15, valid: true},
+		{name: "too low", gasPrice: 5, gasUnits: 2, minFee: 15, valid: false},
+		{name: "no units", gasPrice: 20, gasUnits: 0, minFee: 15, valid: false},
+		{name: "overflow", gasPrice: 1 << 63, gasUnits: 2, minFee: 15, valid: false},
+	}
+
+	for _, tst := range tt {
+		f := func(t *testing.T) {
+			tx, err := database.NewTx(1, 1, idKennedy, idCesar, 100, 10, tst.gasPrice, tst.gasUnits, nil, "")
+			if err != nil {
+				t.Fatalf("Test %s:\tShould be able to construct transaction: %s", tst.name, err)
+			}
+
+			signedTx, err := sign(keyKennedy, tx)
+			if err != nil {
+				t.Fatalf("Test %s:\tShould be able to sign transaction: %s", tst.name, err)
+			}
+
+			err = signedTx.Validate(1, tst.minFee)
+			if tst.valid && err != nil {
+				t.Fatalf("Test %s:\tShould be a valid transaction: %s", tst.name, err)
+			}
+			if !tst.valid && err == nil {
+				t.Fatalf("Test %s:\tShould be an invalid transaction.", tst.name)
+			}
+		}
+
+		t.Run(tst.name, f)
+	}
+}
+
+func Test_SignCoversGas(t *testing.T) {
+	tx, err := database.NewTx(1, 1, idKennedy, idCesar, 100, 10, 5, 3, nil, "")
+	if err != nil {
+		t.Fatalf("Should be able to construct transaction: %s", err)
+	}
+
+	signedTx, err := sign(keyKennedy, tx)
+	if err != nil {
+		t.Fatalf("Should be able to sign transaction: %s", err)
+	}
+
+	signedTx.GasPrice = 50
+	if err := signedTx.Validate(1, 15); err == nil {
+		t.Fatalf("Should not be able to change the gas price after signing.")
+	}
+}
+
+func Test_SignCoversPriority(t *testing.T) {
+	tx := database.Tx{ChainID: 1, Nonce: 1, FromID: idKennedy, ToID: idCesar, Value: 100, Tip: 10, Priority: 1}
+
+	signedTx, err := sign(keyKennedy, tx)
+	if err != nil {
+		t.Fatalf("Should be able to sign transaction: %s", err)
+	}
+
+	if err := signedTx.Validate(1, 0); err != nil {
+		t.Fatalf("Should be able to validate a transaction with a priority: %s", err)
+	}
+
+	signedTx.Priority = 255
+	if err := signedTx.Validate(1, 0); err == nil {
+		t.Fatalf("Should not be able to change the priority after signing.")
+	}
+}
+
+func Test_CrossChainReplay(t *testing.T) {
+	tx := database.Tx{ChainID: 1, Nonce: 1, FromID: idKennedy, ToID: idCesar, Value: 100, Tip: 10}
+
+	signedTx, err := sign(keyKennedy, tx)
+	if err != nil {
+		t.Fatalf("Should be able to sign transaction: %s", err)
+	}
+
+	if err := signedTx.Validate(1, 0); err != nil {
+		t.Fatalf("Should be a valid transaction on chain 1: %s", err)
+	}
+
+	// A node on chain 2 rejects the transaction by its chain id.
+	if err := signedTx.Validate(2, 0); !errors.Is(err, database.ErrInvalidChainID) {
+		t.Fatalf("Should reject the transaction on chain 2 by its chain id: %v", err)
+	}
+
+	// Replaying the signature with the chain id changed to 2 must not recover
+	// the account that signed for chain 1.
+	replayTx := database.SignedTx{Tx: tx, V: signedTx.V, R: signedTx.R, S: signedTx.S}
+	replayTx.ChainID = 2
+
+	address, err := replayTx.FromAddress()
+	if err == nil && address == idKennedy {
+		t.Fatalf("Should not recover the signing account for another chain id.")
+	}
+
+	if err := replayTx.Validate(2, 0); !errors.Is(err, database.ErrBadSignature) {
+		t.Fatalf("Should reject the replayed signature on chain 2 as a bad signature: %v", err)
+	}
+
+	// Accepting both chain ids during a fork window must not open the replay.
+	if err := replayTx.ValidateChainIDs([]uint16{1, 2}, 0); !errors.Is(err, database.ErrBadSignature) {
+		t.Fatalf("Should reject the replayed signature during a fork window: %v", err)
+	}
+
+	if errs := database.VerifyBatch([]database.SignedTx{replayTx}, 2); !errors.Is(errs[0], database.ErrBadSignature) {
+		t.Fatalf("Should reject the replayed signature in a batch: %v", errs[0])
+	}
+}
+
+func Test_ValidateDeadline(t *testing.T) {
+	type table struct {
+		name     string
+		deadline uint64
+		valid    bool
+	}
+
+	now := uint64(time.Now().Unix())
+
+	tt := []table{
+		{name: "never expires", deadline: 0, valid: true},
+		{name: "before deadline", deadline: now + 3600, valid: true},
+		{name: "after deadline", deadline: now - 3600, valid: false},
+	}
+
+	for _, tst := range tt {
+		f := func(t *testing.T) {
+			tx := database.Tx{ChainID: 1, Nonce: 1, FromID: idKennedy, ToID: idCesar, Value: 100, Deadline: tst.deadline}
+
+			signedTx, err := sign(keyKennedy, tx)
+			if err != nil {
+				t.Fatalf("Test %s:\tShould be able to sign transaction: %s", tst.name, err)
+			}
+
+			err = signedTx.Validate(1, 0)
+			if tst.valid && err != nil {
+				t.Fatalf("Test %s:\tShould be a valid transaction: %s", tst.name, err)
+			}
+			if !tst.valid && err == nil {
+				t.Fatalf("Test %s:\tShould be an expired transaction.", tst.name)
+			}
+
+			// The deadline is covered by the signature.
+			signedTx.Deadline++
+			if err := signedTx.Validate(1, 0); err == nil {
+				t.Fatalf("Test %s:\tShould not be able to change the deadline after signing.", tst.name)
+			}
+		}
+
+		t.Run(tst.name, f)
+	}
+}
+
+func Test_ValidateValue(t *testing.T) {
+	type table struct {
+		name  string
+		value uint64
+		tip   uint64
+		data  []byte
+		valid bool
+	}
+
+	const maxUint64 = ^uint64(0)
+
+	tt := []table{
+		{name: "value", value: 100, tip: 10, valid: true},
+		{name: "zero value", value: 0, tip: 10, valid: false},
+		{name: "zero value with data", value: 0, tip: 10, data: []byte("call"), valid: true},
+		{name: "max value and tip", value: maxUint64 - 10, tip: 10, valid: true},
+		{name: "value and tip overflow", value: maxUint64 - 9, tip: 10, valid: false},
+	}
+
+	for _, tst := range tt {
+		f := func(t *testing.T) {
+			tx, err := database.NewTx(1, 1, idKennedy, idCesar, tst.value, tst.tip, 0, 0, tst.data, "")
+			if tst.valid && err != nil {
+				t.Fatalf("Test %s:\tShould be able to construct transaction: %s", tst.name, err)
+			}
+			if !tst.valid && err == nil {
+				t.Fatalf("Test %s:\tShould not be able to construct transaction.", tst.name)
+			}
+
+			// The same check must happen when validating a signed transaction
+			// that was not constructed by NewTx.
+			tx = database.Tx{ChainID: 1, Nonce: 1, FromID: idKennedy, ToID: idCesar, Value: tst.value, Tip: tst.tip, Data: tst.data}
+
+			signedTx, err := sign(keyKennedy, tx)
+			if err != nil {
+				t.Fatalf("Test %s:\tShould be able to sign transaction: %s", tst.name, err)
+			}
+
+			err = signedTx.Validate(1, 0)
+			if tst.valid && err != nil {
+				t.Fatalf("Test %s:\tShould be a valid transaction: %s", tst.name, err)
+			}
+			if !tst.valid && err == nil {
+				t.Fatalf("Test %s:\tShould be an invalid transaction.", tst.name)
+			}
+		}
+
+		t.Run(tst.name, f)
+	}
+}
+
+func Test_MultiOutput(t *testing.T) {
+	type table struct {
+		name    string
+		tx      database.Tx
+		success bool
+	}
+
+	outputs := []database.Output{
+		{ToID: idPavel, Value: 100},
+		{ToID: idCesar, Value: 200},
+	}
+
+	const maxUint64 = ^uint64(0)
+
+	tt := []table{
+		{name: "outputs", tx: database.Tx{ChainID: 1, Nonce: 1, FromID: idKennedy, Outputs: outputs, Tip: 10}, success: true},
+		{name: "to set", tx: database.Tx{ChainID: 1, Nonce: 1, FromID: idKennedy, ToID: idCesar, Outputs: outputs}, success: false},
+		{name: "value set", tx: database.Tx{ChainID: 1, Nonce: 1, FromID: idKennedy, Value: 1, Outputs: outputs}, success: false},
+		{name: "zero value output", tx: database.Tx{ChainID: 1, Nonce: 1, FromID: idKennedy, Outputs: []database.Output{{ToID: idPavel}}}, success: false},
+		{name: "self output", tx: database.Tx{ChainID: 1, Nonce: 1, FromID: idKennedy, Outputs: []database.Output{{ToID: idKennedy, Value: 1}}}, success: false},
+		{name: "malformed output", tx: database.Tx{ChainID: 1, Nonce: 1, FromID: idKennedy, Outputs: []database.Output{{ToID: "0x1", Value: 1}}}, success: false},
+		{name: "outputs overflow", tx: database.Tx{ChainID: 1, Nonce: 1, FromID: idKennedy, Outputs: []database.Output{{ToID: idPavel, Value: maxUint64}, {ToID: idCesar, Value: 1}}}, success: false},
+	}
+
+	for _, tst := range tt {
+		f := func(t *testing.T) {
+			signedTx, err := sign(keyKennedy, tst.tx)
+			if err != nil {
+				t.Fatalf("Test %s:\tShould be able to sign transaction: %s", tst.name, err)
+			}
+
+			err = signedTx.Validate(1, 0)
+			if tst.success && err != nil {
+				t.Fatalf("Test %s:\tShould be a valid transaction: %s", tst.name, err)
+			}
+			if !tst.success && err == nil {
+				t.Fatalf("Test %s:\tShould be an invalid transaction.", tst.name)
+			}
+
+			if !tst.success {
+				return
+			}
+
+			// Changing an output after signing must break the signature.
+			signedTx.Outputs = []database.Output{outputs[0], {ToID: idCesar, Value: 2000}}
+			if err := signedTx.Validate(1, 0); !errors.Is(err, database.ErrBadSignature) {
+				t.Fatalf("Test %s:\tShould reject a changed output as a bad signature: %v", tst.name, err)
+			}
+		}
+
+		t.Run(tst.name, f)
+	}
+}
+
+func Test_Memo(t *testing.T) {
+	type table struct {
+		name  string
+		memo  string
+		valid bool
+	}
+
+	tt := []table{
+		{name: "empty memo", memo: "", valid: true},
+		{name: "max memo", memo: strings.Repeat("m", database.MaxMemoSize), valid: true},
+		{name: "over length memo", memo: strings.Repeat("m", database.MaxMemoSize+1), valid: false},
+	}
+
+	for _, tst := range tt {
+		f := func(t *testing.T) {
+			tx, err := database.NewTx(1, 1, idKennedy, idCesar, 100, 10, 0, 0, nil, tst.memo)
+			if !tst.valid {
+				if err == nil {
+					t.Fatalf("Test %s:\tShould not be able to construct transaction.", tst.name)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Test %s:\tShould be able to construct transaction: %s", tst.name, err)
+			}
+
+			signedTx, err := sign(keyKennedy, tx)
+			if err != nil {
+				t.Fatalf("Test %s:\tShould be able to sign transaction: %s", tst.name, err)
+			}
+
+			if err := signedTx.Validate(1, 0); err != nil {
+				t.Fatalf("Test %s:\tShould be a valid transaction: %s", tst.name, err)
+			}
+
+			// The memo is covered by the signature.
+			signedTx.Memo = "INV-0001"
+			if err := signedTx.Validate(1, 0); err == nil {
+				t.Fatalf("Test %s:\tShould not be able to change the memo after signing.", tst.name)
+			}
+		}
+
+		t.Run(tst.name, f)
+	}
+}
+
+func Test_Data(t *testing.T) {
+	type table struct {
+		name  string
+		data  []byte
+		valid bool
+	}
+
+	tt := []table{
+		{name: "empty data", data: nil, valid: true},
+		{name: "max data", data: bytes.Repeat([]byte("d"), database.MaxDataSize), valid: true},
+		{name: "over length data", data: bytes.Repeat([]byte("d"), database.MaxDataSize+1), valid: false},
+	}
+
+	for _, tst := range tt {
+		f := func(t *testing.T) {
+			tx, err := database.NewTx(1, 1, idKennedy, idCesar, 100, 10, 0, 0, tst.data, "")
+			if !tst.valid {
+				if err == nil {
+					t.Fatalf("Test %s:\tShould not be able to construct transaction.", tst.name)
+				}
+				if !strings.Contains(err.Error(), fmt.Sprintf("size %d, max %d", len(tst.data), database.MaxDataSize)) {
+					t.Fatalf("Test %s:\tShould name the size and the limit: %s", tst.name, err)
+				}
+
+				// Transactions built without NewTx are checked at validation.
+				tx = database.Tx{ChainID: 1, Nonce: 1, FromID: idKennedy, ToID: idCesar, Value: 100, Tip: 10, Data: tst.data}
+				signedTx, err := sign(keyKennedy, tx)
+				if err != nil {
+					t.Fatalf("Test %s:\tShould be able to sign transaction: %s", tst.name, err)
+				}
+				if err := signedTx.Validate(1, 0); err == nil {
+					t.Fatalf("Test %s:\tShould not be a valid transaction.", tst.name)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Test %s:\tShould be able to construct transaction: %s", tst.name, err)
+			}
+
+			signedTx, err := sign(keyKennedy, tx)
+			if err != nil {
+				t.Fatalf("Test %s:\tShould be able to sign transaction: %s", tst.name, err)
+			}
+
+			if err := signedTx.Validate(1, 0); err != nil {
+				t.Fatalf("Test %s:\tShould be a valid transaction: %s", tst.name, err)
+			}
+		}
+
+		t.Run(tst.name, f)
+	}
+}
+
+func Test_TipPerByte(t *testing.T) {
+	type table struct {
+		name    string
+		rate    uint64
+		data    []byte
+		success bool
+	}
+
+	// Both transactions pay the same tip, which covers the small transaction
+	// at one unit per byte but not the large one.
+	const tip = 500
+
+	tt := []table{
+		{name: "small", rate: 1, success: true},
+		{name: "large data", rate: 1, data: bytes.Repeat([]byte{1}, 1000), success: false},
+		{name: "large data without floor", rate: 0, data: bytes.Repeat([]byte{1}, 1000), success: true},
+	}
+
+	defer func(rate uint64) { database.MinTipPerByte = rate }(database.MinTipPerByte)
+
+	for _, tst := range tt {
+		f := func(t *testing.T) {
+			database.MinTipPerByte = tst.rate
+
+			tx := database.Tx{ChainID: 1, Nonce: 1, FromID: idKennedy, ToID: idCesar, Value: 100, Tip: tip, Data: tst.data}
+
+			signedTx, err := sign(keyKennedy, tx)
+			if err != nil {
+				t.Fatalf("Test %s:\tShould be able to sign transaction: %s", tst.name, err)
+			}
+
+			err = signedTx.Validate(1, 0)
+			if tst.success && err != nil {
+				t.Fatalf("Test %s:\tShould be a valid transaction: %s", tst.name, err)
+			}
+			if !tst.success && (err == nil || !strings.Contains(err.Error(), "tip too low for size")) {
+				t.Fatalf("Test %s:\tShould fail the per byte tip floor: %v", tst.name, err)
+			}
+		}
+
+		t.Run(tst.name, f)
+	}
+}
+
+func Test_ValidateChainIDs(t *testing.T) {
+	type table struct {
+		name     string
+		chainID  uint16
+		chainIDs []uint16
+		valid    bool
+	}
+
+	tt := []table{
+		{name: "matching id", chainID: 1, chainIDs: []uint16{1}, valid: true},
+		{name: "non matching id", chainID: 2, chainIDs: []uint16{1}, valid: false},
+		{name: "old id in set", chainID: 1, chainIDs: []uint16{1, 2}, valid: true},
+		{name: "new id in set", chainID: 2, chainIDs: []uint16{1, 2}, valid: true},
+		{name: "id not in set", chainID: 3, chainIDs: []uint16{1, 2}, valid: false},
+		{name: "empty set", chainID: 1, chainIDs: nil, valid: false},
+	}
+
+	for _, tst := range tt {
+		f := func(t *testing.T) {
+			tx := database.Tx{ChainID: tst.chainID, Nonce: 1, FromID: idKennedy, ToID: idCesar, Value: 100, Tip: 10}
+
+			signedTx, err := sign(keyKennedy, tx)
+			if err != nil {
+				t.Fatalf("Test %s:\tShould be able to sign transaction: %s", tst.name, err)
+			}
+
+			err = signedTx.ValidateChainIDs(tst.chainIDs, 0)
+			if tst.valid && err != nil {
+				t.Fatalf("Test %s:\tShould be a valid transaction: %s", tst.name, err)
+			}
+			if !tst.valid && err == nil {
+				t.Fatalf("Test %s:\tShould be an invalid transaction.", tst.name)
+			}
+
+			// The single id version must agree when there is one id.
+			if len(tst.chainIDs) == 1 {
+				if err2 := signedTx.Validate(tst.chainIDs[0], 0); (err == nil) != (err2 == nil) {
+					t.Fatalf("Test %s:\tShould get the same result from Validate: %v, %v", tst.name, err, err2)
+				}
+			}
+		}
+
+		t.Run(tst.name, f)
+	}
+}
+
+func Test_ValidateErrors(t *testing.T) {
+	type table struct {
+		name   string
+		key    string
+		tx     database.Tx
+		change func(tx *database.SignedTx)
+		err    error
+	}
+
+	tt := []table{
+		{name: "wrong chain id", key: keyKennedy, tx: database.Tx{ChainID: 2, Nonce: 1, FromID: idKennedy, ToID: idCesar, Value: 100}, err: database.ErrInvalidChainID},
+		{name: "bad from", key: keyKennedy, tx: database.Tx{ChainID: 1, Nonce: 1, FromID: "0xbad", ToID: idCesar, Value: 100}, err: database.ErrMalformedAccount},
+		{name: "bad to", key: keyKennedy, tx: database.Tx{ChainID: 1, Nonce: 1, FromID: idKennedy, ToID: "0xbad", Value: 100}, err: database.ErrMalformedAccount},
+		{name: "self transfer", key: keyKennedy, tx: database.Tx{ChainID: 1, Nonce: 1, FromID: idKennedy, ToID: idKennedy, Value: 100}, err: database.ErrSelfTransfer},
+		{name: "wrong signer", key: keyPavel, tx: database.Tx{ChainID: 1, Nonce: 1, FromID: idKennedy, ToID: idCesar, Value: 100}, err: database.ErrBadSignature},
+		{name: "changed value", key: keyKennedy, tx: database.Tx{ChainID: 1, Nonce: 1, FromID: idKennedy, ToID: idCesar, Value: 100}, change: func(tx *database.SignedTx) { tx.Value++ }, err: database.ErrBadSignature},
+		{name: "bad recovery id", key: keyKennedy, tx: database.Tx{ChainID: 1, Nonce: 1, FromID: idKennedy, ToID: idCesar, Value: 100}, change: func(tx *database.SignedTx) { tx.V.SetUint64(0) }, err: database.ErrBadSignature},
+	}
+
+	for _, tst := range tt {
+		f := func(t *testing.T) {
+			signedTx, err := sign(tst.key, tst.tx)
+			if err != nil {
+				t.Fatalf("Test %s:\tShould be able to sign transaction: %s", tst.name, err)
+			}
+			if tst.change != nil {
+				tst.change(&signedTx)
+			}
+
+			if err := signedTx.Validate(1, 0); !errors.Is(err, tst.err) {
+				t.Fatalf("Test %s:\tShould get back %q: %v", tst.name, tst.err, err)
+			}
+		}
+
+		t.Run(tst.name, f)
+	}
+
+	t.Run("new tx", func(t *testing.T) {
+		if _, err := database.NewTx(1, 1, "0xbad", idCesar, 100, 0, 0, 0, nil, ""); !errors.Is(err, database.ErrMalformedAccount) {
+			t.Fatalf("Should get back %q for a bad from: %v", database.ErrMalformedAccount, err)
+		}
+		if _, err := database.NewTx(1, 1, idKennedy, "0xbad", 100, 0, 0, 0, nil, ""); !errors.Is(err, database.ErrMalformedAccount) {
+			t.Fatalf("Should get back %q for a bad to: %v", database.ErrMalformedAccount, err)
+		}
+		if _, err := database.ToAccountID("0xbad"); !errors.Is(err, database.ErrMalformedAccount) {
+			t.Fatalf("Should get back %q for a bad account id: %v", database.ErrMalformedAccount, err)
+		}
+	})
+
+	t.Run("verify batch", func(t *testing.T) {
+		txs := signedTxs(t, 2)
+		txs[1].ChainID = 2
+
+		errs := database.VerifyBatch(txs, 1)
+		if !errors.Is(errs[1], database.ErrInvalidChainID) {
+			t.Fatalf("Should get back %q from the batch: %v", database.ErrInvalidChainID, errs[1])
+		}
+	})
+}
+
+func Test_ValidateMalleability(t *testing.T) {
+	tx := database.Tx{ChainID: 1, Nonce: 1, FromID: idKennedy, ToID: idCesar, Value: 100, Tip: 10}
+
+	signedTx, err := sign(keyKennedy, tx)
+	if err != nil {
+		t.Fatalf("Should be able to sign transaction: %s", err)
+	}
+
+	if err := signedTx.Validate(1, 0); err != nil {
+		t.Fatalf("Should accept the low s signature: %s", err)
+	}
+
+	// Build the twin signature by flipping s to its complement and the
+	// recovery id to match, so v goes between 29 and 30. Both recover the
+	// same from address.
+	twin := signedTx
+	twin.S = new(big.Int).Sub(crypto.S256().Params().N, signedTx.S)
+	twin.V = new(big.Int).Sub(big.NewInt(29+30), signedTx.V)
+
+	address, err := twin.FromAddress()
+	if err != nil {
+		t.Fatalf("Should be able to recover the address from the twin: %s", err)
+	}
+	if address != idKennedy {
+		t.Fatalf("Should recover the same address from the twin: got %s, exp %s", address, idKennedy)
+	}
+
+	if err := twin.Validate(1, 0); !errors.Is(err, database.ErrBadSignature) {
+		t.Fatalf("Should reject the high s signature: %v", err)
+	}
+}
+
+func Test_VerifySignatureOnly(t *testing.T) {
+	type table struct {
+		name   string
+		key    string
+		tx     database.Tx
+		forge  func(tx *database.SignedTx)
+		valid  bool
+		strict bool
+	}
+
+	tt := []table{
+		{name: "valid", key: keyKennedy, tx: database.Tx{ChainID: 1, Nonce: 1, FromID: idKennedy, ToID: idCesar, Value: 100}, valid: true, strict: true},
+		{name: "wrong chain", key: keyKennedy, tx: database.Tx{ChainID: 9, Nonce: 1, FromID: idKennedy, ToID: idCesar, Value: 100}, valid: true},
+		{name: "self transfer", key: keyKennedy, tx: database.Tx{ChainID: 1, Nonce: 1, FromID: idKennedy, ToID: idKennedy, Value: 100}, valid: true},
+		{name: "wrong signer", key: keyPavel, tx: database.Tx{ChainID: 1, Nonce: 1, FromID: idKennedy, ToID: idCesar, Value: 100}},
+		{name: "changed value", key: keyKennedy, tx: database.Tx{ChainID: 1, Nonce: 1, FromID: idKennedy, ToID: idCesar, Value: 100}, forge: func(tx *database.SignedTx) { tx.Value = 1_000_000 }},
+		{name: "changed signature", key: keyKennedy, tx: database.Tx{ChainID: 1, Nonce: 1, FromID: idKennedy, ToID: idCesar, Value: 100}, forge: func(tx *database.SignedTx) { tx.R = new(big.Int).Add(tx.R, big.NewInt(1)) }},
+	}
+
+	for _, tst := range tt {
+		f := func(t *testing.T) {
+			signedTx, err := sign(tst.key, tst.tx)
+			if err != nil {
+				t.Fatalf("Test %s:\tShould be able to sign transaction: %s", tst.name, err)
+			}
+
+			if tst.forge != nil {
+				tst.forge(&signedTx)
+			}
+
+			err = signedTx.VerifySignatureOnly()
+			if tst.valid && err != nil {
+				t.Fatalf("Test %s:\tShould verify the signature: %s", tst.name, err)
+			}
+			if !tst.valid && !errors.Is(err, database.ErrBadSignature) {
+				t.Fatalf("Test %s:\tShould get ErrBadSignature, got %v", tst.name, err)
+			}
+
+			// The full validation still makes the structural checks.
+			if err := signedTx.Validate(1, 0); (err == nil) != tst.strict {
+				t.Fatalf("Test %s:\tShould get a full validation result of %t, got %v", tst.name, tst.strict, err)
+			}
+		}
+
+		t.Run(tst.name, f)
+	}
+}
+
+func Test_VerifyBatch(t *testing.T) {
+	txs := signedTxs(t, 20)
+
+	// Break a few transactions in different ways.
+	txs[3].Value++
+	txs[7].ChainID = 2
+	txs[15].FromID = idPavel
+
+	errs := database.VerifyBatch(txs, 1)
+	if len(errs) != len(txs) {
+		t.Fatalf("Should get an error slot for each transaction: got %d, exp %d", len(errs), len(txs))
+	}
+
+	for i, err := range errs {
+		switch i {
+		case 3, 7, 15:
+			if err == nil {
+				t.Fatalf("Should get an error for transaction %d.", i)
+			}
+		default:
+			if err != nil {
+				t.Fatalf("Should be a valid transaction %d: %s", i, err)
+			}
+		}
+	}
+}
+
+func BenchmarkVerifyBatch(b *testing.B) {
+	pristine := signedTxs(b, 1000)
+	txs := make([]database.SignedTx, len(pristine))
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+
+		// Start with transactions that haven't remembered the from address.
+		copy(txs, pristine)
+
+		for _, err := range database.VerifyBatch(txs, 1) {
+			if err != nil {
+				b.Fatalf("Should be a valid transaction: %s", err)
+			}
+		}
+	}
+}
+
+func BenchmarkVerifySequential(b *testing.B) {
+	txs := signedTxs(b, 1000)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, tx := range txs {
+			if err := tx.Validate(1, 0); err != nil {
+				b.Fatalf("Should be a valid transaction: %s", err)
+			}
+		}
+	}
+}
+
+func Test_FromAddress(t *testing.T) {
+	tx := database.Tx{ChainID: 1, Nonce: 1, FromID: idKennedy, ToID: idCesar, Value: 100, Tip: 10}
+
+	signedTx, err := sign(keyKennedy, tx)
+	if err != nil {
+		t.Fatalf("Should be able to sign transaction: %s", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		address, err := signedTx.FromAddress()
+		if err != nil {
+			t.Fatalf("Should be able to recover the from address: %s", err)
+		}
+		if address != idKennedy {
+			t.Fatalf("Should recover the right from address: got %s, exp %s", address, idKennedy)
+		}
+	}
+
+	data, err := json.Marshal(signedTx)
+	if err != nil {
+		t.Fatalf("Should be able to marshal transaction: %s", err)
+	}
+	if strings.Count(string(data), idKennedy) != 1 {
+		t.Fatalf("Should only encode the from address once: %s", data)
+	}
+
+	// Changing the transaction after the address is remembered must not
+	// let the change pass validation.
+	signedTx.Value = 1_000
+	if err := signedTx.Validate(1, 0); err == nil {
+		t.Fatalf("Should not be able to change the value after the from address is remembered.")
+	}
+}
+
+func Test_PubKey(t *testing.T) {
+	tx := database.Tx{ChainID: 1, Nonce: 1, FromID: idKennedy, ToID: idCesar, Value: 100, Tip: 10}
+
+	signedTx, err := sign(keyKennedy, tx)
+	if err != nil {
+		t.Fatalf("Should be able to sign transaction: %s", err)
+	}
+
+	pubKey, err := signedTx.PubKey()
+	if err != nil {
+		t.Fatalf("Should be able to recover the public key: %s", err)
+	}
+
+	address, err := signedTx.FromAddress()
+	if err != nil {
+		t.Fatalf("Should be able to recover the from address: %s", err)
+	}
+
+	if got := crypto.PubkeyToAddress(*pubKey).String(); got != address {
+		t.Fatalf("Should derive the from address from the public key: got %s, exp %s", got, address)
+	}
+
+	pk, err := crypto.HexToECDSA(keyKennedy)
+	if err != nil {
+		t.Fatalf("Should be able to load the private key: %s", err)
+	}
+	if !pubKey.Equal(&pk.PublicKey) {
+		t.Fatalf("Should recover the public key of the signer.")
+	}
+}
+
+func BenchmarkFromAddress(b *testing.B) {
+	tx := database.Tx{ChainID: 1, Nonce: 1, FromID: idKennedy, ToID: idCesar, Value: 100, Tip: 10}
+
+	pristine, err := sign(keyKennedy, tx)
+	if err != nil {
+		b.Fatalf("Should be able to sign transaction: %s", err)
+	}
+
+	b.Run("recover", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			signedTx := pristine
+			if _, err := signedTx.FromAddress(); err != nil {
+				b.Fatalf("Should be able to recover the from address: %s", err)
+			}
+		}
+	})
+
+	b.Run("remembered", func(b *testing.B) {
+		signedTx := pristine
+		if _, err := signedTx.FromAddress(); err != nil {
+			b.Fatalf("Should be able to recover the from address: %s", err)
+		}
+
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := signedTx.FromAddress(); err != nil {
+				b.Fatalf("Should be able to recover the from address: %s", err)
+			}
+		}
+	})
+}
+
+// =============================================================================
+
+func signedTxs(tb testing.TB, n int) []database.SignedTx {
+	txs := make([]database.SignedTx, n)
+	for i := range txs {
+		tx := database.Tx{ChainID: 1, Nonce: uint64(i), FromID: idKennedy, ToID: idCesar, Value: 100, Tip: 10}
+
+		signedTx, err := sign(keyKennedy, tx)
+		if err != nil {
+			tb.Fatalf("Should be able to sign transaction: %s", err)
+		}
+		txs[i] = signedTx
+	}
+
+	return txs
+}
+
+func sign(hexKey string, tx database.Tx) (database.SignedTx, error) {
+	pk, err := crypto.HexToECDSA(hexKey)
+	if err != nil {
+		return database.SignedTx{}, err
+	}
+
+	return tx.Sign(pk)
+}
@@ -0,0 +1,297 @@
+package database
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+)
+
+// CORE NOTE: The binary wire encoding of a signed transaction is a version
+// byte followed by the fields in a fixed order. Integers are big endian and
+// fixed width. Strings and byte slices are written after a 4 byte length,
+// and the big.Int signature values are written as their big endian bytes
+// the same way. Byte slices and big.Int values that can be nil are written
+// with a length of nilLength when nil, so a nil value comes back as nil and
+// the transaction hashes the same after a round trip. The layout must never
+// change for a version, add a new version instead.
+
+// CORE NOTE: The methods are not named MarshalBinary and UnmarshalBinary on
+// purpose. Implementing encoding.BinaryMarshaler would make encoding/gob use
+// them for SignedTx and, through the embedding, for BlockTx, which changes the
+// layout of gob block files and drops the BlockTx fields.
+
+// CORE NOTE: Version 2 adds the outputs of a multi output transaction after
+// the priority, as a 4 byte count followed by the to and value of each
+// output. Transactions without outputs are still written as version 1, so
+// nodes that only know version 1 keep working for those transactions.
+
+// Set of versions of the binary layout written by MarshalWire.
+const (
+	wireVersion        = 1
+	wireVersionOutputs = 2
+)
+
+// nilLength is the length written for a nil byte slice or big.Int.
+const nilLength = math.MaxUint32
+
+// ErrWireVersion is returned when decoding a binary transaction written with
+// a layout this node doesn't know.
+var ErrWireVersion = errors.New("unknown binary transaction version")
+
+// MarshalWire produces the canonical binary wire encoding of the signed
+// transaction.
+func (tx SignedTx) MarshalWire() ([]byte, error) {
+	for _, n := range []int{len(tx.FromID), len(tx.ToID), len(tx.Memo), len(tx.Data)} {
+		if uint64(n) >= nilLength {
+			return nil, errors.New("transaction field too large to encode")
+		}
+	}
+
+	if uint64(len(tx.Outputs)) >= nilLength {
+		return nil, errors.New("transaction field too large to encode")
+	}
+
+	version := byte(wireVersion)
+	if tx.IsMultiOutput() {
+		version = wireVersionOutputs
+	}
+
+	b := make([]byte, 0, 256+len(tx.Memo)+len(tx.Data)+64*len(tx.Outputs))
+
+	b = append(b, version)
+	b = appendUint16(b, tx.ChainID)
+	b = appendUint64(b, tx.Nonce)
+	b = appendBytes(b, []byte(tx.FromID), false)
+	b = appendBytes(b, []byte(tx.ToID), false)
+	b = appendUint64(b, tx.Value)
+	b = appendUint64(b, tx.Tip)
+	b = appendUint64(b, tx.Tx.GasPrice)
+	b = appendUint64(b, tx.Tx.GasUnits)
+	b = appendUint64(b, tx.Deadline)
+	b = appendBytes(b, []byte(tx.Memo), false)
+	b = append(b, tx.Priority)
+	if version == wireVersionOutputs {
+		b = appendUint32(b, uint32(len(tx.Outputs)))
+		for _, out := range tx.Outputs {
+			b = appendBytes(b, []byte(out.ToID), false)
+			b = appendUint64(b, out.Value)
+		}
+	}
+	b = appendBytes(b, tx.Data, tx.Data == nil)
+	b = appendBigInt(b, tx.V)
+	b = appendBigInt(b, tx.R)
+	b = appendBigInt(b, tx.S)
+
+	return b, nil
+}
+
+// UnmarshalWire decodes a signed transaction written by MarshalWire.
+// Truncated input and extra bytes after the transaction are rejected.
+func (tx *SignedTx) UnmarshalWire(data []byte) error {
+	wr := wireReader{data: data}
+
+	version := wr.uint8()
+	if wr.err == nil && version != wireVersion && version != wireVersionOutputs {
+		return fmt.Errorf("%w: %d", ErrWireVersion, version)
+	}
+
+	var stx SignedTx
+	stx.ChainID = wr.uint16()
+	stx.Nonce = wr.uint64()
+	stx.FromID = AccountID(wr.bytes())
+	stx.ToID = AccountID(wr.bytes())
+	stx.Value = wr.uint64()
+	stx.Tip = wr.uint64()
+	stx.Tx.GasPrice = wr.uint64()
+	stx.Tx.GasUnits = wr.uint64()
+	stx.Deadline = wr.uint64()
+	stx.Memo = string(wr.bytes())
+	stx.Priority = wr.uint8()
+	if version == wireVersionOutputs {
+		stx.Outputs = wr.outputs()
+	}
+	stx.Data = wr.bytes()
+	stx.V = wr.bigInt()
+	stx.R = wr.bigInt()
+	stx.S = wr.bigInt()
+
+	if wr.err != nil {
+		return fmt.Errorf("decoding binary transaction: %w", wr.err)
+	}
+	if len(wr.data) != 0 {
+		return fmt.Errorf("decoding binary transaction: %d extra bytes", len(wr.data))
+	}
+
+	*tx = stx
+
+	return nil
+}
+
+// appendUint16 appends the big endian bytes of the value.
+func appendUint16(b []byte, value uint16) []byte {
+	var buf [2]byte
+	binary.BigEndian.PutUint16(buf[:], value)
+	return append(b, buf[:]...)
+}
+
+// appendUint32 appends the big endian bytes of the value.
+func appendUint32(b []byte, value uint32) []byte {
+	var buf [4]byte
+	binary.BigEndian.PutUint32(buf[:], value)
+	return append(b, buf[:]...)
+}
+
+// appendUint64 appends the big endian bytes of the value.
+func appendUint64(b []byte, value uint64) []byte {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], value)
+	return append(b, buf[:]...)
+}
+
+// appendBytes appends the length of the value and the value.
+func appendBytes(b []byte, value []byte, isNil bool) []byte {
+	if isNil {
+		return appendUint32(b, nilLength)
+	}
+
+	b = appendUint32(b, uint32(len(value)))
+	return append(b, value...)
+}
+
+// appendBigInt appends the length of the big endian bytes of the value and
+// the bytes. The sign of the value isn't kept.
+func appendBigInt(b []byte, value *big.Int) []byte {
+	if value == nil {
+		return appendBytes(b, nil, true)
+	}
+
+	return appendBytes(b, value.Bytes(), false)
+}
+
+// =============================================================================
+
+// wireReader reads the fields of the binary encoding in order. After the
+// first error every read returns the zero value, so the error only needs
+// to be checked once at the end.
+type wireReader struct {
+	data []byte
+	err  error
+}
+
+// next returns the next n bytes or records the input is truncated.
+func (wr *wireReader) next(n int) []byte {
+	if wr.err != nil {
+		return nil
+	}
+
+	if len(wr.data) < n {
+		wr.err = io.ErrUnexpectedEOF
+		return nil
+	}
+
+	b := wr.data[:n]
+	wr.data = wr.data[n:]
+
+	return b
+}
+
+func (wr *wireReader) uint8() uint8 {
+	b := wr.next(1)
+	if b == nil {
+		return 0
+	}
+	return b[0]
+}
+
+func (wr *wireReader) uint16() uint16 {
+	b := wr.next(2)
+	if b == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint16(b)
+}
+
+func (wr *wireReader) uint32() uint32 {
+	b := wr.next(4)
+	if b == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint32(b)
+}
+
+func (wr *wireReader) uint64() uint64 {
+	b := wr.next(8)
+	if b == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint64(b)
+}
+
+// bytes returns a copy of the next length prefixed value, which is nil when
+// the value was written as nil.
+func (wr *wireReader) bytes() []byte {
+	b := wr.next(4)
+	if b == nil {
+		return nil
+	}
+
+	n := binary.BigEndian.Uint32(b)
+	if n == nilLength {
+		return nil
+	}
+
+	// Check the length against the input before the conversion to int, which
+	// could overflow on 32 bit platforms.
+	if uint64(n) > uint64(len(wr.data)) {
+		wr.err = io.ErrUnexpectedEOF
+		return nil
+	}
+
+	value := make([]byte, n)
+	copy(value, wr.next(int(n)))
+
+	return value
+}
+
+// bigInt returns the next length prefixed big.Int value, which is nil when
+// the value was written as nil.
+func (wr *wireReader) bigInt() *big.Int {
+	b := wr.bytes()
+	if b == nil {
+		return nil
+	}
+
+	return new(big.Int).SetBytes(b)
+}
+
+// outputs returns the next count prefixed set of outputs. A version 2
+// transaction always has at least one output.
+func (wr *wireReader) outputs() []Output {
+	n := wr.uint32()
+	if wr.err != nil {
+		return nil
+	}
+
+	if n == 0 {
+		wr.err = errors.New("no outputs")
+		return nil
+	}
+
+	// Each output takes at least 12 bytes, so check the count against the
+	// input before allocating.
+	if uint64(n)*12 > uint64(len(wr.data)) {
+		wr.err = io.ErrUnexpectedEOF
+		return nil
+	}
+
+	outputs := make([]Output, n)
+	for i := range outputs {
+		outputs[i].ToID = AccountID(wr.bytes())
+		outputs[i].Value = wr.uint64()
+	}
+
+	return outputs
+}
\ No newline at end of file
@@ -0,0 +1,95 @@
+package database_test
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/andrewyang17/blockchain/foundation/blockchain/database"
+)
+
+func Test_WireRoundTrip(t *testing.T) {
+	type table struct {
+		name string
+		tx   database.Tx
+	}
+
+	tt := []table{
+		{name: "legacy", tx: database.Tx{ChainID: 1, Nonce: 1, FromID: idKennedy, ToID: idCesar, Value: 100, Tip: 10}},
+		{name: "empty data", tx: database.Tx{ChainID: 1, Nonce: 2, FromID: idKennedy, ToID: idCesar, Value: 100, Data: []byte{}}},
+		{name: "all fields", tx: database.Tx{ChainID: 9, Nonce: 3, FromID: idKennedy, ToID: idPavel, Value: 1, Tip: 2, GasPrice: 3, GasUnits: 4, Deadline: 4_102_444_800, Memo: "invoice 42", Priority: 6, Data: []byte("call")}},
+		{name: "outputs", tx: database.Tx{ChainID: 1, Nonce: 4, FromID: idKennedy, Tip: 2, Outputs: []database.Output{{ToID: idPavel, Value: 1}, {ToID: idCesar, Value: 2}}}},
+	}
+
+	for _, tst := range tt {
+		f := func(t *testing.T) {
+			signedTx, err := sign(keyKennedy, tst.tx)
+			if err != nil {
+				t.Fatalf("Test %s:\tShould be able to sign transaction: %s", tst.name, err)
+			}
+
+			data, err := signedTx.MarshalWire()
+			if err != nil {
+				t.Fatalf("Test %s:\tShould be able to marshal transaction: %s", tst.name, err)
+			}
+
+			var got database.SignedTx
+			if err := got.UnmarshalWire(data); err != nil {
+				t.Fatalf("Test %s:\tShould be able to unmarshal transaction: %s", tst.name, err)
+			}
+
+			if got.Hash() != signedTx.Hash() {
+				t.Fatalf("Test %s:\tShould get the same hash after the round trip: got %s, exp %s", tst.name, got.Hash(), signedTx.Hash())
+			}
+			if (got.Data == nil) != (signedTx.Data == nil) {
+				t.Fatalf("Test %s:\tShould keep a nil data as nil: got %v, exp %v", tst.name, got.Data, signedTx.Data)
+			}
+
+			again, err := got.MarshalWire()
+			if err != nil {
+				t.Fatalf("Test %s:\tShould be able to marshal transaction again: %s", tst.name, err)
+			}
+			if string(again) != string(data) {
+				t.Fatalf("Test %s:\tShould get the same bytes after the round trip.", tst.name)
+			}
+
+			if err := got.Validate(tst.tx.ChainID, 0); err != nil {
+				t.Fatalf("Test %s:\tShould still be a valid transaction: %s", tst.name, err)
+			}
+		}
+
+		t.Run(tst.name, f)
+	}
+}
+
+func Test_WireMalformed(t *testing.T) {
+	tx := database.Tx{ChainID: 1, Nonce: 1, FromID: idKennedy, ToID: idCesar, Value: 100, Memo: "memo", Data: []byte("data")}
+
+	signedTx, err := sign(keyKennedy, tx)
+	if err != nil {
+		t.Fatalf("Should be able to sign transaction: %s", err)
+	}
+
+	data, err := signedTx.MarshalWire()
+	if err != nil {
+		t.Fatalf("Should be able to marshal transaction: %s", err)
+	}
+
+	for n := 0; n < len(data); n++ {
+		var got database.SignedTx
+		if err := got.UnmarshalWire(data[:n]); !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Fatalf("Should reject the input truncated to %d bytes: %v", n, err)
+		}
+	}
+
+	var got database.SignedTx
+	if err := got.UnmarshalWire(append(data, 0)); err == nil {
+		t.Fatalf("Should reject extra bytes after the transaction.")
+	}
+
+	bad := append([]byte{}, data...)
+	bad[0] = 99
+	if err := got.UnmarshalWire(bad); !errors.Is(err, database.ErrWireVersion) {
+		t.Fatalf("Should reject an unknown version: %v", err)
+	}
+}
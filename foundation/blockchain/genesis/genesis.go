@@ -2,8 +2,23 @@ package genesis
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// DefaultPath is the location of the genesis file used by Load.
+const DefaultPath = "zblock/genesis.json"
+
+// Set of error variables for validating the genesis file.
+var (
+	ErrNoChainID       = errors.New("chain id must not be zero")
+	ErrNoDifficulty    = errors.New("difficulty must not be zero")
+	ErrNoTransPerBlock = errors.New("trans per block must not be zero")
+	ErrBadBalance      = errors.New("balance account is not properly formatted")
 )
 
 // Genesis represents the genesis file.
@@ -12,26 +27,71 @@ type Genesis struct {
 	ChainID       uint16            `json:"chain_id"`        // The chain id represents an unique id for this running instance.
 	TransPerBlock uint16            `json:"trans_per_block"` // The maximum number of transactions that can be in a block.
 	Difficulty    uint16            `json:"difficulty"`      // How difficult it needs to be to solve the work problem.
+	BlockTime     uint64            `json:"block_time"`      // Target seconds between blocks, zero keeps the difficulty fixed.
 	MiningReward  uint64            `json:"mining_reward"`   // Reward for mining a block.
 	GasPrice      uint64            `json:"gas_price"`       // Fee paid for each transaction mined into a block.
+	MinFee        uint64            `json:"min_fee"`         // Minimum fee a transaction providing gas must pay.
 	Balances      map[string]uint64 `json:"balances"`
 }
 
+// Validate checks the genesis settings can be used to start a chain.
+func (g Genesis) Validate() error {
+	if g.ChainID == 0 {
+		return ErrNoChainID
+	}
+
+	if g.Difficulty == 0 {
+		return ErrNoDifficulty
+	}
+
+	if g.TransPerBlock == 0 {
+		return ErrNoTransPerBlock
+	}
+
+	for account := range g.Balances {
+		if !common.IsHexAddress(account) {
+			return fmt.Errorf("%w: %q", ErrBadBalance, account)
+		}
+	}
+
+	return nil
+}
+
+// TargetBlockTime returns the target time between blocks the difficulty is
+// adjusted to track. Zero means the difficulty is fixed.
+func (g Genesis) TargetBlockTime() time.Duration {
+	return time.Duration(g.BlockTime) * time.Second
+}
+
 // =============================================================================
 
-// Load opens and consumes the genesis file.
+// Load opens and consumes the genesis file at the default path.
 func Load() (Genesis, error) {
-	path := "zblock/genesis.json"
-	content, err := os.ReadFile(path)
+	return LoadFile(DefaultPath)
+}
+
+// LoadFile opens, consumes, and validates the specified genesis file. This
+// allows a custom chain to be started without changing the code.
+func LoadFile(path string) (Genesis, error) {
+	f, err := os.Open(path)
 	if err != nil {
 		return Genesis{}, err
 	}
+	defer f.Close()
+
+	// Fields that aren't known are rejected so a misspelled setting doesn't
+	// silently fall back to the zero value.
+	d := json.NewDecoder(f)
+	d.DisallowUnknownFields()
 
 	var genesis Genesis
-	err = json.Unmarshal(content, &genesis)
-	if err != nil {
-		return Genesis{}, err
+	if err := d.Decode(&genesis); err != nil {
+		return Genesis{}, fmt.Errorf("decoding genesis %s: %w", path, err)
 	}
 
-	return genesis, err
-}
\ No newline at end of file
+	if err := genesis.Validate(); err != nil {
+		return Genesis{}, fmt.Errorf("validating genesis %s: %w", path, err)
+	}
+
+	return genesis, nil
+}
@@ -0,0 +1,90 @@
+package genesis_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/andrewyang17/blockchain/foundation/blockchain/genesis"
+)
+
+const validGenesis = `{
+  "date": "2021-12-17T00:00:00.000000000Z",
+  "chain_id": 7,
+  "trans_per_block": 10,
+  "difficulty": 2,
+  "mining_reward": 700,
+  "gas_price": 15,
+  "min_fee": 15,
+  "balances": {
+    "0xF01813E4B85e178A83e29B8E7bF26BD830a25f32": 1000000
+  }
+}`
+
+// writeGenesis writes the content to a genesis file in a temp directory and
+// returns the path.
+func writeGenesis(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "genesis.json")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("Should be able to write the genesis file: %s", err)
+	}
+
+	return path
+}
+
+func Test_LoadFile(t *testing.T) {
+	gen, err := genesis.LoadFile(writeGenesis(t, validGenesis))
+	if err != nil {
+		t.Fatalf("Should be able to load a valid genesis: %s", err)
+	}
+
+	if gen.ChainID != 7 || gen.Difficulty != 2 || gen.MiningReward != 700 {
+		t.Fatalf("Should get back the settings in the file: %+v", gen)
+	}
+	if gen.Balances["0xF01813E4B85e178A83e29B8E7bF26BD830a25f32"] != 1000000 {
+		t.Fatalf("Should get back the balances in the file: %v", gen.Balances)
+	}
+}
+
+func Test_LoadFileMalformed(t *testing.T) {
+	type table struct {
+		name    string
+		content string
+		err     error
+		msg     string
+	}
+
+	tt := []table{
+		{name: "zero chain id", content: strings.Replace(validGenesis, `"chain_id": 7`, `"chain_id": 0`, 1), err: genesis.ErrNoChainID},
+		{name: "zero difficulty", content: strings.Replace(validGenesis, `"difficulty": 2`, `"difficulty": 0`, 1), err: genesis.ErrNoDifficulty},
+		{name: "zero trans per block", content: strings.Replace(validGenesis, `"trans_per_block": 10`, `"trans_per_block": 0`, 1), err: genesis.ErrNoTransPerBlock},
+		{name: "bad balance account", content: strings.Replace(validGenesis, "0xF01813E4B85e178A83e29B8E7bF26BD830a25f32", "0xbad", 1), err: genesis.ErrBadBalance},
+		{name: "unknown field", content: strings.Replace(validGenesis, `"difficulty"`, `"dificulty"`, 1), msg: `unknown field "dificulty"`},
+		{name: "bad json", content: validGenesis[:40], msg: "unexpected EOF"},
+		{name: "negative balance", content: strings.Replace(validGenesis, "1000000", "-1", 1), msg: "cannot unmarshal number -1"},
+	}
+
+	for _, tst := range tt {
+		f := func(t *testing.T) {
+			_, err := genesis.LoadFile(writeGenesis(t, tst.content))
+			if err == nil {
+				t.Fatalf("Test %s:\tShould not be able to load the genesis.", tst.name)
+			}
+
+			if tst.err != nil && !errors.Is(err, tst.err) {
+				t.Fatalf("Test %s:\tShould get back %q: %v", tst.name, tst.err, err)
+			}
+			if tst.msg != "" && !strings.Contains(err.Error(), tst.msg) {
+				t.Fatalf("Test %s:\tShould get back an error containing %q: %v", tst.name, tst.msg, err)
+			}
+		}
+
+		t.Run(tst.name, f)
+	}
+
+	if _, err := genesis.LoadFile(filepath.Join(t.TempDir(), "missing.json")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("Should get back a not exist error for a missing file: %v", err)
+	}
+}
@@ -2,9 +2,14 @@
 package mempool
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io/fs"
 	"math"
+	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 
@@ -17,6 +22,7 @@ type Mempool struct {
 	mu       sync.RWMutex
 	pool     map[string]database.BlockTx
 	selectFn selector.Func
+	nonceFn  selector.NonceFunc
 }
 
 // New constructs a new mempool using the default tip strategy.
@@ -26,7 +32,20 @@ func New() (*Mempool, error) {
 
 // NewWithStrategy constructs a new mempool with specified sort strategy.
 func NewWithStrategy(strategy string) (*Mempool, error) {
-	selectFn, err := selector.Retrieve(strategy)
+	return NewWithAccountCap(strategy, 0)
+}
+
+// NewWithAccountCap constructs a new mempool with specified sort strategy
+// that considers at most maxPerAccount transactions from each account when
+// picking transactions for a block. Zero means there is no cap.
+func NewWithAccountCap(strategy string, maxPerAccount int) (*Mempool, error) {
+	return NewWithOptions(strategy, selector.Options{MaxPerAccount: maxPerAccount})
+}
+
+// NewWithOptions constructs a new mempool with specified sort strategy and
+// the options for the strategy.
+func NewWithOptions(strategy string, opts selector.Options) (*Mempool, error) {
+	selectFn, err := selector.RetrieveWithOptions(strategy, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -34,6 +53,7 @@ func NewWithStrategy(strategy string) (*Mempool, error) {
 	mp := Mempool{
 		pool:     make(map[string]database.BlockTx),
 		selectFn: selectFn,
+		nonceFn:  selector.WithNonces(selectFn),
 	}
 
 	return &mp, nil
@@ -96,6 +116,67 @@ func (mp *Mempool) Delete(tx database.BlockTx) error {
 	}
 }
 
+// Evict drops transactions until the pool holds no more than maxSize of them
+// and returns the evicted transactions. Only the transaction with the highest
+// nonce for an account can be evicted, so a run of transactions is never left
+// with a gap. Among those the lowest tip goes first and then the oldest. The
+// transaction at the front of each account's run is never evicted, so the
+// pool can be left over maxSize when only those remain.
+func (mp *Mempool) Evict(maxSize int) []database.BlockTx {
+	mp.mu.Lock()
+	defer mp.mu.Unlock()
+	{
+		evicted := []database.BlockTx{}
+		if len(mp.pool) <= maxSize {
+			return evicted
+		}
+
+		// Order the transactions for each account by nonce, so the front of
+		// the run is first and the one that can be evicted is last.
+		runs := make(map[database.AccountID][]database.BlockTx)
+		for key, tx := range mp.pool {
+			account := accountFromMapKey(key)
+			runs[account] = append(runs[account], tx)
+		}
+		for _, txs := range runs {
+			sort.Slice(txs, func(i, j int) bool { return txs[i].Nonce < txs[j].Nonce })
+		}
+
+		for len(mp.pool) > maxSize {
+			var pick database.AccountID
+			var found bool
+			for account, txs := range runs {
+				if len(txs) < 2 {
+					continue
+				}
+
+				tail := txs[len(txs)-1]
+				if !found || evictsBefore(tail, runs[pick][len(runs[pick])-1]) {
+					pick = account
+					found = true
+				}
+			}
+			if !found {
+				break
+			}
+
+			txs := runs[pick]
+			tx := txs[len(txs)-1]
+			runs[pick] = txs[:len(txs)-1]
+
+			key, err := mapKey(tx)
+			if err != nil {
+				break
+			}
+			delete(mp.pool, key)
+
+			evicted = append(evicted, tx)
+		}
+
+		return evicted
+	}
+}
+
 // Truncate clears all the transactions from the pool.
 func (mp *Mempool) Truncate() {
 	mp.mu.Lock()
@@ -105,6 +186,103 @@ func (mp *Mempool) Truncate() {
 	}
 }
 
+// Persist writes the transactions in the mempool to the specified file as
+// JSON, so they can be restored when the node starts again. The file is
+// written to a temporary file first and then renamed into place, so a crash
+// never leaves a partial file behind.
+func (mp *Mempool) Persist(path string) error {
+	var txs []database.BlockTx
+
+	mp.mu.RLock()
+	{
+		txs = make([]database.BlockTx, 0, len(mp.pool))
+		for _, tx := range mp.pool {
+			txs = append(txs, tx)
+		}
+	}
+	mp.mu.RUnlock()
+
+	// Sort the transactions so the file is the same for the same pool.
+	sort.Slice(txs, func(i, j int) bool {
+		if txs[i].FromID != txs[j].FromID {
+			return txs[i].FromID < txs[j].FromID
+		}
+		return txs[i].Nonce < txs[j].Nonce
+	})
+
+	data, err := json.Marshal(txs)
+	if err != nil {
+		return fmt.Errorf("marshal mempool: %w", err)
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("create mempool file: %w", err)
+	}
+	tmpPath := f.Name()
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("write mempool file: %w", err)
+	}
+
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("sync mempool file: %w", err)
+	}
+
+	if err := f.Close(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("close mempool file: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("rename mempool file: %w", err)
+	}
+
+	return nil
+}
+
+// Restore adds the transactions written by Persist to the mempool. The chain
+// may have moved on since the file was written, so each transaction is passed
+// to the validate function first and dropped when it returns an error. A nil
+// validate function keeps every transaction. A missing file restores nothing.
+// The number of transactions restored and dropped are returned.
+func (mp *Mempool) Restore(path string, validate func(tx database.BlockTx) error) (restored int, dropped int, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return 0, 0, nil
+		}
+		return 0, 0, fmt.Errorf("read mempool file: %w", err)
+	}
+
+	var txs []database.BlockTx
+	if err := json.Unmarshal(data, &txs); err != nil {
+		return 0, 0, fmt.Errorf("unmarshal mempool file: %w", err)
+	}
+
+	for _, tx := range txs {
+		if validate != nil {
+			if err := validate(tx); err != nil {
+				dropped++
+				continue
+			}
+		}
+
+		if err := mp.Upsert(tx); err != nil {
+			dropped++
+			continue
+		}
+		restored++
+	}
+
+	return restored, dropped, nil
+}
+
 // PickBest uses the configured sort strategy to return a set of transactions.
 // If 0 is passed, all transactions in the mempool will be returned.
 func (mp *Mempool) PickBest(howMany ...uint16) []database.BlockTx {
@@ -118,9 +296,47 @@ func (mp *Mempool) PickBest(howMany ...uint16) []database.BlockTx {
 	// transactions for the next block, the Ardan blockchain is currently not
 	// focused on block size but a max number of transactions.
 
-	// Copy all the transactions for each account into separate slices.
+	m, number := mp.copyByAccount(number)
+
+	return mp.selectFn(m, number)
+}
+
+// PickBestExecutable works like PickBest but only selects transactions that
+// can execute given the current nonce of each account. The transactions for
+// an account stop at the first nonce gap.
+func (mp *Mempool) PickBestExecutable(nonces map[database.AccountID]uint64, howMany ...uint16) []database.BlockTx {
+	number := 0
+	if len(howMany) > 0 {
+		number = int(howMany[0])
+	}
+
+	m, number := mp.copyByAccount(number)
+
+	return mp.nonceFn(m, nonces, number)
+}
+
+// PickBestFromSource works like PickBestExecutable but loads the current
+// nonce of each account in the pool from the source in one call.
+func (mp *Mempool) PickBestFromSource(src selector.NonceSource, howMany ...uint16) []database.BlockTx {
+	number := 0
+	if len(howMany) > 0 {
+		number = int(howMany[0])
+	}
+
+	m, number := mp.copyByAccount(number)
+	nonces := selector.PrepareContext(m, src)
+
+	return mp.nonceFn(m, nonces, number)
+}
+
+// copyByAccount copies all the transactions for each account into separate
+// slices. If number is 0, it's replaced with the number of transactions in
+// the pool.
+func (mp *Mempool) copyByAccount(number int) (map[database.AccountID][]database.BlockTx, int) {
 	m := make(map[database.AccountID][]database.BlockTx)
+
 	mp.mu.RLock()
+	defer mp.mu.RUnlock()
 	{
 		if number == 0 {
 			number = len(mp.pool)
@@ -131,13 +347,28 @@ func (mp *Mempool) PickBest(howMany ...uint16) []database.BlockTx {
 			m[account] = append(m[account], tx)
 		}
 	}
-	mp.mu.RUnlock()
 
-	return mp.selectFn(m, number)
+	return m, number
 }
 
 // =============================================================================
 
+// evictsBefore reports if the transaction should be evicted before the other
+// transaction. The lowest tip goes first, then the oldest, and then the
+// highest hash so every node evicts in the same order.
+func evictsBefore(tx database.BlockTx, other database.BlockTx) bool {
+	switch {
+	case tx.Tip != other.Tip:
+		return tx.Tip < other.Tip
+
+	case tx.TimeStamp != other.TimeStamp:
+		return tx.TimeStamp < other.TimeStamp
+
+	default:
+		return tx.SignedTx.Hash() > other.SignedTx.Hash()
+	}
+}
+
 // mapKey is used to generate the map key.
 func mapKey(tx database.BlockTx) (string, error) {
 	return fmt.Sprintf("%s:%d", tx.FromID, tx.Nonce), nil
@@ -1,6 +1,8 @@
 package mempool_test
 
 import (
+	"errors"
+	"path/filepath"
 	"testing"
 
 	"github.com/andrewyang17/blockchain/foundation/blockchain/database"
@@ -104,6 +106,174 @@ func Test_CRUD(t *testing.T) {
 	}
 }
 
+func Test_Evict(t *testing.T) {
+	const (
+		kennedy = "0xF01813E4B85e178A83e29B8E7bF26BD830a25f32"
+		pavel   = "0xdd6B972ffcc631a62CAE1BB9d80b7ff429c8ebA4"
+		ed      = "0xa988b1866EaBF72B4c53b592c97aAD8e4b9bDCC0"
+	)
+
+	tran := func(from string, nonce uint64, tip uint64, timeStamp uint64) database.BlockTx {
+		return database.BlockTx{
+			SignedTx:  database.SignedTx{Tx: database.Tx{Nonce: nonce, FromID: database.AccountID(from), Tip: tip}},
+			TimeStamp: timeStamp,
+		}
+	}
+
+	type table struct {
+		name    string
+		txs     []database.BlockTx
+		maxSize int
+		evicted []string
+	}
+
+	tt := []table{
+		{
+			name:    "under capacity",
+			txs:     []database.BlockTx{tran(kennedy, 1, 10, 1), tran(kennedy, 2, 10, 1)},
+			maxSize: 2,
+			evicted: []string{},
+		},
+		{
+			name: "lowest tip from the tail",
+			txs: []database.BlockTx{
+				tran(kennedy, 1, 50, 1), tran(kennedy, 2, 40, 1), tran(kennedy, 3, 30, 1), tran(kennedy, 4, 5, 1),
+				tran(pavel, 1, 1, 1), tran(pavel, 2, 2, 1),
+				tran(ed, 1, 0, 1),
+			},
+			maxSize: 4,
+			evicted: []string{pavel + ":2", kennedy + ":4", kennedy + ":3"},
+		},
+		{
+			name: "oldest on equal tip",
+			txs: []database.BlockTx{
+				tran(kennedy, 1, 10, 200), tran(kennedy, 2, 10, 200),
+				tran(pavel, 1, 10, 100), tran(pavel, 2, 10, 100),
+			},
+			maxSize: 3,
+			evicted: []string{pavel + ":2"},
+		},
+		{
+			name:    "fronts protected",
+			txs:     []database.BlockTx{tran(kennedy, 1, 1, 1), tran(pavel, 1, 1, 1), tran(ed, 1, 1, 1)},
+			maxSize: 1,
+			evicted: []string{},
+		},
+	}
+
+	for _, tst := range tt {
+		f := func(t *testing.T) {
+			mp, err := mempool.New()
+			if err != nil {
+				t.Fatalf("Test %s:\tShould be able to create the mempool: %s", tst.name, err)
+			}
+
+			for _, tx := range tst.txs {
+				if err := mp.Upsert(tx); err != nil {
+					t.Fatalf("Test %s:\tShould be able to add the transaction: %s", tst.name, err)
+				}
+			}
+
+			evicted := mp.Evict(tst.maxSize)
+			if evicted == nil {
+				t.Fatalf("Test %s:\tShould get back a non nil slice.", tst.name)
+			}
+
+			if len(evicted) != len(tst.evicted) {
+				t.Fatalf("Test %s:\tShould evict %d transactions, got %d", tst.name, len(tst.evicted), len(evicted))
+			}
+			for i, tx := range evicted {
+				if tx.String() != tst.evicted[i] {
+					t.Fatalf("Test %s:\tShould evict %s in position %d, got %s", tst.name, tst.evicted[i], i, tx)
+				}
+			}
+
+			if exp := len(tst.txs) - len(tst.evicted); mp.Count() != exp {
+				t.Fatalf("Test %s:\tShould have %d transactions left, got %d", tst.name, exp, mp.Count())
+			}
+		}
+
+		t.Run(tst.name, f)
+	}
+}
+
+func Test_Persist(t *testing.T) {
+	const (
+		kennedy    = "0xF01813E4B85e178A83e29B8E7bF26BD830a25f32"
+		kennedyKey = "9f332e3700d8fc2446eaf6d15034cf96e0c2745e40353deef032a5dbf1dfed93"
+		pavel      = "0xdd6B972ffcc631a62CAE1BB9d80b7ff429c8ebA4"
+		pavelKey   = "fae85851bdf5c9f49923722ce38f3c1defcfd3619ef5453230a58ad805499959"
+	)
+
+	mp, err := mempool.New()
+	if err != nil {
+		t.Fatalf("Test persist:\tShould be able to create the mempool: %s", err)
+	}
+
+	txs := []struct {
+		tx     database.Tx
+		hexKey string
+	}{
+		{database.Tx{Nonce: 1, FromID: kennedy, ToID: pavel, Value: 10}, kennedyKey},
+		{database.Tx{Nonce: 2, FromID: kennedy, ToID: pavel, Value: 10}, kennedyKey},
+		{database.Tx{Nonce: 3, FromID: kennedy, ToID: pavel, Value: 10}, kennedyKey},
+		{database.Tx{Nonce: 1, FromID: pavel, ToID: kennedy, Value: 10}, pavelKey},
+	}
+
+	for _, tx := range txs {
+		blockTx, err := sign(tx.hexKey, tx.tx)
+		if err != nil {
+			t.Fatalf("Test persist:\tShould be able to sign the transaction: %s", err)
+		}
+		if err := mp.Upsert(blockTx); err != nil {
+			t.Fatalf("Test persist:\tShould be able to add the transaction: %s", err)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "mempool.json")
+	if err := mp.Persist(path); err != nil {
+		t.Fatalf("Test persist:\tShould be able to persist the mempool: %s", err)
+	}
+
+	// Pretend kennedy's first transaction was mined while the node was down.
+	nonces := map[database.AccountID]uint64{kennedy: 1}
+	validate := func(tx database.BlockTx) error {
+		if tx.Nonce <= nonces[tx.FromID] {
+			return errors.New("stale nonce")
+		}
+		return tx.VerifySignatureOnly()
+	}
+
+	restoredMP, err := mempool.New()
+	if err != nil {
+		t.Fatalf("Test persist:\tShould be able to create the mempool: %s", err)
+	}
+
+	restored, dropped, err := restoredMP.Restore(path, validate)
+	if err != nil {
+		t.Fatalf("Test persist:\tShould be able to restore the mempool: %s", err)
+	}
+
+	if restored != 3 || dropped != 1 {
+		t.Fatalf("Test persist:\tShould restore 3 and drop 1, got %d and %d", restored, dropped)
+	}
+
+	if restoredMP.Count() != 3 {
+		t.Fatalf("Test persist:\tShould have 3 transactions in the mempool, got %d", restoredMP.Count())
+	}
+
+	for _, tx := range restoredMP.PickBest() {
+		if tx.FromID == kennedy && tx.Nonce == 1 {
+			t.Fatalf("Test persist:\tShould not restore the stale transaction %s", tx)
+		}
+	}
+
+	restored, dropped, err = restoredMP.Restore(filepath.Join(t.TempDir(), "missing.json"), validate)
+	if err != nil || restored != 0 || dropped != 0 {
+		t.Fatalf("Test persist:\tShould restore nothing from a missing file, got %d, %d, %v", restored, dropped, err)
+	}
+}
+
 // =============================================================================
 
 func sign(hexKey string, tx database.Tx) (database.BlockTx, error) {
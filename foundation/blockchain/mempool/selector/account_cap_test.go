@@ -0,0 +1,97 @@
+package selector_test
+
+import (
+	"testing"
+
+	"github.com/andrewyang17/blockchain/foundation/blockchain/database"
+	"github.com/andrewyang17/blockchain/foundation/blockchain/mempool/selector"
+)
+
+func TestAccountCap(t *testing.T) {
+	tran := func(nonce uint64, from string, tip uint64) database.BlockTx {
+		return database.BlockTx{
+			SignedTx: database.SignedTx{
+				Tx: database.Tx{Nonce: nonce, FromID: database.AccountID(from), Tip: tip},
+			},
+		}
+	}
+
+	const maxPerAccount = 64
+
+	strategies := []string{selector.StrategyTip, selector.StrategyTipAdvanced, selector.StrategyFIFO, selector.StrategyGasPrice, selector.StrategyPriorityTip}
+
+	for _, strategy := range strategies {
+		f := func(t *testing.T) {
+			m := make(map[database.AccountID][]database.BlockTx)
+
+			// Load the nonces in reverse to make sure the cap keeps the
+			// start of the nonce run and not the first ones received.
+			pavel := database.AccountID(fromPavel)
+			for nonce := uint64(200); nonce >= 1; nonce-- {
+				m[pavel] = append(m[pavel], tran(nonce, fromPavel, 100))
+			}
+			bill := database.AccountID(fromBill)
+			m[bill] = append(m[bill], tran(1, fromBill, 1))
+
+			sort, err := selector.RetrieveWithAccountCap(strategy, maxPerAccount)
+			if err != nil {
+				t.Fatalf("Should be able to get sort strategy function: %s", err)
+			}
+
+			txs := sort(m, 1000)
+			if len(txs) != maxPerAccount+1 {
+				t.Fatalf("Should get %d after sort, but got %d", maxPerAccount+1, len(txs))
+			}
+
+			var next uint64 = 1
+			var gotBill bool
+			for _, tx := range txs {
+				if tx.FromID == bill {
+					gotBill = true
+					continue
+				}
+				if tx.Nonce != next {
+					t.Fatalf("Should get nonce %d for pavel, but got %d", next, tx.Nonce)
+				}
+				next++
+			}
+
+			if !gotBill {
+				t.Fatalf("Should select the transaction for bill.")
+			}
+		}
+
+		t.Run(strategy, f)
+	}
+}
+
+func TestAccountCapNonces(t *testing.T) {
+	tran := func(nonce uint64, from string) database.BlockTx {
+		return database.BlockTx{
+			SignedTx: database.SignedTx{
+				Tx: database.Tx{Nonce: nonce, FromID: database.AccountID(from)},
+			},
+		}
+	}
+
+	pavel := database.AccountID(fromPavel)
+	m := make(map[database.AccountID][]database.BlockTx)
+	for nonce := uint64(1); nonce <= 20; nonce++ {
+		m[pavel] = append(m[pavel], tran(nonce, fromPavel))
+	}
+
+	fn, err := selector.RetrieveWithAccountCap(selector.StrategyTip, 5)
+	if err != nil {
+		t.Fatalf("Should be able to get sort strategy function: %s", err)
+	}
+
+	// The cap applies to the run that can execute, after the used nonces
+	// have been dropped.
+	txs := selector.WithNonces(fn)(m, map[database.AccountID]uint64{pavel: 10}, 100)
+	if len(txs) != 5 {
+		t.Fatalf("Should get 5 after sort, but got %d", len(txs))
+	}
+	if txs[0].Nonce != 11 || txs[4].Nonce != 15 {
+		t.Fatalf("Should get nonces 11 to 15, but got %d to %d", txs[0].Nonce, txs[4].Nonce)
+	}
+}
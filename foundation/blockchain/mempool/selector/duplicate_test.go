@@ -0,0 +1,59 @@
+package selector_test
+
+import (
+	"testing"
+
+	"github.com/andrewyang17/blockchain/foundation/blockchain/database"
+	"github.com/andrewyang17/blockchain/foundation/blockchain/mempool/selector"
+)
+
+func TestDropDuplicates(t *testing.T) {
+	tran := func(nonce uint64, hexKey string, tip uint64) database.BlockTx {
+		const toID = "0xbEE6ACE826eC3DE1B6349888B9151B92522F7F76"
+
+		fromIDs := map[string]string{
+			signPavel: fromPavel,
+			signBill:  fromBill,
+		}
+
+		tx, err := sign(hexKey, database.Tx{Nonce: nonce, FromID: database.AccountID(fromIDs[hexKey]), ToID: toID, Tip: tip})
+		if err != nil {
+			t.Fatalf("Should be able to sign transaction: %s", err)
+		}
+		return tx
+	}
+
+	pavel1 := tran(1, signPavel, 10)
+	pavel2 := tran(2, signPavel, 10)
+	bill1 := tran(1, signBill, 5)
+
+	for _, strategy := range []string{selector.StrategyTip, selector.StrategyTipAdvanced, selector.StrategyFIFO, selector.StrategyRandom, selector.StrategyGasPrice} {
+		m := map[database.AccountID][]database.BlockTx{
+			pavel1.FromID: {pavel1, pavel2, pavel1, tran(2, signPavel, 50)},
+			bill1.FromID:  {bill1, bill1},
+		}
+
+		sort, err := selector.Retrieve(strategy)
+		if err != nil {
+			t.Fatalf("Test %s:\tShould be able to get sort strategy function: %s", strategy, err)
+		}
+
+		txs := sort(m, 10)
+		if len(txs) != 3 {
+			t.Fatalf("Test %s:\tShould get back 3 transactions, got %d", strategy, len(txs))
+		}
+
+		seen := make(map[string]bool)
+		for _, tx := range txs {
+			hash := tx.SignedTx.Hash()
+			if seen[hash] {
+				t.Fatalf("Test %s:\tShould not select the same transaction twice: %s", strategy, tx)
+			}
+			seen[hash] = true
+
+			if tx.FromID == pavel2.FromID && tx.Nonce == 2 && tx.Tip != 50 {
+				t.Fatalf("Test %s:\tShould keep the duplicate with the highest tip: got %d", strategy, tx.Tip)
+			}
+		}
+	}
+}
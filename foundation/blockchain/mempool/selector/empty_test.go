@@ -0,0 +1,59 @@
+package selector_test
+
+import (
+	"testing"
+
+	"github.com/andrewyang17/blockchain/foundation/blockchain/database"
+	"github.com/andrewyang17/blockchain/foundation/blockchain/mempool/selector"
+)
+
+func TestEmptySelect(t *testing.T) {
+	strategies := []string{
+		selector.StrategyTip,
+		selector.StrategyTipAdvanced,
+		selector.StrategyFIFO,
+		selector.StrategyRandom,
+		selector.StrategyGasPrice,
+		selector.StrategyPriorityTip,
+		selector.StrategyTipAge,
+		selector.StrategyKnapsack,
+	}
+
+	pavel := database.AccountID(fromPavel)
+	tran := database.BlockTx{SignedTx: database.SignedTx{Tx: database.Tx{Nonce: 1, FromID: pavel, Tip: 10}}}
+
+	type test struct {
+		name    string
+		m       map[database.AccountID][]database.BlockTx
+		howMany int
+	}
+
+	tt := []test{
+		{name: "nil pool", m: nil, howMany: 10},
+		{name: "empty pool", m: map[database.AccountID][]database.BlockTx{}, howMany: 10},
+		{name: "empty account", m: map[database.AccountID][]database.BlockTx{pavel: {}}, howMany: 10},
+		{name: "zero howMany", m: map[database.AccountID][]database.BlockTx{pavel: {tran}}, howMany: 0},
+		{name: "negative howMany", m: map[database.AccountID][]database.BlockTx{pavel: {tran}}, howMany: -1},
+	}
+
+	for _, strategy := range strategies {
+		fn, err := selector.Retrieve(strategy)
+		if err != nil {
+			t.Fatalf("Should be able to get sort strategy function %s: %s", strategy, err)
+		}
+
+		for _, tst := range tt {
+			f := func(t *testing.T) {
+				txs := fn(tst.m, tst.howMany)
+				if txs == nil {
+					t.Fatalf("Test %s:\tShould get back a non-nil slice", tst.name)
+				}
+				if len(txs) != 0 {
+					t.Fatalf("Test %s:\tShould get back no transactions, got %d", tst.name, len(txs))
+				}
+			}
+
+			t.Run(strategy+"/"+tst.name, f)
+		}
+	}
+}
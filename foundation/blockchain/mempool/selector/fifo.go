@@ -0,0 +1,52 @@
+package selector
+
+import (
+	"sort"
+
+	"github.com/andrewyang17/blockchain/foundation/blockchain/database"
+)
+
+// fifoSelect returns transactions in the order they were received by the
+// node. An account's transactions can only be selected by nonce, so a later
+// nonce received first waits for the earlier nonce to be selected. This keeps
+// the nonce ordering for each account/transaction.
+var fifoSelect = func(m map[database.AccountID][]database.BlockTx, howMany int) []database.BlockTx {
+
+	// Sort the transactions per account by nonce.
+	for key := range m {
+		if len(m[key]) > 1 {
+			sort.Sort(byNonce(m[key]))
+		}
+	}
+
+	// Only the next transaction by nonce for each account can be selected.
+	// Take the one that was received first until the number of requested
+	// transactions is fulfilled or there are no more transactions. Ties are
+	// broken by account to keep the selection deterministic.
+	final := []database.BlockTx{}
+	for len(final) < howMany {
+		var first database.AccountID
+		for key := range m {
+			if len(m[key]) == 0 {
+				continue
+			}
+
+			switch {
+			case first == "":
+				first = key
+			case m[key][0].TimeStamp < m[first][0].TimeStamp:
+				first = key
+			case m[key][0].TimeStamp == m[first][0].TimeStamp && key < first:
+				first = key
+			}
+		}
+		if first == "" {
+			break
+		}
+
+		final = append(final, m[first][0])
+		m[first] = m[first][1:]
+	}
+
+	return final
+}
@@ -0,0 +1,88 @@
+package selector_test
+
+import (
+	"testing"
+
+	"github.com/andrewyang17/blockchain/foundation/blockchain/database"
+	"github.com/andrewyang17/blockchain/foundation/blockchain/mempool/selector"
+)
+
+func TestFIFOSort(t *testing.T) {
+	tran := func(nonce uint64, from string, received uint64) database.BlockTx {
+		tx := database.BlockTx{
+			SignedTx: database.SignedTx{
+				Tx: database.Tx{Nonce: nonce, FromID: database.AccountID(from), Tip: 100 - received},
+			},
+		}
+		tx.TimeStamp = received
+		return tx
+	}
+
+	type test struct {
+		name    string
+		txs     []database.BlockTx
+		howMany int
+		best    []database.BlockTx
+	}
+
+	tt := []test{
+		{
+			name: "receipt order",
+			txs: []database.BlockTx{
+				tran(0, fromEd, 3),
+				tran(0, fromPavel, 1),
+				tran(1, fromPavel, 4),
+				tran(0, fromBill, 2),
+			},
+			howMany: 10,
+			best: []database.BlockTx{
+				tran(0, fromPavel, 1),
+				tran(0, fromBill, 2),
+				tran(0, fromEd, 3),
+				tran(1, fromPavel, 4),
+			},
+		},
+		{
+			name: "later nonce received first",
+			txs: []database.BlockTx{
+				tran(1, fromPavel, 1),
+				tran(0, fromBill, 2),
+				tran(0, fromPavel, 3),
+				tran(1, fromBill, 4),
+			},
+			howMany: 3,
+			best: []database.BlockTx{
+				tran(0, fromBill, 2),
+				tran(0, fromPavel, 3),
+				tran(1, fromPavel, 1),
+			},
+		},
+	}
+
+	for _, tst := range tt {
+		f := func(t *testing.T) {
+			m := make(map[database.AccountID][]database.BlockTx)
+			for _, tx := range tst.txs {
+				m[tx.FromID] = append(m[tx.FromID], tx)
+			}
+
+			sort, err := selector.Retrieve(selector.StrategyFIFO)
+			if err != nil {
+				t.Fatalf("Test %s:\tShould be able to get sort strategy function: %s", tst.name, err)
+			}
+
+			txs := sort(m, tst.howMany)
+			if len(txs) != len(tst.best) {
+				t.Fatalf("Test %s:\tShould get %d after sort, but got %d", tst.name, len(tst.best), len(txs))
+			}
+
+			for i, exp := range tst.best {
+				if exp.Nonce != txs[i].Nonce || exp.FromID != txs[i].FromID {
+					t.Fatalf("Test %s:\tShould get back the right from/nonce at %d: got %s/%d, exp %s/%d", tst.name, i, txs[i].FromID, txs[i].Nonce, exp.FromID, exp.Nonce)
+				}
+			}
+		}
+
+		t.Run(tst.name, f)
+	}
+}
@@ -0,0 +1,16 @@
+package selector
+
+import (
+	"sort"
+
+	"github.com/andrewyang17/blockchain/foundation/blockchain/database"
+)
+
+// gasPriceSelect returns transactions with the best gas price while
+// respecting the nonce for each account/transaction. This works like the
+// tipSelect function but uses the gas price the block will be charged at.
+var gasPriceSelect = func(m map[database.AccountID][]database.BlockTx, howMany int) []database.BlockTx {
+	return rowSelect(m, howMany, func(row []database.BlockTx) {
+		sort.Sort(byGasPrice(row))
+	})
+}
@@ -0,0 +1,67 @@
+package selector_test
+
+import (
+	"testing"
+
+	"github.com/andrewyang17/blockchain/foundation/blockchain/database"
+	"github.com/andrewyang17/blockchain/foundation/blockchain/mempool/selector"
+)
+
+func TestGasPriceSort(t *testing.T) {
+	tran := func(nonce uint64, from string, tip uint64, gasPrice uint64) database.BlockTx {
+		tx := database.BlockTx{
+			SignedTx: database.SignedTx{
+				Tx: database.Tx{Nonce: nonce, FromID: database.AccountID(from), Tip: tip},
+			},
+		}
+		tx.GasPrice = gasPrice
+		return tx
+	}
+
+	// Pavel pays the best tip, but Ed pays the best gas price.
+	pool := []database.BlockTx{
+		tran(0, fromPavel, 100, 10),
+		tran(1, fromPavel, 100, 10),
+		tran(0, fromBill, 50, 20),
+		tran(1, fromBill, 50, 20),
+		tran(0, fromEd, 10, 30),
+		tran(1, fromEd, 10, 30),
+	}
+
+	type test struct {
+		strategy string
+		best     []database.AccountID
+	}
+
+	tt := []test{
+		{strategy: selector.StrategyTip, best: []database.AccountID{database.AccountID(fromPavel), database.AccountID(fromBill)}},
+		{strategy: selector.StrategyGasPrice, best: []database.AccountID{database.AccountID(fromEd), database.AccountID(fromBill)}},
+	}
+
+	for _, tst := range tt {
+		f := func(t *testing.T) {
+			m := make(map[database.AccountID][]database.BlockTx)
+			for _, tx := range pool {
+				m[tx.FromID] = append(m[tx.FromID], tx)
+			}
+
+			sort, err := selector.Retrieve(tst.strategy)
+			if err != nil {
+				t.Fatalf("Test %s:\tShould be able to get sort strategy function: %s", tst.strategy, err)
+			}
+
+			txs := sort(m, len(tst.best))
+			if len(txs) != len(tst.best) {
+				t.Fatalf("Test %s:\tShould get %d after sort, but got %d", tst.strategy, len(tst.best), len(txs))
+			}
+
+			for i, exp := range tst.best {
+				if txs[i].FromID != exp || txs[i].Nonce != 0 {
+					t.Fatalf("Test %s:\tShould get back the right from/nonce at %d: got %s/%d, exp %s/0", tst.strategy, i, txs[i].FromID, txs[i].Nonce, exp)
+				}
+			}
+		}
+
+		t.Run(tst.strategy, f)
+	}
+}
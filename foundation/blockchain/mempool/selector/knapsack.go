@@ -0,0 +1,102 @@
+package selector
+
+import (
+	"math/bits"
+	"sort"
+
+	"github.com/andrewyang17/blockchain/foundation/blockchain/database"
+)
+
+// newKnapsackSelect returns a strategy that packs transactions into maxBytes
+// of encoded transaction data to approximately maximize the total tip, while
+// respecting the nonce for each account/transaction. A maxBytes of zero or
+// less means there is no byte budget.
+//
+// CORE NOTE: Picking the set of transactions with the largest total tip that
+// fits the budget is the knapsack problem. The greedy heuristic picks the next
+// transaction with the best tip per byte, which gets close to the best total
+// and is fast enough for a block. Only the next transaction by nonce for each
+// account can be picked, so a low tip transaction blocks the ones behind it
+// until it's picked, and an account whose next transaction doesn't fit the
+// remaining budget is done for this block.
+func newKnapsackSelect(maxBytes int) Func {
+	return func(m map[database.AccountID][]database.BlockTx, howMany int) []database.BlockTx {
+
+		// Sort the transactions per account by nonce and work out the size
+		// of each one. A transaction that can't be encoded ends the run for
+		// its account.
+		sizes := make(map[database.AccountID][]int, len(m))
+		for key, txs := range m {
+			if len(txs) > 1 {
+				sort.Sort(byNonce(txs))
+			}
+
+			for i, tx := range txs {
+				n, err := txSize(tx)
+				if err != nil {
+					m[key] = txs[:i]
+					break
+				}
+				sizes[key] = append(sizes[key], n)
+			}
+		}
+
+		// Keep picking the account whose next transaction has the best tip
+		// per byte and still fits until the amount is fulfilled or nothing
+		// else fits.
+		final := []database.BlockTx{}
+		var size int
+		for len(final) < howMany {
+			var best database.AccountID
+			var found bool
+
+			for key, txs := range m {
+				if len(txs) == 0 {
+					continue
+				}
+
+				if maxBytes > 0 && size+sizes[key][0] > maxBytes {
+					m[key] = nil
+					continue
+				}
+
+				if !found || knapsackBefore(txs[0], sizes[key][0], m[best][0], sizes[best][0]) {
+					best, found = key, true
+				}
+			}
+			if !found {
+				break
+			}
+
+			final = append(final, m[best][0])
+			size += sizes[best][0]
+			m[best] = m[best][1:]
+			sizes[best] = sizes[best][1:]
+		}
+
+		return final
+	}
+}
+
+// knapsackBefore reports if transaction a with sizeA bytes should be picked
+// before transaction b with sizeB bytes. The higher tip per byte wins, then
+// the higher tip, then the older transaction and the lower account so the
+// selection doesn't depend on map order. The tips per byte are compared by
+// cross multiplying in 128 bits so nothing is lost to division or overflow.
+func knapsackBefore(a database.BlockTx, sizeA int, b database.BlockTx, sizeB int) bool {
+	aHi, aLo := bits.Mul64(a.Tip, uint64(sizeB))
+	bHi, bLo := bits.Mul64(b.Tip, uint64(sizeA))
+
+	switch {
+	case aHi != bHi:
+		return aHi > bHi
+	case aLo != bLo:
+		return aLo > bLo
+	case a.Tip != b.Tip:
+		return a.Tip > b.Tip
+	case a.TimeStamp != b.TimeStamp:
+		return a.TimeStamp < b.TimeStamp
+	default:
+		return a.FromID < b.FromID
+	}
+}
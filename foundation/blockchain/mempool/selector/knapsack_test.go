@@ -0,0 +1,136 @@
+package selector_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/andrewyang17/blockchain/foundation/blockchain/database"
+	"github.com/andrewyang17/blockchain/foundation/blockchain/mempool/selector"
+)
+
+func TestKnapsackSelect(t *testing.T) {
+	tran := func(nonce uint64, from string, tip uint64, data string) database.BlockTx {
+		return database.BlockTx{
+			SignedTx: database.SignedTx{
+				Tx: database.Tx{Nonce: nonce, FromID: database.AccountID(from), Tip: tip, Data: []byte(data)},
+			},
+		}
+	}
+
+	size := func(txs ...database.BlockTx) int {
+		var n int
+		for _, tx := range txs {
+			data, err := json.Marshal(tx)
+			if err != nil {
+				t.Fatalf("Should be able to marshal transaction: %s", err)
+			}
+			n += len(data)
+		}
+		return n
+	}
+
+	total := func(txs []database.BlockTx) uint64 {
+		var tip uint64
+		for _, tx := range txs {
+			tip += tx.Tip
+		}
+		return tip
+	}
+
+	// The big transaction has the single best tip, but the three small ones
+	// fit in the same space and pay more together.
+	big := tran(0, fromPavel, 50, strings.Repeat("a", 600))
+	small1 := tran(0, fromBill, 20, "")
+	small2 := tran(1, fromBill, 20, "")
+	small3 := tran(0, fromEd, 20, "")
+
+	pool := func() map[database.AccountID][]database.BlockTx {
+		return map[database.AccountID][]database.BlockTx{
+			big.FromID:    {big},
+			small1.FromID: {small2, small1},
+			small3.FromID: {small3},
+		}
+	}
+
+	maxBytes := size(big)
+	if size(small1, small2, small3) > maxBytes {
+		t.Fatalf("Should have small transactions that fit the budget: %d > %d", size(small1, small2, small3), maxBytes)
+	}
+
+	tip, err := selector.RetrieveWithLimit(selector.StrategyTip)
+	if err != nil {
+		t.Fatalf("Should be able to get the tip strategy function: %s", err)
+	}
+	tipTxs := tip(pool(), 10, maxBytes)
+
+	knapsack, err := selector.RetrieveWithOptions(selector.StrategyKnapsack, selector.Options{MaxBytes: maxBytes})
+	if err != nil {
+		t.Fatalf("Should be able to get the knapsack strategy function: %s", err)
+	}
+	knapTxs := knapsack(pool(), 10)
+
+	if size(knapTxs...) > maxBytes {
+		t.Fatalf("Should stay within the byte budget: got %d, exp <= %d", size(knapTxs...), maxBytes)
+	}
+
+	if total(knapTxs) <= total(tipTxs) {
+		t.Fatalf("Should get more total tip than the tip strategy: got %d, tip %d", total(knapTxs), total(tipTxs))
+	}
+
+	if len(knapTxs) != 3 {
+		t.Fatalf("Should get the three small transactions, but got %d", len(knapTxs))
+	}
+
+	nonces := make(map[database.AccountID]uint64)
+	for _, tx := range knapTxs {
+		if tx.FromID == big.FromID {
+			t.Fatalf("Should not select the big transaction.")
+		}
+		if exp, exists := nonces[tx.FromID]; exists && tx.Nonce != exp {
+			t.Fatalf("Should respect the nonce order for %s: got %d, exp %d", tx.FromID, tx.Nonce, exp)
+		}
+		nonces[tx.FromID] = tx.Nonce + 1
+	}
+}
+
+func TestKnapsackNonceOrder(t *testing.T) {
+	tran := func(nonce uint64, from string, tip uint64) database.BlockTx {
+		return database.BlockTx{
+			SignedTx: database.SignedTx{
+				Tx: database.Tx{Nonce: nonce, FromID: database.AccountID(from), Tip: tip},
+			},
+		}
+	}
+
+	// The best tip sits behind a poor one, so the poor one has to be picked
+	// first even though the other account pays more per byte.
+	m := map[database.AccountID][]database.BlockTx{
+		database.AccountID(fromPavel): {tran(1, fromPavel, 100), tran(0, fromPavel, 1)},
+		database.AccountID(fromBill):  {tran(0, fromBill, 10)},
+	}
+
+	fn, err := selector.Retrieve(selector.StrategyKnapsack)
+	if err != nil {
+		t.Fatalf("Should be able to get the knapsack strategy function: %s", err)
+	}
+
+	txs := fn(m, 3)
+	if len(txs) != 3 {
+		t.Fatalf("Should get all transactions back, but got %d", len(txs))
+	}
+
+	exp := []struct {
+		from  string
+		nonce uint64
+	}{
+		{fromBill, 0},
+		{fromPavel, 0},
+		{fromPavel, 1},
+	}
+	for i, e := range exp {
+		if string(txs[i].FromID) != e.from || txs[i].Nonce != e.nonce {
+			t.Fatalf("Should get %s/%d at %d, but got %s/%d", e.from, e.nonce, i, txs[i].FromID, txs[i].Nonce)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package selector_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/andrewyang17/blockchain/foundation/blockchain/database"
+	"github.com/andrewyang17/blockchain/foundation/blockchain/mempool/selector"
+)
+
+func TestRetrieveWithLimit(t *testing.T) {
+	tran := func(nonce uint64, from string, tip uint64, data string) database.BlockTx {
+		return database.BlockTx{
+			SignedTx: database.SignedTx{
+				Tx: database.Tx{Nonce: nonce, FromID: database.AccountID(from), Tip: tip, Data: []byte(data)},
+			},
+		}
+	}
+
+	size := func(tx database.BlockTx) int {
+		data, err := json.Marshal(tx)
+		if err != nil {
+			t.Fatalf("Should be able to marshal transaction: %s", err)
+		}
+		return len(data)
+	}
+
+	small := tran(0, fromPavel, 30, "")
+	big := tran(0, fromBill, 20, "a large amount of data for the transaction")
+	last := tran(0, fromEd, 10, "")
+
+	type test struct {
+		name     string
+		howMany  int
+		maxBytes int
+		best     []database.BlockTx
+	}
+
+	tt := []test{
+		{name: "no limit", howMany: 10, maxBytes: size(small) + size(big) + size(last), best: []database.BlockTx{small, big, last}},
+		{name: "count limit", howMany: 2, maxBytes: size(small) + size(big) + size(last), best: []database.BlockTx{small, big}},
+		{name: "bytes limit", howMany: 10, maxBytes: size(small) + size(big), best: []database.BlockTx{small, big}},
+		{name: "stop at too big", howMany: 10, maxBytes: size(small) + size(last), best: []database.BlockTx{small}},
+		{name: "nothing fits", howMany: 10, maxBytes: size(small) - 1, best: []database.BlockTx{}},
+	}
+
+	for _, tst := range tt {
+		f := func(t *testing.T) {
+			m := map[database.AccountID][]database.BlockTx{
+				small.FromID: {small},
+				big.FromID:   {big},
+				last.FromID:  {last},
+			}
+
+			sort, err := selector.RetrieveWithLimit(selector.StrategyTip)
+			if err != nil {
+				t.Fatalf("Test %s:\tShould be able to get sort strategy function: %s", tst.name, err)
+			}
+
+			txs := sort(m, tst.howMany, tst.maxBytes)
+			if len(txs) != len(tst.best) {
+				t.Fatalf("Test %s:\tShould get %d after sort, but got %d", tst.name, len(tst.best), len(txs))
+			}
+
+			for i, exp := range tst.best {
+				if exp.FromID != txs[i].FromID {
+					t.Fatalf("Test %s:\tShould get back the right from at %d: got %s, exp %s", tst.name, i, txs[i].FromID, exp.FromID)
+				}
+			}
+		}
+
+		t.Run(tst.name, f)
+	}
+
+	if _, err := selector.RetrieveWithLimit("unknown"); err == nil {
+		t.Fatal("Should not be able to get an unknown strategy.")
+	}
+}
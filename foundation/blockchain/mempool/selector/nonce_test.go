@@ -0,0 +1,101 @@
+package selector_test
+
+import (
+	"testing"
+
+	"github.com/andrewyang17/blockchain/foundation/blockchain/database"
+	"github.com/andrewyang17/blockchain/foundation/blockchain/mempool/selector"
+)
+
+func TestNonceGaps(t *testing.T) {
+	tran := func(nonce uint64, from string, tip uint64) database.BlockTx {
+		return database.BlockTx{
+			SignedTx: database.SignedTx{
+				Tx: database.Tx{Nonce: nonce, FromID: database.AccountID(from), Tip: tip},
+			},
+		}
+	}
+
+	type test struct {
+		name   string
+		txs    []database.BlockTx
+		nonces map[database.AccountID]uint64
+		best   []database.BlockTx
+	}
+
+	tt := []test{
+		{
+			name: "stop at gap",
+			txs: []database.BlockTx{
+				tran(5, fromPavel, 10),
+				tran(7, fromPavel, 90),
+				tran(8, fromPavel, 80),
+				tran(1, fromBill, 20),
+			},
+			nonces: map[database.AccountID]uint64{database.AccountID(fromPavel): 4},
+			best: []database.BlockTx{
+				tran(1, fromBill, 20),
+				tran(5, fromPavel, 10),
+			},
+		},
+		{
+			name: "gap at the start",
+			txs: []database.BlockTx{
+				tran(2, fromPavel, 50),
+				tran(3, fromPavel, 50),
+				tran(1, fromBill, 20),
+			},
+			nonces: map[database.AccountID]uint64{},
+			best: []database.BlockTx{
+				tran(1, fromBill, 20),
+			},
+		},
+		{
+			name: "skip used nonces",
+			txs: []database.BlockTx{
+				tran(3, fromPavel, 50),
+				tran(4, fromPavel, 40),
+				tran(5, fromPavel, 30),
+			},
+			nonces: map[database.AccountID]uint64{database.AccountID(fromPavel): 3},
+			best: []database.BlockTx{
+				tran(4, fromPavel, 40),
+				tran(5, fromPavel, 30),
+			},
+		},
+	}
+
+	for _, strategy := range []string{selector.StrategyTip, selector.StrategyTipAdvanced, selector.StrategyFIFO} {
+		for _, tst := range tt {
+			f := func(t *testing.T) {
+				m := make(map[database.AccountID][]database.BlockTx)
+				for _, tx := range tst.txs {
+					m[tx.FromID] = append(m[tx.FromID], tx)
+				}
+
+				sort, err := selector.RetrieveWithNonces(strategy)
+				if err != nil {
+					t.Fatalf("Test %s:\tShould be able to get sort strategy function: %s", tst.name, err)
+				}
+
+				txs := sort(m, tst.nonces, 10)
+				if len(txs) != len(tst.best) {
+					t.Fatalf("Test %s:\tShould get %d after sort, but got %d", tst.name, len(tst.best), len(txs))
+				}
+
+				exp := make(map[database.AccountID][]uint64)
+				for _, tx := range tst.best {
+					exp[tx.FromID] = append(exp[tx.FromID], tx.Nonce)
+				}
+				for _, tx := range txs {
+					if len(exp[tx.FromID]) == 0 || exp[tx.FromID][0] != tx.Nonce {
+						t.Fatalf("Test %s:\tShould not select %s/%d", tst.name, tx.FromID, tx.Nonce)
+					}
+					exp[tx.FromID] = exp[tx.FromID][1:]
+				}
+			}
+
+			t.Run(strategy+"/"+tst.name, f)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package selector_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/andrewyang17/blockchain/foundation/blockchain/database"
+	"github.com/andrewyang17/blockchain/foundation/blockchain/genesis"
+	"github.com/andrewyang17/blockchain/foundation/blockchain/mempool/selector"
+	"github.com/andrewyang17/blockchain/foundation/blockchain/storage/memory"
+)
+
+// nonceMap is a NonceSource backed by a map that counts the calls made.
+type nonceMap struct {
+	nonces map[database.AccountID]uint64
+	calls  int
+}
+
+func (nm *nonceMap) Nonces(accountIDs []database.AccountID) map[database.AccountID]uint64 {
+	nm.calls++
+
+	nonces := make(map[database.AccountID]uint64)
+	for _, accountID := range accountIDs {
+		if nonce, exists := nm.nonces[accountID]; exists {
+			nonces[accountID] = nonce
+		}
+	}
+
+	return nonces
+}
+
+func TestPrepareContext(t *testing.T) {
+	pavel := database.AccountID(fromPavel)
+	bill := database.AccountID(fromBill)
+	ed := database.AccountID(fromEd)
+
+	src := nonceMap{
+		nonces: map[database.AccountID]uint64{pavel: 4, bill: 7, ed: 2},
+	}
+
+	m := map[database.AccountID][]database.BlockTx{
+		pavel: {{SignedTx: database.SignedTx{Tx: database.Tx{Nonce: 5, FromID: pavel}}}},
+		bill:  {{SignedTx: database.SignedTx{Tx: database.Tx{Nonce: 8, FromID: bill}}}},
+	}
+
+	nonces := selector.PrepareContext(m, &src)
+
+	if src.calls != 1 {
+		t.Fatalf("Should load the nonces in a single call, got %d calls", src.calls)
+	}
+	if len(nonces) != 2 || nonces[pavel] != 4 || nonces[bill] != 7 {
+		t.Fatalf("Should only get the nonces for the accounts in the pool: %v", nonces)
+	}
+}
+
+// benchPool builds a pool of transactions for the accounts and a database
+// holding the accounts.
+func benchPool(b *testing.B, accounts int, txsPerAccount int) (map[database.AccountID][]database.BlockTx, *database.Database) {
+	gen := genesis.Genesis{ChainID: 1, Balances: make(map[string]uint64)}
+
+	m := make(map[database.AccountID][]database.BlockTx)
+	for i := 0; i < accounts; i++ {
+		accountID := database.AccountID(fmt.Sprintf("0x%040x", i+1))
+		gen.Balances[string(accountID)] = 1_000_000
+
+		for nonce := 1; nonce <= txsPerAccount; nonce++ {
+			tx := database.BlockTx{SignedTx: database.SignedTx{Tx: database.Tx{Nonce: uint64(nonce), FromID: accountID, Tip: uint64(nonce)}}}
+			m[accountID] = append(m[accountID], tx)
+		}
+	}
+
+	db, err := database.New(gen, memory.New(), nil)
+	if err != nil {
+		b.Fatalf("Should be able to construct the database: %s", err)
+	}
+
+	return m, db
+}
+
+// copyPool makes a copy of the pool since the selector changes the map.
+func copyPool(m map[database.AccountID][]database.BlockTx) map[database.AccountID][]database.BlockTx {
+	cp := make(map[database.AccountID][]database.BlockTx, len(m))
+	for accountID, txs := range m {
+		cp[accountID] = append([]database.BlockTx(nil), txs...)
+	}
+
+	return cp
+}
+
+func BenchmarkNoncePerTx(b *testing.B) {
+	pool, db := benchPool(b, 100, 20)
+
+	fn, err := selector.RetrieveWithNonces(selector.StrategyTip)
+	if err != nil {
+		b.Fatalf("Should be able to get sort strategy function: %s", err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m := copyPool(pool)
+
+		// Look up the nonce against the database for every transaction.
+		nonces := make(map[database.AccountID]uint64)
+		for _, txs := range m {
+			for _, tx := range txs {
+				account, err := db.Query(tx.FromID)
+				if err != nil {
+					b.Fatalf("Should be able to query the account: %s", err)
+				}
+				nonces[tx.FromID] = account.Nonce
+			}
+		}
+
+		fn(m, nonces, 500)
+	}
+}
+
+func BenchmarkNoncePrepared(b *testing.B) {
+	pool, db := benchPool(b, 100, 20)
+
+	fn, err := selector.RetrieveWithNonces(selector.StrategyTip)
+	if err != nil {
+		b.Fatalf("Should be able to get sort strategy function: %s", err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m := copyPool(pool)
+
+		fn(m, selector.PrepareContext(m, db), 500)
+	}
+}
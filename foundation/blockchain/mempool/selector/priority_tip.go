@@ -0,0 +1,17 @@
+package selector
+
+import (
+	"sort"
+
+	"github.com/andrewyang17/blockchain/foundation/blockchain/database"
+)
+
+// priorityTipSelect returns transactions with the highest priority and then
+// the best tip while respecting the nonce for each account/transaction. This
+// works like the tipSelect function but lets the node operator push system
+// transactions ahead of any tip.
+var priorityTipSelect = func(m map[database.AccountID][]database.BlockTx, howMany int) []database.BlockTx {
+	return rowSelect(m, howMany, func(row []database.BlockTx) {
+		sort.Sort(byPriorityTip(row))
+	})
+}
@@ -0,0 +1,92 @@
+package selector_test
+
+import (
+	"testing"
+
+	"github.com/andrewyang17/blockchain/foundation/blockchain/database"
+	"github.com/andrewyang17/blockchain/foundation/blockchain/mempool/selector"
+)
+
+func TestPriorityTipSort(t *testing.T) {
+	tran := func(nonce uint64, from string, tip uint64, priority uint8) database.BlockTx {
+		return database.BlockTx{
+			SignedTx: database.SignedTx{
+				Tx: database.Tx{Nonce: nonce, FromID: database.AccountID(from), Tip: tip, Priority: priority},
+			},
+		}
+	}
+
+	type test struct {
+		name    string
+		pool    []database.BlockTx
+		howMany int
+		best    []database.BlockTx
+	}
+
+	tt := []test{
+		{
+			name: "priority over tip",
+			pool: []database.BlockTx{
+				tran(0, fromPavel, 500, 0),
+				tran(0, fromBill, 1, 1),
+				tran(0, fromEd, 250, 0),
+			},
+			howMany: 2,
+			best: []database.BlockTx{
+				tran(0, fromBill, 1, 1),
+				tran(0, fromPavel, 500, 0),
+			},
+		},
+		{
+			name: "tip breaks priority tie",
+			pool: []database.BlockTx{
+				tran(0, fromPavel, 10, 2),
+				tran(0, fromBill, 20, 2),
+				tran(0, fromEd, 500, 1),
+			},
+			howMany: 1,
+			best: []database.BlockTx{
+				tran(0, fromBill, 20, 2),
+			},
+		},
+		{
+			name: "nonce order kept",
+			pool: []database.BlockTx{
+				tran(0, fromPavel, 100, 0),
+				tran(1, fromPavel, 1, 5),
+				tran(0, fromBill, 50, 0),
+			},
+			howMany: 1,
+			best: []database.BlockTx{
+				tran(0, fromPavel, 100, 0),
+			},
+		},
+	}
+
+	for _, tst := range tt {
+		f := func(t *testing.T) {
+			m := make(map[database.AccountID][]database.BlockTx)
+			for _, tx := range tst.pool {
+				m[tx.FromID] = append(m[tx.FromID], tx)
+			}
+
+			sort, err := selector.Retrieve(selector.StrategyPriorityTip)
+			if err != nil {
+				t.Fatalf("Test %s:\tShould be able to get sort strategy function: %s", tst.name, err)
+			}
+
+			txs := sort(m, tst.howMany)
+			if len(txs) != len(tst.best) {
+				t.Fatalf("Test %s:\tShould get %d after sort, but got %d", tst.name, len(tst.best), len(txs))
+			}
+
+			for i, exp := range tst.best {
+				if txs[i].FromID != exp.FromID || txs[i].Nonce != exp.Nonce {
+					t.Fatalf("Test %s:\tShould get back the right from/nonce at %d: got %s/%d, exp %s/%d", tst.name, i, txs[i].FromID, txs[i].Nonce, exp.FromID, exp.Nonce)
+				}
+			}
+		}
+
+		t.Run(tst.name, f)
+	}
+}
@@ -0,0 +1,76 @@
+package selector
+
+import (
+	"encoding/binary"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/andrewyang17/blockchain/foundation/blockchain/database"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// SeedFunc returns the seed used to shuffle the transactions.
+type SeedFunc func() int64
+
+// randomSelect is the registered random strategy which is seeded by the
+// current time.
+var randomSelect = RandomSelect(func() int64 { return time.Now().UnixNano() })
+
+// RandomSelect returns a select strategy function that shuffles the
+// transactions using a PRNG seeded by the specified seed function. The same
+// seed and transactions always produce the same selection. This keeps the
+// nonce ordering for each account/transaction.
+func RandomSelect(seed SeedFunc) Func {
+	return func(m map[database.AccountID][]database.BlockTx, howMany int) []database.BlockTx {
+
+		// Sort the transactions per account by nonce and the accounts by id,
+		// so the map ordering doesn't change the result for a given seed.
+		accounts := make([]database.AccountID, 0, len(m))
+		for key := range m {
+			if len(m[key]) > 1 {
+				sort.Sort(byNonce(m[key]))
+			}
+			accounts = append(accounts, key)
+		}
+		sort.Slice(accounts, func(i, j int) bool { return accounts[i] < accounts[j] })
+
+		// Create a slot for every transaction labeled with its account and
+		// shuffle the slots. Filling each slot with the account's next
+		// transaction by nonce randomizes the order between the accounts.
+		var slots []database.AccountID
+		for _, account := range accounts {
+			for range m[account] {
+				slots = append(slots, account)
+			}
+		}
+
+		rnd := rand.New(rand.NewSource(seed()))
+		rnd.Shuffle(len(slots), func(i, j int) { slots[i], slots[j] = slots[j], slots[i] })
+
+		if len(slots) > howMany {
+			slots = slots[:howMany]
+		}
+
+		final := []database.BlockTx{}
+		for _, account := range slots {
+			final = append(final, m[account][0])
+			m[account] = m[account][1:]
+		}
+
+		return final
+	}
+}
+
+// HashSeed returns a seed function derived from the specified block hash, so
+// the selection can be reproduced by anyone who knows the previous block.
+func HashSeed(hash string) SeedFunc {
+	return func() int64 {
+		data, err := hexutil.Decode(hash)
+		if err != nil || len(data) < 8 {
+			return 0
+		}
+
+		return int64(binary.BigEndian.Uint64(data[:8]))
+	}
+}
@@ -0,0 +1,63 @@
+package selector_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/andrewyang17/blockchain/foundation/blockchain/database"
+	"github.com/andrewyang17/blockchain/foundation/blockchain/mempool/selector"
+)
+
+func TestRandomSort(t *testing.T) {
+	const prevHash = "0x000000a1b2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d"
+
+	txs := func() map[database.AccountID][]database.BlockTx {
+		m := make(map[database.AccountID][]database.BlockTx)
+		for _, from := range []string{fromPavel, fromBill, fromEd} {
+			for _, nonce := range []uint64{3, 1, 2, 4} {
+				tx := database.BlockTx{SignedTx: database.SignedTx{Tx: database.Tx{Nonce: nonce, FromID: database.AccountID(from)}}}
+				m[tx.FromID] = append(m[tx.FromID], tx)
+			}
+		}
+		return m
+	}
+
+	order := func(txs []database.BlockTx) string {
+		var s string
+		for _, tx := range txs {
+			s += fmt.Sprintf("%s/%d ", tx.FromID, tx.Nonce)
+		}
+		return s
+	}
+
+	sort := selector.RandomSelect(selector.HashSeed(prevHash))
+
+	first := sort(txs(), 12)
+	if len(first) != 12 {
+		t.Fatalf("Should get back 12 transactions, got %d", len(first))
+	}
+
+	nonces := make(map[database.AccountID]uint64)
+	for _, tx := range first {
+		if tx.Nonce != nonces[tx.FromID]+1 {
+			t.Fatalf("Should keep the nonce order for %s: got %d after %d", tx.FromID, tx.Nonce, nonces[tx.FromID])
+		}
+		nonces[tx.FromID] = tx.Nonce
+	}
+
+	// The same seed must produce the same order no matter the map ordering.
+	for i := 0; i < 10; i++ {
+		if got := sort(txs(), 12); order(got) != order(first) {
+			t.Fatalf("Should get the same order for the same seed:\ngot %s\nexp %s", order(got), order(first))
+		}
+	}
+
+	best := sort(txs(), 5)
+	if order(best) != order(first[:5]) {
+		t.Fatalf("Should get the first 5 of the same order:\ngot %s\nexp %s", order(best), order(first[:5]))
+	}
+
+	if _, err := selector.Retrieve(selector.StrategyRandom); err != nil {
+		t.Fatalf("Should be able to get the random strategy: %s", err)
+	}
+}
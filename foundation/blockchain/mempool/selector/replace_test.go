@@ -0,0 +1,81 @@
+package selector_test
+
+import (
+	"testing"
+
+	"github.com/andrewyang17/blockchain/foundation/blockchain/database"
+	"github.com/andrewyang17/blockchain/foundation/blockchain/mempool/selector"
+)
+
+func TestReplaceByTip(t *testing.T) {
+	tran := func(nonce uint64, tip uint64, value uint64, timeStamp uint64) database.BlockTx {
+		const toID = "0xbEE6ACE826eC3DE1B6349888B9151B92522F7F76"
+
+		tx, err := sign(signPavel, database.Tx{Nonce: nonce, FromID: database.AccountID(fromPavel), ToID: toID, Value: value, Tip: tip})
+		if err != nil {
+			t.Fatalf("Should be able to sign transaction: %s", err)
+		}
+		tx.TimeStamp = timeStamp
+		return tx
+	}
+
+	lowHash, highHash := tran(1, 10, 100, 5), tran(1, 10, 200, 5)
+	if lowHash.SignedTx.Hash() > highHash.SignedTx.Hash() {
+		lowHash, highHash = highHash, lowHash
+	}
+
+	type test struct {
+		name string
+		txs  []database.BlockTx
+		exp  database.BlockTx
+	}
+
+	tt := []test{
+		{
+			name: "higher tip replaces",
+			txs:  []database.BlockTx{tran(1, 10, 100, 5), tran(1, 20, 100, 6)},
+			exp:  tran(1, 20, 100, 6),
+		},
+		{
+			name: "lower tip is discarded",
+			txs:  []database.BlockTx{tran(1, 20, 100, 6), tran(1, 10, 100, 5)},
+			exp:  tran(1, 20, 100, 6),
+		},
+		{
+			name: "equal tip keeps the first received",
+			txs:  []database.BlockTx{tran(1, 10, 200, 9), tran(1, 10, 100, 5)},
+			exp:  tran(1, 10, 100, 5),
+		},
+		{
+			name: "equal tip and time keeps the lowest hash",
+			txs:  []database.BlockTx{highHash, lowHash},
+			exp:  lowHash,
+		},
+	}
+
+	for _, tst := range tt {
+		f := func(t *testing.T) {
+			for _, strategy := range []string{selector.StrategyTip, selector.StrategyTipAdvanced, selector.StrategyFIFO, selector.StrategyRandom, selector.StrategyGasPrice} {
+				m := map[database.AccountID][]database.BlockTx{
+					database.AccountID(fromPavel): append([]database.BlockTx{}, tst.txs...),
+				}
+
+				sort, err := selector.Retrieve(strategy)
+				if err != nil {
+					t.Fatalf("Test %s:\tShould be able to get sort strategy function: %s", strategy, err)
+				}
+
+				txs := sort(m, 10)
+				if len(txs) != 1 {
+					t.Fatalf("Test %s:\tShould get back 1 transaction, got %d", strategy, len(txs))
+				}
+
+				if txs[0].SignedTx.Hash() != tst.exp.SignedTx.Hash() {
+					t.Fatalf("Test %s:\tShould keep the right transaction: got tip %d value %d, exp tip %d value %d", strategy, txs[0].Tip, txs[0].Value, tst.exp.Tip, tst.exp.Value)
+				}
+			}
+		}
+
+		t.Run(tst.name, f)
+	}
+}
@@ -2,8 +2,11 @@
 package selector
 
 import (
+	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/andrewyang17/blockchain/foundation/blockchain/database"
 )
@@ -11,25 +14,306 @@ import (
 const (
 	StrategyTip         = "tip"
 	StrategyTipAdvanced = "tip_advanced"
+	StrategyFIFO        = "fifo"
+	StrategyRandom      = "random"
+	StrategyGasPrice    = "gas_price"
+	StrategyPriorityTip = "priority_tip"
+	StrategyTipAge      = "tip_age"
+	StrategyKnapsack    = "knapsack"
 )
 
 var strategies = map[string]Func{
-	StrategyTip: tipSelect,
+	StrategyTip:         tipSelect,
+	StrategyTipAdvanced: advancedTipSelect,
+	StrategyFIFO:        fifoSelect,
+	StrategyRandom:      randomSelect,
+	StrategyGasPrice:    gasPriceSelect,
+	StrategyPriorityTip: priorityTipSelect,
+	StrategyTipAge:      newTipAgeSelect(DefaultAgeFactor),
+	StrategyKnapsack:    newKnapsackSelect(0),
 }
 
 // Func defines a function that takes a mempool of transactions grouped by
 // account and selects howMany of them in an order based on the function strategy.
 // All selector function MUST respect nonce ordering. Receiving for howMany
-// must return all the transactions in the strategies ordering.
+// must return all the transactions in the strategies ordering. A non-nil
+// empty slice is returned when there are no transactions to select or
+// howMany is zero or less, so a block can always be mined without any
+// transactions.
 type Func func(transaction map[database.AccountID][]database.BlockTx, howMany int) []database.BlockTx
 
-// Retrieve returns the specified select strategy function.
+// Retrieve returns the specified select strategy function. The function
+// drops duplicate transactions and skips any transactions that are past
+// their deadline.
 func Retrieve(strategy string) (Func, error) {
-	fn, exists := strategies[strings.ToLower(strategy)]
+	return RetrieveWithAccountCap(strategy, 0)
+}
+
+// RetrieveWithAccountCap returns the specified select strategy function that
+// considers at most maxPerAccount transactions for each account, so a single
+// account with a long nonce run can't starve the others. A maxPerAccount of
+// zero or less means there is no cap.
+func RetrieveWithAccountCap(strategy string, maxPerAccount int) (Func, error) {
+	return RetrieveWithOptions(strategy, Options{MaxPerAccount: maxPerAccount})
+}
+
+// Options provides the settings for the select strategy functions.
+type Options struct {
+
+	// MaxPerAccount is the most transactions considered for each account.
+	// Zero or less means there is no cap.
+	MaxPerAccount int
+
+	// AgeFactor is the tip a transaction gains for each second it waits in
+	// the mempool under the tip age strategy. Zero means DefaultAgeFactor.
+	AgeFactor uint64
+
+	// MaxBytes is the budget of encoded transaction data the knapsack
+	// strategy packs a block into. Zero or less means there is no budget.
+	MaxBytes int
+}
+
+// RetrieveWithOptions returns the specified select strategy function using
+// the specified options.
+func RetrieveWithOptions(strategy string, opts Options) (Func, error) {
+	strategy = strings.ToLower(strategy)
+
+	fn, exists := strategies[strategy]
 	if !exists {
 		return nil, fmt.Errorf("strategy %q does not exist", strategy)
 	}
-	return fn, nil
+
+	switch {
+	case strategy == StrategyTipAge && opts.AgeFactor > 0:
+		fn = newTipAgeSelect(opts.AgeFactor)
+
+	case strategy == StrategyKnapsack && opts.MaxBytes > 0:
+		fn = newKnapsackSelect(opts.MaxBytes)
+	}
+
+	return guardEmpty(dropDuplicates(skipExpired(capPerAccount(fn, opts.MaxPerAccount)))), nil
+}
+
+// LimitFunc defines a function that selects transactions like Func but also
+// stops once adding the next transaction would exceed maxBytes of encoded
+// transaction data.
+type LimitFunc func(transaction map[database.AccountID][]database.BlockTx, howMany int, maxBytes int) []database.BlockTx
+
+// RetrieveWithLimit returns the specified select strategy function with
+// support for a byte budget per block.
+func RetrieveWithLimit(strategy string) (LimitFunc, error) {
+	fn, err := Retrieve(strategy)
+	if err != nil {
+		return nil, err
+	}
+
+	f := func(m map[database.AccountID][]database.BlockTx, howMany int, maxBytes int) []database.BlockTx {
+
+		// Ask for every transaction so the strategy ordering is known past
+		// any transactions that are too big to fit.
+		var total int
+		for _, txs := range m {
+			total += len(txs)
+		}
+
+		final := []database.BlockTx{}
+		var size int
+		for _, tx := range fn(m, total) {
+			if len(final) == howMany {
+				break
+			}
+
+			// Stop at the first transaction that doesn't fit. Skipping it
+			// could leave a nonce gap for the account.
+			n, err := txSize(tx)
+			if err != nil || size+n > maxBytes {
+				break
+			}
+
+			size += n
+			final = append(final, tx)
+		}
+
+		return final
+	}
+
+	return f, nil
+}
+
+// txSize returns the encoded size of the transaction as stored in a block.
+func txSize(tx database.BlockTx) (int, error) {
+	data, err := json.Marshal(tx)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(data), nil
+}
+
+// NonceFunc defines a function that selects transactions like Func but only
+// from the transactions that can execute given the current nonce of each
+// account. Accounts missing from nonces have a current nonce of 0.
+type NonceFunc func(transaction map[database.AccountID][]database.BlockTx, nonces map[database.AccountID]uint64, howMany int) []database.BlockTx
+
+// RetrieveWithNonces returns the specified select strategy function that stops
+// selecting transactions for an account at the first nonce gap.
+func RetrieveWithNonces(strategy string) (NonceFunc, error) {
+	fn, err := Retrieve(strategy)
+	if err != nil {
+		return nil, err
+	}
+
+	return WithNonces(fn), nil
+}
+
+// WithNonces turns the select strategy function into a NonceFunc that stops
+// selecting transactions for an account at the first nonce gap.
+func WithNonces(fn Func) NonceFunc {
+	return func(m map[database.AccountID][]database.BlockTx, nonces map[database.AccountID]uint64, howMany int) []database.BlockTx {
+		dropNonceGaps(m, nonces)
+		return fn(m, howMany)
+	}
+}
+
+// NonceSource provides the current nonce for a set of accounts in a single
+// call. Accounts missing from the result have a current nonce of 0.
+type NonceSource interface {
+	Nonces(accountIDs []database.AccountID) map[database.AccountID]uint64
+}
+
+// PrepareContext loads the current nonce for every account in the pool from
+// the source once, so a NonceFunc can be called without going back to the
+// source for each transaction.
+func PrepareContext(m map[database.AccountID][]database.BlockTx, src NonceSource) map[database.AccountID]uint64 {
+	accountIDs := make([]database.AccountID, 0, len(m))
+	for accountID := range m {
+		accountIDs = append(accountIDs, accountID)
+	}
+
+	return src.Nonces(accountIDs)
+}
+
+// dropNonceGaps leaves each account with the run of transactions that starts
+// with the account's next nonce and has no gaps. A transaction after a gap
+// can't execute until the missing nonce arrives, and a transaction with a
+// nonce that has already been used can never execute.
+func dropNonceGaps(m map[database.AccountID][]database.BlockTx, nonces map[database.AccountID]uint64) {
+	for key, txs := range m {
+		sort.Sort(byNonce(txs))
+
+		next := nonces[key] + 1
+
+		var run []database.BlockTx
+		for _, tx := range txs {
+			if tx.Nonce < next {
+				continue
+			}
+			if tx.Nonce != next {
+				break
+			}
+
+			run = append(run, tx)
+			next++
+		}
+
+		m[key] = run
+	}
+}
+
+// guardEmpty wraps the select strategy function to return an empty slice
+// without calling the strategy when there is nothing to select.
+func guardEmpty(fn Func) Func {
+	return func(m map[database.AccountID][]database.BlockTx, howMany int) []database.BlockTx {
+		if howMany <= 0 || len(m) == 0 {
+			return []database.BlockTx{}
+		}
+
+		return fn(m, howMany)
+	}
+}
+
+// capPerAccount wraps the select strategy function to truncate the nonce
+// ordered transactions for each account to the first maxPerAccount. Only the
+// end of the run is dropped, so the nonce order is never broken.
+func capPerAccount(fn Func, maxPerAccount int) Func {
+	if maxPerAccount <= 0 {
+		return fn
+	}
+
+	return func(m map[database.AccountID][]database.BlockTx, howMany int) []database.BlockTx {
+		for key, txs := range m {
+			if len(txs) > maxPerAccount {
+				sort.Sort(byNonce(txs))
+				m[key] = txs[:maxPerAccount]
+			}
+		}
+
+		return fn(m, howMany)
+	}
+}
+
+// skipExpired wraps the select strategy function to remove the transactions
+// that are past their deadline so they never make it into a block.
+func skipExpired(fn Func) Func {
+	return func(m map[database.AccountID][]database.BlockTx, howMany int) []database.BlockTx {
+		now := time.Now()
+
+		for key, txs := range m {
+			var live []database.BlockTx
+			for _, tx := range txs {
+				if !tx.IsExpired(now) {
+					live = append(live, tx)
+				}
+			}
+			m[key] = live
+		}
+
+		return fn(m, howMany)
+	}
+}
+
+// dropDuplicates wraps the select strategy function to leave each account
+// with a single transaction per nonce, so a transaction received twice can't
+// be selected twice and a transaction resubmitted with a higher tip replaces
+// the original.
+func dropDuplicates(fn Func) Func {
+	return func(m map[database.AccountID][]database.BlockTx, howMany int) []database.BlockTx {
+		for key, txs := range m {
+			var unique []database.BlockTx
+			index := make(map[uint64]int)
+			for _, tx := range txs {
+				i, exists := index[tx.Nonce]
+				switch {
+				case !exists:
+					index[tx.Nonce] = len(unique)
+					unique = append(unique, tx)
+
+				case replaces(tx, unique[i]):
+					unique[i] = tx
+				}
+			}
+			m[key] = unique
+		}
+
+		return fn(m, howMany)
+	}
+}
+
+// replaces reports if the transaction should replace the existing transaction
+// with the same nonce. The higher tip wins. On an equal tip the transaction
+// received first wins, and then the lowest hash, so every node picks the same
+// transaction no matter what order they were received in.
+func replaces(tx database.BlockTx, existing database.BlockTx) bool {
+	switch {
+	case tx.Tip != existing.Tip:
+		return tx.Tip > existing.Tip
+
+	case tx.TimeStamp != existing.TimeStamp:
+		return tx.TimeStamp < existing.TimeStamp
+
+	default:
+		return tx.SignedTx.Hash() < existing.SignedTx.Hash()
+	}
 }
 
 // =============================================================================
@@ -67,6 +351,47 @@ func (b byTip) Less(i, j int) bool {
 }
 
 func (b byTip) Swap(i, j int) {
-	//TODO implement me
-	panic("implement me")
+	b[i], b[j] = b[j], b[i]
+}
+
+// =============================================================================
+
+// byGasPrice provides sorting support by the transaction gas price value.
+type byGasPrice []database.BlockTx
+
+func (b byGasPrice) Len() int {
+	return len(b)
+}
+
+// Less helps to sort the list by gas price in decending order to pick the
+// transactions that pay the most for each unit of gas.
+func (b byGasPrice) Less(i, j int) bool {
+	return b[i].GasPrice > b[j].GasPrice
+}
+
+func (b byGasPrice) Swap(i, j int) {
+	b[i], b[j] = b[j], b[i]
+}
+
+// =============================================================================
+
+// byPriorityTip provides sorting support by the transaction priority and
+// then the tip value.
+type byPriorityTip []database.BlockTx
+
+func (b byPriorityTip) Len() int {
+	return len(b)
+}
+
+// Less helps to sort the list by priority in decending order and then by tip
+// in decending order, so a system transaction is picked over any tip.
+func (b byPriorityTip) Less(i, j int) bool {
+	if b[i].Priority != b[j].Priority {
+		return b[i].Priority > b[j].Priority
+	}
+	return b[i].Tip > b[j].Tip
+}
+
+func (b byPriorityTip) Swap(i, j int) {
+	b[i], b[j] = b[j], b[i]
 }
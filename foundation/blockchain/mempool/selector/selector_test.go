@@ -0,0 +1,28 @@
+package selector
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/andrewyang17/blockchain/foundation/blockchain/database"
+)
+
+func TestByTipSort(t *testing.T) {
+	txs := []database.BlockTx{
+		{SignedTx: database.SignedTx{Tx: database.Tx{Nonce: 1, Tip: 25}}},
+		{SignedTx: database.SignedTx{Tx: database.Tx{Nonce: 2, Tip: 250}}},
+		{SignedTx: database.SignedTx{Tx: database.Tx{Nonce: 3, Tip: 0}}},
+		{SignedTx: database.SignedTx{Tx: database.Tx{Nonce: 4, Tip: 75}}},
+		{SignedTx: database.SignedTx{Tx: database.Tx{Nonce: 5, Tip: 75}}},
+		{SignedTx: database.SignedTx{Tx: database.Tx{Nonce: 6, Tip: 150}}},
+	}
+
+	sort.Sort(byTip(txs))
+
+	exp := []uint64{250, 150, 75, 75, 25, 0}
+	for i, tx := range txs {
+		if tx.Tip != exp[i] {
+			t.Fatalf("Should get back the tips in descending order at %d: got %d, exp %d", i, tx.Tip, exp[i])
+		}
+	}
+}
@@ -9,6 +9,16 @@ import (
 // tipSelect returns transactions with the best tip while respecting the nonce
 // for each account/transaction.
 var tipSelect = func(m map[database.AccountID][]database.BlockTx, howMany int) []database.BlockTx {
+	return rowSelect(m, howMany, func(row []database.BlockTx) {
+		sort.Sort(byTip(row))
+	})
+}
+
+// rowSelect returns transactions by taking rows of one transaction per
+// account, in nonce order, and sorting each row with the sortRow function.
+// This is the shared algorithm for the strategies that only differ in how
+// the transactions in a row are ranked.
+func rowSelect(m map[database.AccountID][]database.BlockTx, howMany int, sortRow func(row []database.BlockTx)) []database.BlockTx {
 
 	/*
 		Bill: {Nonce: 2, To: "0x6Fe6CF3c8fF57c58d24BfC869668F48BCbDb3BD9", Tip: 250},
@@ -63,16 +73,17 @@ var tipSelect = func(m map[database.AccountID][]database.BlockTx, howMany int) [
 		1: Edua: {Nonce: 2, To: "0xa988b1866EaBF72B4c53b592c97aAD8e4b9bDCC0", Tip: 75},
 	*/
 
-	// Sort each row by tip unless we will take all transactions from that row
-	// anyway. Then try to select the number of requested transactions. Keep
-	// pulling transactions from each row until the amount of fulfilled or
-	// there are no more transactions.
+	// Sort each row, even when all transactions from that row will be taken,
+	// so callers like RetrieveWithLimit that cut the selection short get the
+	// best transactions first. Then try to select the number of requested
+	// transactions. Keep pulling transactions from each row until the amount
+	// of fulfilled or there are no more transactions.
 	final := []database.BlockTx{}
 	for _, row := range rows {
 		need := howMany - len(final)
 
+		sortRow(row)
 		if len(row) > need {
-			sort.Sort(byTip(row))
 			final = append(final, row[:need]...)
 			break
 		}
@@ -0,0 +1,62 @@
+package selector
+
+import (
+	"sort"
+
+	"github.com/andrewyang17/blockchain/foundation/blockchain/database"
+)
+
+// advancedTipSelect returns transactions by taking turns between the accounts
+// so a single account can't monopolize a block. The turn order is set once by
+// the tip of each account's first eligible transaction and kept for every
+// turn, so an account can't jump the queue by putting high tips on its later
+// transactions. This keeps the nonce ordering for each account/transaction.
+var advancedTipSelect = func(m map[database.AccountID][]database.BlockTx, howMany int) []database.BlockTx {
+
+	// Sort the transactions per account by nonce.
+	for key := range m {
+		if len(m[key]) > 1 {
+			sort.Sort(byNonce(m[key]))
+		}
+	}
+
+	// Order the accounts by the tip of their first eligible transaction. Ties
+	// are broken by account id so the selection is deterministic.
+	var order []database.AccountID
+	for key := range m {
+		if len(m[key]) > 0 {
+			order = append(order, key)
+		}
+	}
+	sort.Slice(order, func(i, j int) bool {
+		ti, tj := m[order[i]][0].Tip, m[order[j]][0].Tip
+		if ti != tj {
+			return ti > tj
+		}
+		return order[i] < order[j]
+	})
+
+	// Each turn takes the next transaction by nonce from every account that
+	// still has transactions, in the turn order. Keep taking turns until the
+	// number of requested transactions is fulfilled or there are no more
+	// transactions.
+	final := []database.BlockTx{}
+	for len(final) < howMany {
+		var took bool
+		for _, key := range order {
+			if len(final) == howMany {
+				break
+			}
+			if len(m[key]) > 0 {
+				final = append(final, m[key][0])
+				m[key] = m[key][1:]
+				took = true
+			}
+		}
+		if !took {
+			break
+		}
+	}
+
+	return final
+}
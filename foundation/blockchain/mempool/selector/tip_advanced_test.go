@@ -0,0 +1,199 @@
+package selector_test
+
+import (
+	"testing"
+
+	"github.com/andrewyang17/blockchain/foundation/blockchain/database"
+	"github.com/andrewyang17/blockchain/foundation/blockchain/mempool/selector"
+)
+
+func TestAdvancedTipSort(t *testing.T) {
+	tran := func(nonce uint64, hexKey string, tip uint64) database.BlockTx {
+		const toID = "0xbEE6ACE826eC3DE1B6349888B9151B92522F7F76"
+
+		fromIDs := map[string]string{
+			signPavel: fromPavel,
+			signBill:  fromBill,
+			signEd:    fromEd,
+		}
+
+		tx, err := sign(hexKey, database.Tx{Nonce: nonce, FromID: database.AccountID(fromIDs[hexKey]), ToID: toID, Tip: tip})
+		if err != nil {
+			t.Fatalf("Should be able to sign transaction: %s", tx)
+		}
+		return tx
+	}
+
+	type test struct {
+		name    string
+		txs     []database.BlockTx
+		howMany int
+		best    []database.BlockTx
+	}
+
+	tt := []test{
+		{
+			name: "whale can't monopolize",
+			txs: []database.BlockTx{
+				tran(2, signPavel, 500),
+				tran(0, signPavel, 300),
+				tran(1, signPavel, 400),
+				tran(3, signPavel, 600),
+
+				tran(0, signBill, 5),
+				tran(1, signBill, 10),
+
+				tran(0, signEd, 1),
+			},
+			howMany: 3,
+			best: []database.BlockTx{
+				tran(0, signPavel, 300),
+				tran(0, signBill, 5),
+				tran(0, signEd, 1),
+			},
+		},
+		{
+			name: "turn order is kept",
+			txs: []database.BlockTx{
+				tran(0, signPavel, 30),
+				tran(1, signPavel, 5),
+
+				tran(0, signBill, 20),
+				tran(1, signBill, 50),
+
+				tran(0, signEd, 10),
+				tran(1, signEd, 25),
+			},
+			howMany: 5,
+			best: []database.BlockTx{
+				tran(0, signPavel, 30),
+				tran(0, signBill, 20),
+				tran(0, signEd, 10),
+				tran(1, signPavel, 5),
+				tran(1, signBill, 50),
+			},
+		},
+		{
+			name: "take all",
+			txs: []database.BlockTx{
+				tran(1, signPavel, 75),
+				tran(0, signPavel, 25),
+				tran(0, signBill, 10),
+				tran(0, signEd, 5),
+			},
+			howMany: 15,
+			best: []database.BlockTx{
+				tran(0, signPavel, 25),
+				tran(0, signBill, 10),
+				tran(0, signEd, 5),
+				tran(1, signPavel, 75),
+			},
+		},
+	}
+
+	for _, tst := range tt {
+		f := func(t *testing.T) {
+			m := make(map[database.AccountID][]database.BlockTx)
+			for _, tx := range tst.txs {
+				m[tx.FromID] = append(m[tx.FromID], tx)
+			}
+
+			sort, err := selector.Retrieve(selector.StrategyTipAdvanced)
+			if err != nil {
+				t.Fatalf("Test %s:\tShould be able to get sort strategy function: %s", tst.name, err)
+			}
+
+			txs := sort(m, tst.howMany)
+			if len(txs) != len(tst.best) {
+				t.Fatalf("Test %s:\tShould get %d after sort, but got %d", tst.name, len(tst.best), len(txs))
+			}
+
+			for i, exp := range tst.best {
+				if exp.Nonce != txs[i].Nonce || exp.FromID != txs[i].FromID {
+					t.Fatalf("Test %s:\tShould get back the right from/nonce at %d: got %s/%d, exp %s/%d", tst.name, i, txs[i].FromID, txs[i].Nonce, exp.FromID, exp.Nonce)
+				}
+			}
+
+			nonces := make(map[database.AccountID]uint64)
+			for _, tx := range txs {
+				if last, exists := nonces[tx.FromID]; exists && tx.Nonce != last+1 {
+					t.Fatalf("Test %s:\tShould keep the nonce order for %s: got %d after %d", tst.name, tx.FromID, tx.Nonce, last)
+				}
+				nonces[tx.FromID] = tx.Nonce
+			}
+		}
+
+		t.Run(tst.name, f)
+	}
+}
+
+func TestAdvancedTipFairness(t *testing.T) {
+	tran := func(nonce uint64, hexKey string, tip uint64) database.BlockTx {
+		const toID = "0xbEE6ACE826eC3DE1B6349888B9151B92522F7F76"
+
+		fromIDs := map[string]string{
+			signPavel: fromPavel,
+			signBill:  fromBill,
+			signEd:    fromEd,
+		}
+
+		tx, err := sign(hexKey, database.Tx{Nonce: nonce, FromID: database.AccountID(fromIDs[hexKey]), ToID: toID, Tip: tip})
+		if err != nil {
+			t.Fatalf("Should be able to sign transaction: %s", tx)
+		}
+		return tx
+	}
+
+	// The whale pays a low tip on its first transaction and high tips on the
+	// rest. The tip strategy gives the whale the open slot on the second turn,
+	// the advanced strategy keeps the turn order and gives it to Bill.
+	txs := []database.BlockTx{
+		tran(0, signPavel, 1),
+		tran(1, signPavel, 600),
+		tran(2, signPavel, 700),
+		tran(3, signPavel, 800),
+
+		tran(0, signBill, 50),
+		tran(1, signBill, 5),
+
+		tran(0, signEd, 40),
+	}
+	const howMany = 4
+
+	selections := make(map[string][]database.BlockTx)
+	for _, strategy := range []string{selector.StrategyTip, selector.StrategyTipAdvanced} {
+		m := make(map[database.AccountID][]database.BlockTx)
+		for _, tx := range txs {
+			m[tx.FromID] = append(m[tx.FromID], tx)
+		}
+
+		fn, err := selector.Retrieve(strategy)
+		if err != nil {
+			t.Fatalf("Test %s:\tShould be able to get sort strategy function: %s", strategy, err)
+		}
+		selections[strategy] = fn(m, howMany)
+	}
+
+	count := func(txs []database.BlockTx, id string) int {
+		var n int
+		for _, tx := range txs {
+			if tx.FromID == database.AccountID(id) {
+				n++
+			}
+		}
+		return n
+	}
+
+	tip := selections[selector.StrategyTip]
+	if n := count(tip, fromPavel); n != 2 {
+		t.Fatalf("Test %s:\tShould give the whale 2 transactions, got %d", selector.StrategyTip, n)
+	}
+
+	adv := selections[selector.StrategyTipAdvanced]
+	if n := count(adv, fromPavel); n != 1 {
+		t.Fatalf("Test %s:\tShould give the whale 1 transaction, got %d", selector.StrategyTipAdvanced, n)
+	}
+	if n := count(adv, fromBill); n != 2 {
+		t.Fatalf("Test %s:\tShould give Bill 2 transactions, got %d", selector.StrategyTipAdvanced, n)
+	}
+}
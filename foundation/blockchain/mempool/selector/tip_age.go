@@ -0,0 +1,90 @@
+package selector
+
+import (
+	"math/bits"
+	"sort"
+	"time"
+
+	"github.com/andrewyang17/blockchain/foundation/blockchain/database"
+)
+
+// DefaultAgeFactor is the tip a transaction gains for each second it waits
+// in the mempool when no age factor is configured.
+const DefaultAgeFactor = 1
+
+// newTipAgeSelect returns a strategy that selects transactions by a score of
+// the tip plus ageFactor for every second the transaction has been in the
+// mempool, while respecting the nonce for each account/transaction. High tips
+// are rewarded, but an old transaction with a low tip eventually outscores
+// the new ones so it can't starve.
+func newTipAgeSelect(ageFactor uint64) Func {
+	return func(m map[database.AccountID][]database.BlockTx, howMany int) []database.BlockTx {
+		now := uint64(time.Now().UTC().UnixMilli())
+
+		// Sort the transactions per account by nonce.
+		for key := range m {
+			if len(m[key]) > 1 {
+				sort.Sort(byNonce(m[key]))
+			}
+		}
+
+		// Only the next transaction by nonce for each account can be picked.
+		// Keep picking the best scoring of those until the amount is
+		// fulfilled or there are no more transactions.
+		final := []database.BlockTx{}
+		for len(final) < howMany {
+			var best database.AccountID
+			var bestScore uint64
+			var found bool
+
+			for key, txs := range m {
+				if len(txs) == 0 {
+					continue
+				}
+
+				score := tipAgeScore(txs[0], ageFactor, now)
+				if !found || score > bestScore || (score == bestScore && tipAgeBefore(txs[0], m[best][0])) {
+					best, bestScore, found = key, score, true
+				}
+			}
+			if !found {
+				break
+			}
+
+			final = append(final, m[best][0])
+			m[best] = m[best][1:]
+		}
+
+		return final
+	}
+}
+
+// tipAgeScore returns the tip plus ageFactor for every whole second since
+// the transaction was received. The score saturates instead of overflowing.
+func tipAgeScore(tx database.BlockTx, ageFactor uint64, now uint64) uint64 {
+	var age uint64
+	if now > tx.TimeStamp {
+		age = (now - tx.TimeStamp) / 1000
+	}
+
+	hi, boost := bits.Mul64(ageFactor, age)
+	if hi != 0 {
+		return ^uint64(0)
+	}
+
+	score, carry := bits.Add64(tx.Tip, boost, 0)
+	if carry != 0 {
+		return ^uint64(0)
+	}
+
+	return score
+}
+
+// tipAgeBefore breaks a tie in score by picking the older transaction and
+// then the lower account so the selection doesn't depend on map order.
+func tipAgeBefore(a, b database.BlockTx) bool {
+	if a.TimeStamp != b.TimeStamp {
+		return a.TimeStamp < b.TimeStamp
+	}
+	return a.FromID < b.FromID
+}
@@ -0,0 +1,114 @@
+package selector_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/andrewyang17/blockchain/foundation/blockchain/database"
+	"github.com/andrewyang17/blockchain/foundation/blockchain/mempool/selector"
+)
+
+func TestTipAgeSort(t *testing.T) {
+	now := time.Now().UTC()
+
+	tran := func(nonce uint64, from string, tip uint64, age time.Duration) database.BlockTx {
+		return database.BlockTx{
+			SignedTx: database.SignedTx{
+				Tx: database.Tx{Nonce: nonce, FromID: database.AccountID(from), Tip: tip},
+			},
+			TimeStamp: uint64(now.Add(-age).UnixMilli()),
+		}
+	}
+
+	type test struct {
+		name      string
+		ageFactor uint64
+		pool      []database.BlockTx
+		howMany   int
+		best      []database.BlockTx
+	}
+
+	tt := []test{
+		{
+			name:      "tip wins when fresh",
+			ageFactor: 1,
+			pool: []database.BlockTx{
+				tran(0, fromPavel, 0, time.Second),
+				tran(0, fromBill, 50, 0),
+				tran(0, fromEd, 100, 0),
+			},
+			howMany: 2,
+			best: []database.BlockTx{
+				tran(0, fromEd, 100, 0),
+				tran(0, fromBill, 50, 0),
+			},
+		},
+		{
+			name:      "old zero tip outscores fresh low tips",
+			ageFactor: 1,
+			pool: []database.BlockTx{
+				tran(0, fromPavel, 0, 5*time.Minute),
+				tran(0, fromBill, 50, 0),
+				tran(0, fromEd, 100, 0),
+			},
+			howMany: 1,
+			best: []database.BlockTx{
+				tran(0, fromPavel, 0, 5*time.Minute),
+			},
+		},
+		{
+			name:      "age factor speeds up aging",
+			ageFactor: 10,
+			pool: []database.BlockTx{
+				tran(0, fromPavel, 0, 20*time.Second),
+				tran(0, fromEd, 100, 0),
+			},
+			howMany: 1,
+			best: []database.BlockTx{
+				tran(0, fromPavel, 0, 20*time.Second),
+			},
+		},
+		{
+			name:      "nonce order kept",
+			ageFactor: 1,
+			pool: []database.BlockTx{
+				tran(0, fromPavel, 1, 0),
+				tran(1, fromPavel, 0, time.Hour),
+				tran(0, fromBill, 50, 0),
+			},
+			howMany: 3,
+			best: []database.BlockTx{
+				tran(0, fromBill, 50, 0),
+				tran(0, fromPavel, 1, 0),
+				tran(1, fromPavel, 0, time.Hour),
+			},
+		},
+	}
+
+	for _, tst := range tt {
+		f := func(t *testing.T) {
+			m := make(map[database.AccountID][]database.BlockTx)
+			for _, tx := range tst.pool {
+				m[tx.FromID] = append(m[tx.FromID], tx)
+			}
+
+			sort, err := selector.RetrieveWithOptions(selector.StrategyTipAge, selector.Options{AgeFactor: tst.ageFactor})
+			if err != nil {
+				t.Fatalf("Test %s:\tShould be able to get sort strategy function: %s", tst.name, err)
+			}
+
+			txs := sort(m, tst.howMany)
+			if len(txs) != len(tst.best) {
+				t.Fatalf("Test %s:\tShould get %d after sort, but got %d", tst.name, len(tst.best), len(txs))
+			}
+
+			for i, exp := range tst.best {
+				if txs[i].FromID != exp.FromID || txs[i].Nonce != exp.Nonce {
+					t.Fatalf("Test %s:\tShould get back the right from/nonce at %d: got %s/%d, exp %s/%d", tst.name, i, txs[i].FromID, txs[i].Nonce, exp.FromID, exp.Nonce)
+				}
+			}
+		}
+
+		t.Run(tst.name, f)
+	}
+}
@@ -37,7 +37,13 @@ func TestTipSort(t *testing.T) {
 	tran := func(nonce uint64, hexKey string, tip uint64, ts time.Time) database.BlockTx {
 		const toID = "0xbEE6ACE826eC3DE1B6349888B9151B92522F7F76"
 
-		tx, err := sign(hexKey, database.Tx{Nonce: nonce, ToID: toID, Tip: tip})
+		fromIDs := map[string]string{
+			signPavel: fromPavel,
+			signBill:  fromBill,
+			signEd:    fromEd,
+		}
+
+		tx, err := sign(hexKey, database.Tx{Nonce: nonce, FromID: database.AccountID(fromIDs[hexKey]), ToID: toID, Tip: tip})
 		if err != nil {
 			t.Fatalf("hould be able to sign transaction: %s", tx)
 		}
@@ -183,3 +189,40 @@ func TestTipSort(t *testing.T) {
 		t.Run(tst.name, f)
 	}
 }
+
+func TestSkipExpired(t *testing.T) {
+	expired := uint64(time.Now().Add(-time.Hour).Unix())
+	live := uint64(time.Now().Add(time.Hour).Unix())
+
+	m := map[database.AccountID][]database.BlockTx{
+		database.AccountID(fromPavel): {
+			{SignedTx: database.SignedTx{Tx: database.Tx{FromID: database.AccountID(fromPavel), Nonce: 1, Tip: 10}}},
+			{SignedTx: database.SignedTx{Tx: database.Tx{FromID: database.AccountID(fromPavel), Nonce: 2, Tip: 10, Deadline: expired}}},
+		},
+		database.AccountID(fromBill): {
+			{SignedTx: database.SignedTx{Tx: database.Tx{FromID: database.AccountID(fromBill), Nonce: 1, Tip: 10, Deadline: live}}},
+		},
+	}
+
+	for _, strategy := range []string{selector.StrategyTip, selector.StrategyTipAdvanced} {
+		cpy := make(map[database.AccountID][]database.BlockTx)
+		for key, txs := range m {
+			cpy[key] = append([]database.BlockTx{}, txs...)
+		}
+
+		sort, err := selector.Retrieve(strategy)
+		if err != nil {
+			t.Fatalf("Test %s:\tShould be able to get sort strategy function: %s", strategy, err)
+		}
+
+		txs := sort(cpy, 10)
+		if len(txs) != 2 {
+			t.Fatalf("Test %s:\tShould get back 2 transactions, got %d", strategy, len(txs))
+		}
+		for _, tx := range txs {
+			if tx.Deadline == expired {
+				t.Fatalf("Test %s:\tShould not select an expired transaction.", strategy)
+			}
+		}
+	}
+}
@@ -0,0 +1,86 @@
+// Package metrics maintains the Prometheus metrics for the blockchain. The
+// metrics are registered with the default Prometheus registry so they can be
+// served by the standard promhttp handler.
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// namespace is the prefix applied to all the blockchain metrics.
+const namespace = "blockchain"
+
+// The Prometheus registry is a singleton, so the metrics are held in package
+// level variables just like the expvar based web metrics.
+var (
+	blocksWritten = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "blocks_written_total",
+		Help:      "The number of blocks written to the blockchain.",
+	})
+
+	blockWriteSeconds = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "block_write_seconds",
+		Help:      "The time it takes to write a block to storage.",
+		Buckets:   prometheus.DefBuckets,
+	})
+
+	chainHeight = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "chain_height",
+		Help:      "The number of the latest block in the blockchain.",
+	})
+
+	mempoolTxs = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "mempool_transactions",
+		Help:      "The number of transactions in the mempool.",
+	})
+
+	txsAccepted = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transactions_accepted_total",
+		Help:      "The number of submitted transactions accepted into the mempool.",
+	})
+
+	txsRejected = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transactions_rejected_total",
+		Help:      "The number of submitted transactions rejected by the node.",
+	})
+)
+
+// Add more of these functions when a metric needs to be collected in
+// different parts of the codebase. This will keep this package the
+// central authority for metrics and metrics won't get lost.
+
+// AddBlockWritten increments the blocks written metric by 1 and records how
+// long the write took.
+func AddBlockWritten(took time.Duration) {
+	blocksWritten.Inc()
+	blockWriteSeconds.Observe(took.Seconds())
+}
+
+// SetChainHeight sets the chain height metric to the specified block number.
+func SetChainHeight(number uint64) {
+	chainHeight.Set(float64(number))
+}
+
+// SetMempool sets the mempool metric to the specified number of transactions.
+func SetMempool(count int) {
+	mempoolTxs.Set(float64(count))
+}
+
+// AddTxAccepted increments the accepted transactions metric by 1.
+func AddTxAccepted() {
+	txsAccepted.Inc()
+}
+
+// AddTxRejected increments the rejected transactions metric by 1.
+func AddTxRejected() {
+	txsRejected.Inc()
+}
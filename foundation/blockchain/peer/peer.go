@@ -2,7 +2,13 @@
 // of know peers and their status.
 package peer
 
-import "sync"
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+)
 
 // Peer represents information about a Node in the network.
 type Peer struct {
@@ -21,6 +27,25 @@ func (p Peer) Match(host string) bool {
 	return p.Host == host
 }
 
+// Validate checks the host is a host:port address with a valid port.
+func (p Peer) Validate() error {
+	host, port, err := net.SplitHostPort(p.Host)
+	if err != nil {
+		return fmt.Errorf("invalid peer address %q: %w", p.Host, err)
+	}
+
+	if host == "" {
+		return fmt.Errorf("invalid peer address %q: missing host", p.Host)
+	}
+
+	n, err := strconv.ParseUint(port, 10, 16)
+	if err != nil || n == 0 {
+		return fmt.Errorf("invalid peer address %q: %w", p.Host, errors.New("invalid port"))
+	}
+
+	return nil
+}
+
 // =============================================================================
 
 // PeerStatus represents information about the status
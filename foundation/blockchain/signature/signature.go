@@ -16,10 +16,25 @@ import (
 // ZeroHash represents a hash code of zeros.
 const ZeroHash string = "0x0000000000000000000000000000000000000000000000000000000000000000"
 
-// ardanID is an arbitrary number for signing messages. This will make it
-// clear that the signature comes from the Ardan blockchain.
-// Ethereum and Bitcoin do this as well, but they use the value of 27.
-const ardanID = 29
+// Set of offsets added to the recovery id of a signature. The Ardan offset is
+// an arbitrary number that makes it clear the signature comes from the Ardan
+// blockchain. Ethereum and Bitcoin do this as well, but they use the value of
+// 27, which is what standard Ethereum tooling expects.
+const (
+	ArdanRecoveryOffset    = 29
+	EthereumRecoveryOffset = 27
+)
+
+// RecoveryOffset is added to the recovery id of 0 or 1 when signing and taken
+// away when verifying and recovering, so the v value is either the offset or
+// the offset plus one. Every node and wallet on a chain must use the same
+// offset. It can be changed when the program starts, before any data is
+// signed or verified.
+var RecoveryOffset uint64 = ArdanRecoveryOffset
+
+// halfN is half the order of the secp256k1 curve. A signature with an s value
+// above this has a malleated twin with a low s value that is just as valid.
+var halfN = new(big.Int).Rsh(crypto.S256().Params().N, 1)
 
 // =============================================================================
 
@@ -70,16 +85,24 @@ func Sign(value any, privateKey *ecdsa.PrivateKey) (v, r, s *big.Int, err error)
 
 }
 
+// VerifySignature checks the signature values are valid. Like Ethereum since
+// EIP-2, only the low s value form of a signature is accepted so there is a
+// single valid signature for the data.
 func VerifySignature(v, r, s *big.Int) error {
 
 	// Check the recovery id is either 0 or 1.
-	uintV := v.Uint64() - ardanID
+	uintV := v.Uint64() - RecoveryOffset
 	if uintV != 0 && uintV != 1 {
 		return errors.New("invalid recovery id")
 	}
 
+	// Check the s value is in the lower half of the curve order.
+	if s.Cmp(halfN) > 0 {
+		return errors.New("invalid signature, s value in the upper half of the curve order")
+	}
+
 	// Check the signature values are valid.
-	if !crypto.ValidateSignatureValues(byte(uintV), r, s, false) {
+	if !crypto.ValidateSignatureValues(byte(uintV), r, s, true) {
 		return errors.New("invalid signature values")
 	}
 
@@ -89,23 +112,30 @@ func VerifySignature(v, r, s *big.Int) error {
 // FromAddress extracts the address for the account that signed the data.
 func FromAddress(value any, v, r, s *big.Int) (string, error) {
 
+	// Capture the public key associated with this data and signature.
+	publicKey, err := FromPubKey(value, v, r, s)
+	if err != nil {
+		return "", err
+	}
+
+	// Extract the account address from the public key.
+	return crypto.PubkeyToAddress(*publicKey).String(), nil
+}
+
+// FromPubKey extracts the public key for the account that signed the data.
+func FromPubKey(value any, v, r, s *big.Int) (*ecdsa.PublicKey, error) {
+
 	// Prepare the data for public key extraction.
 	data, err := stamp(value)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	// Convert the [R|S|V] format into the original 65 bytes.
 	sig := ToSignatureBytes(v, r, s)
 
 	// Capture the public key associated with this data and signature.
-	publicKey, err := crypto.SigToPub(data, sig)
-	if err != nil {
-		return "", err
-	}
-
-	// Extract the account address from the public key.
-	return crypto.PubkeyToAddress(*publicKey).String(), nil
+	return crypto.SigToPub(data, sig)
 }
 
 // SignatureString returns the signature as a string.
@@ -145,13 +175,13 @@ func stamp(value any) ([]byte, error) {
 func toSignatureValues(sig []byte) (v, r, s *big.Int) {
 	r = new(big.Int).SetBytes(sig[:32])
 	s = new(big.Int).SetBytes(sig[32:64])
-	v = new(big.Int).SetBytes([]byte{sig[64] + ardanID})
+	v = new(big.Int).SetUint64(uint64(sig[64]) + RecoveryOffset)
 
 	return v, r, s
 }
 
 // ToSignatureBytes converts the r, s, v values into a slice of bytes
-// with the removal of the ardanID.
+// with the removal of the recovery offset.
 func ToSignatureBytes(v, r, s *big.Int) []byte {
 	sig := make([]byte, crypto.SignatureLength)
 
@@ -163,13 +193,13 @@ func ToSignatureBytes(v, r, s *big.Int) []byte {
 	s.FillBytes(sBytes)
 	copy(sig[32:], sBytes)
 
-	sig[64] = byte(v.Uint64() - ardanID)
+	sig[64] = byte(v.Uint64() - RecoveryOffset)
 
 	return sig
 }
 
 // ToSignatureBytesWithArdanID converts the r, s, v values into a slice of bytes
-// keeping the Ardan id.
+// keeping the recovery offset.
 func ToSignatureBytesWithArdanID(v, r, s *big.Int) []byte {
 	sig := ToSignatureBytes(v, r, s)
 	sig[64] = byte(v.Uint64())
@@ -0,0 +1,80 @@
+package signature_test
+
+import (
+	"testing"
+
+	"github.com/andrewyang17/blockchain/foundation/blockchain/signature"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func Test_RecoveryOffset(t *testing.T) {
+	const (
+		keyKennedy = "9f332e3700d8fc2446eaf6d15034cf96e0c2745e40353deef032a5dbf1dfed93"
+		idKennedy  = "0xF01813E4B85e178A83e29B8E7bF26BD830a25f32"
+	)
+
+	type table struct {
+		name   string
+		offset uint64
+		other  uint64
+	}
+
+	tt := []table{
+		{name: "ardan", offset: signature.ArdanRecoveryOffset, other: signature.EthereumRecoveryOffset},
+		{name: "ethereum", offset: signature.EthereumRecoveryOffset, other: signature.ArdanRecoveryOffset},
+	}
+
+	value := struct {
+		Nonce uint64 `json:"nonce"`
+		Memo  string `json:"memo"`
+	}{Nonce: 1, Memo: "recovery offset"}
+
+	pk, err := crypto.HexToECDSA(keyKennedy)
+	if err != nil {
+		t.Fatalf("Should be able to load the private key: %s", err)
+	}
+
+	defer func(offset uint64) { signature.RecoveryOffset = offset }(signature.RecoveryOffset)
+
+	for _, tst := range tt {
+		f := func(t *testing.T) {
+			signature.RecoveryOffset = tst.offset
+
+			v, r, s, err := signature.Sign(value, pk)
+			if err != nil {
+				t.Fatalf("Test %s:\tShould be able to sign the value: %s", tst.name, err)
+			}
+
+			if got := v.Uint64(); got != tst.offset && got != tst.offset+1 {
+				t.Fatalf("Test %s:\tShould get a v of %d or %d: got %d", tst.name, tst.offset, tst.offset+1, got)
+			}
+
+			if err := signature.VerifySignature(v, r, s); err != nil {
+				t.Fatalf("Test %s:\tShould be able to verify the signature: %s", tst.name, err)
+			}
+
+			address, err := signature.FromAddress(value, v, r, s)
+			if err != nil {
+				t.Fatalf("Test %s:\tShould be able to recover the address: %s", tst.name, err)
+			}
+			if address != idKennedy {
+				t.Fatalf("Test %s:\tShould recover the signing address: got %s, exp %s", tst.name, address, idKennedy)
+			}
+
+			if got := signature.ToSignatureBytesWithArdanID(v, r, s)[64]; uint64(got) != v.Uint64() {
+				t.Fatalf("Test %s:\tShould keep the offset in the signature bytes: got %d, exp %d", tst.name, got, v.Uint64())
+			}
+			if got := signature.ToSignatureBytes(v, r, s)[64]; got != 0 && got != 1 {
+				t.Fatalf("Test %s:\tShould remove the offset from the signature bytes: got %d", tst.name, got)
+			}
+
+			// A node using the other offset must reject the signature.
+			signature.RecoveryOffset = tst.other
+			if err := signature.VerifySignature(v, r, s); err == nil {
+				t.Fatalf("Test %s:\tShould not verify the signature with an offset of %d.", tst.name, tst.other)
+			}
+		}
+
+		t.Run(tst.name, f)
+	}
+}
@@ -5,8 +5,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/andrewyang17/blockchain/foundation/blockchain/database"
+	"github.com/andrewyang17/blockchain/foundation/blockchain/metrics"
 )
 
 // ErrNoTransactions is returned when a block is requested to be created
@@ -27,11 +29,17 @@ func (s *State) MineNewBlock(ctx context.Context) (database.Block, error) {
 		return database.Block{}, ErrNoTransactions
 	}
 
-	// Pick the best transactions from the mempool.
-	trans := s.mempool.PickBest(s.genesis.TransPerBlock)
+	// Pick the best transactions from the mempool that can execute against
+	// the current nonce of each account. The nonces are only loaded for the
+	// accounts with transactions in the pool.
+	trans := s.mempool.PickBestFromSource(s.db, s.genesis.TransPerBlock)
+	if len(trans) == 0 {
+		return database.Block{}, ErrNoTransactions
+	}
 
 	// If PoA is being used, drop the difficulty down to 1 to speed up
-	// the mining operation.
+	// the mining operation. When the genesis sets a block time, only the
+	// first block uses this difficulty and later blocks adjust their parent's.
 	difficulty := s.genesis.Difficulty
 	if s.Consensus() == ConsensusPOA {
 		difficulty = 1
@@ -39,13 +47,14 @@ func (s *State) MineNewBlock(ctx context.Context) (database.Block, error) {
 
 	// Attempt to create a new block by solving the POW puzzle. This can be cancelled.
 	block, err := database.POW(ctx, database.POWArgs{
-		BeneficiaryID: s.beneficiaryID,
-		Difficulty:    difficulty,
-		MiningReward:  s.genesis.MiningReward,
-		PrevBlock:     s.db.LatestBlock(),
-		StateRoot:     s.db.HashState(),
-		Trans:         trans,
-		EvHandler:     s.evHandler,
+		BeneficiaryID:   s.beneficiaryID,
+		Difficulty:      difficulty,
+		TargetBlockTime: s.genesis.TargetBlockTime(),
+		MiningReward:    s.genesis.MiningReward,
+		PrevBlock:       s.db.LatestBlock(),
+		StateRoot:       s.db.HashState(),
+		Trans:           trans,
+		EvHandler:       s.evHandler,
 	})
 	if err != nil {
 		return database.Block{}, err
@@ -84,6 +93,54 @@ func (s *State) ProcessProposedBlock(block database.Block) error {
 	return nil
 }
 
+// TxReport is the outcome of a transaction in a block that is dry run. Err is
+// nil when the transaction would be applied without any problem.
+type TxReport struct {
+	Tx  database.BlockTx
+	Err error
+}
+
+// BlockReport is the outcome of dry running a block. Err is the reason the
+// block would be rejected, nil if it would be accepted. StateRoot is the hash
+// of the accounts after the block is applied.
+type BlockReport struct {
+	Err       error
+	Txs       []TxReport
+	StateRoot string
+}
+
+// DryRunBlock runs the validation a proposed block goes through and applies
+// the block to a copy of the accounts. Each transaction also has its chain id,
+// signature, and fields validated. Nothing in the node is changed.
+func (s *State) DryRunBlock(block database.Block) BlockReport {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	{
+		report := BlockReport{
+			Err: block.ValidateBlock(s.db.LatestBlock(), s.db.HashState(), s.genesis.TargetBlockTime(), s.evHandler),
+		}
+
+		// The transactions are applied the same way validateUpdateDatabase
+		// applies them, so the state root matches what committing the block
+		// would produce.
+		db := s.db.Clone()
+		for _, tx := range block.MerkleTree.Values() {
+			txErr := tx.ValidateChainIDs(s.chainIDs, s.genesis.MinFee)
+
+			if err := db.ApplyTransaction(block, tx); err != nil && txErr == nil {
+				txErr = err
+			}
+
+			report.Txs = append(report.Txs, TxReport{Tx: tx, Err: txErr})
+		}
+
+		db.ApplyMiningReward(block)
+		report.StateRoot = db.HashState()
+
+		return report
+	}
+}
+
 // =============================================================================
 
 // validateUpdateDatabase takes the block and validates the block against the
@@ -100,18 +157,24 @@ func (s *State) validateUpdateDatabase(block database.Block) error {
 		// me to this function for the same block number, I could replace the peer
 		// block with my own and attempt to have other peers accept my block instead.
 
-		if err := block.ValidateBlock(s.db.LatestBlock(), s.db.HashState(), s.evHandler); err != nil {
+		if err := block.ValidateBlock(s.db.LatestBlock(), s.db.HashState(), s.genesis.TargetBlockTime(), s.evHandler); err != nil {
 			return err
 		}
 
 		s.evHandler("state: validateUpdateDatabase: write to disk")
 
-		// Write the new block to the chain on disk.
-		if err := s.db.Write(block); err != nil {
+		// Write the new block to the chain on disk. The block has already been
+		// accepted, so the write isn't tied to the mining context which is
+		// cancelled when a peer's block arrives.
+		start := time.Now()
+		if err := s.db.Write(context.Background(), block); err != nil {
 			return err
 		}
 		s.db.UpdateLatestBlock(block)
 
+		metrics.AddBlockWritten(time.Since(start))
+		metrics.SetChainHeight(block.Header.Number)
+
 		s.evHandler("state: validateUpdateDatabase: update accounts and remove from mempool")
 
 		// Process the transactions and update the accounts.
@@ -133,6 +196,8 @@ func (s *State) validateUpdateDatabase(block database.Block) error {
 		// Apply the mining reward for this block.
 		s.db.ApplyMiningReward(block)
 
+		metrics.SetMempool(s.mempool.Count())
+
 		// Send an event about this new block.
 		s.blockEvent(block)
 
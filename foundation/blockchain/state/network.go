@@ -14,6 +14,15 @@ import (
 
 const baseURL = "http://%s/v1/node"
 
+// mempoolPageSize is the number of transactions requested per call when
+// asking a peer for their mempool.
+const mempoolPageSize = 1000
+
+// blockPageSize is the number of blocks requested per call when asking a
+// peer for the blocks this node is missing. This needs to stay within the
+// block range peers allow for a single call.
+const blockPageSize = 100
+
 // NetSendBlockToPeers takes the new mined block and sends it to all know peers.
 func (s *State) NetSendBlockToPeers(block database.Block) error {
 	s.evHandler("state: NetSendBlockToPeers: started")
@@ -92,6 +101,11 @@ func (s *State) NetRequestPeerStatus(pr peer.Peer) (peer.PeerStatus, error) {
 
 	s.evHandler("state: NetRequestPeerStatus: peer-node[%s]: latest-blknum[%d]: peer-list[%s]", pr, ps.LatestBlockNumber, ps.KnownPeers)
 
+	// Remember the height of the peer so the sync status can be reported.
+	s.mu.Lock()
+	s.peerHeights[pr] = ps.LatestBlockNumber
+	s.mu.Unlock()
+
 	return ps, nil
 }
 
@@ -100,11 +114,24 @@ func (s *State) NetRequestPeerMempool(pr peer.Peer) ([]database.BlockTx, error)
 	s.evHandler("state: NetRequestPeerMempool: started: %s", pr)
 	defer s.evHandler("state: NetRequestPeerMempool: completed: %s", pr)
 
-	url := fmt.Sprintf("%s/tx/list", fmt.Sprintf(baseURL, pr.Host))
-
+	// The mempool is returned a page at a time, so keep asking for the
+	// next page until all the transactions have been received.
 	var mempool []database.BlockTx
-	if err := send(http.MethodGet, url, nil, &mempool); err != nil {
-		return nil, err
+	for {
+		url := fmt.Sprintf("%s/tx/list?limit=%d&offset=%d", fmt.Sprintf(baseURL, pr.Host), mempoolPageSize, len(mempool))
+
+		var page struct {
+			Total int                `json:"total"`
+			Txs   []database.BlockTx `json:"transactions"`
+		}
+		if err := send(http.MethodGet, url, nil, &page); err != nil {
+			return nil, err
+		}
+
+		mempool = append(mempool, page.Txs...)
+		if len(page.Txs) == 0 || len(mempool) >= page.Total {
+			break
+		}
 	}
 
 	s.evHandler("state: NetRequestPeerMempool: len[%d]", len(mempool))
@@ -129,28 +156,34 @@ func (s *State) NetRequestPeerBlocks(pr peer.Peer) error {
 	// transactions to have a complete account database. The cryptographic audit
 	// does take place as each full block is downloaded from peers.
 
-	from := s.LatestBlock().Header.Number + 1
-	url := fmt.Sprintf("%s/block/list/%d/latest", fmt.Sprintf(baseURL, pr.Host), from)
+	// Peers limit the number of blocks returned in a single call, so keep
+	// asking for the next page until a short page says we have caught up.
+	for {
+		from := s.LatestBlock().Header.Number + 1
+		url := fmt.Sprintf("%s/block/list/%d/%d", fmt.Sprintf(baseURL, pr.Host), from, from+blockPageSize-1)
 
-	var blocksData []database.BlockData
-	if err := send(http.MethodGet, url, nil, &blocksData); err != nil {
-		return err
-	}
+		var blocksData []database.BlockData
+		if err := send(http.MethodGet, url, nil, &blocksData); err != nil {
+			return err
+		}
 
-	s.evHandler("state: NetRequestPeerBlocks: found blocks[%d]", len(blocksData))
+		s.evHandler("state: NetRequestPeerBlocks: found blocks[%d]", len(blocksData))
 
-	for _, blockData := range blocksData {
-		block, err := database.ToBlock(blockData)
-		if err != nil {
-			return err
+		for _, blockData := range blocksData {
+			block, err := database.ToBlock(blockData)
+			if err != nil {
+				return err
+			}
+
+			if err := s.ProcessProposedBlock(block); err != nil {
+				return err
+			}
 		}
 
-		if err := s.ProcessProposedBlock(block); err != nil {
-			return err
+		if len(blocksData) < blockPageSize {
+			return nil
 		}
 	}
-
-	return nil
 }
 
 // =============================================================================
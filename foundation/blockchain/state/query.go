@@ -1,6 +1,16 @@
 package state
 
-import "github.com/andrewyang17/blockchain/foundation/blockchain/database"
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/andrewyang17/blockchain/foundation/blockchain/database"
+)
+
+// ErrTxNotFound is returned when a transaction can't be found in the mempool
+// or the blockchain.
+var ErrTxNotFound = errors.New("transaction not found")
 
 // QueryLastest represents to query the latest block in the chain.
 const QueryLastest = ^uint64(0) >> 1
@@ -14,7 +24,7 @@ func (s *State) QueryAccount(account database.AccountID) (database.Account, erro
 
 // QueryBlocksByNumber returns the set of blocks based on block numbers. This
 // function reads the blockchain from disk first.
-func (s *State) QueryBlocksByNumber(from uint64, to uint64) []database.Block {
+func (s *State) QueryBlocksByNumber(ctx context.Context, from uint64, to uint64) []database.Block {
 	if from == QueryLastest {
 		from = s.db.LatestBlock().Header.Number
 		to = from
@@ -25,7 +35,7 @@ func (s *State) QueryBlocksByNumber(from uint64, to uint64) []database.Block {
 
 	var out []database.Block
 	for i := from; i <= to; i++ {
-		block, err := s.db.GetBlock(i)
+		block, err := s.db.GetBlock(ctx, i)
 		if err != nil {
 			s.evHandler("state: getblock: ERROR: %s", err)
 			return nil
@@ -36,20 +46,26 @@ func (s *State) QueryBlocksByNumber(from uint64, to uint64) []database.Block {
 	return out
 }
 
+// QueryBlockByNumber returns the block with the specified number. This
+// function reads the block from disk.
+func (s *State) QueryBlockByNumber(ctx context.Context, num uint64) (database.Block, error) {
+	return s.db.GetBlock(ctx, num)
+}
+
 // QueryBlocksByAccount returns the set of blocks by account. If the account
 // is empty, all blocks are returned. This function reads the blockchain
 // from disk first.
-func (s *State) QueryBlocksByAccount(accountID database.AccountID) ([]database.Block, error) {
+func (s *State) QueryBlocksByAccount(ctx context.Context, accountID database.AccountID) ([]database.Block, error) {
 	var out []database.Block
 
-	iter := s.db.ForEach()
+	iter := s.db.ForEach(ctx)
 	for block, err := iter.Next(); !iter.Done(); block, err = iter.Next() {
 		if err != nil {
 			return nil, err
 		}
 
 		for _, tx := range block.MerkleTree.Values() {
-			if accountID == "" || tx.FromID == accountID || tx.ToID == accountID {
+			if accountID == "" || tx.FromID == accountID || tx.Pays(accountID) {
 				out = append(out, block)
 				break
 			}
@@ -58,3 +74,30 @@ func (s *State) QueryBlocksByAccount(accountID database.AccountID) ([]database.B
 
 	return out, nil
 }
+
+// QueryTransactionByHash searches the mempool and then the blockchain for the
+// transaction with the specified hash. The number of the block holding the
+// transaction is returned, which is 0 if the transaction is still pending
+// in the mempool.
+func (s *State) QueryTransactionByHash(ctx context.Context, hash string) (database.BlockTx, uint64, error) {
+	for _, tx := range s.mempool.PickBest() {
+		if strings.EqualFold(tx.SignedTx.Hash(), hash) {
+			return tx, 0, nil
+		}
+	}
+
+	iter := s.db.ForEach(ctx)
+	for block, err := iter.Next(); !iter.Done(); block, err = iter.Next() {
+		if err != nil {
+			return database.BlockTx{}, 0, err
+		}
+
+		for _, tx := range block.MerkleTree.Values() {
+			if strings.EqualFold(tx.SignedTx.Hash(), hash) {
+				return tx, block.Header.Number, nil
+			}
+		}
+	}
+
+	return database.BlockTx{}, 0, ErrTxNotFound
+}
@@ -3,11 +3,13 @@
 package state
 
 import (
+	"context"
 	"sync"
 
 	"github.com/andrewyang17/blockchain/foundation/blockchain/database"
 	"github.com/andrewyang17/blockchain/foundation/blockchain/genesis"
 	"github.com/andrewyang17/blockchain/foundation/blockchain/mempool"
+	"github.com/andrewyang17/blockchain/foundation/blockchain/mempool/selector"
 	"github.com/andrewyang17/blockchain/foundation/blockchain/peer"
 )
 
@@ -19,6 +21,10 @@ const (
 	ConsensusPOA = "POA"
 )
 
+// syncThreshold is the number of blocks this node can be behind the highest
+// block reported by its peers and still be considered in sync.
+const syncThreshold = 2
+
 // =============================================================================
 
 // EventHandler defines a function that is called when events
@@ -45,9 +51,13 @@ type Config struct {
 	Storage        database.Storage
 	Genesis        genesis.Genesis
 	SelectStrategy string
+	MaxTxsPerAcct  int
+	TipAgeFactor   uint64
+	MaxBlockBytes  int
 	KnownPeers     *peer.PeerSet
 	EvHandler      EventHandler
 	Consensus      string
+	ForkChainIDs   []uint16
 }
 
 // State manages the blockchain database.
@@ -60,6 +70,8 @@ type State struct {
 	host          string
 	evHandler     EventHandler
 	consensus     string
+	chainIDs      []uint16
+	peerHeights   map[peer.Peer]uint64
 
 	knownPeers *peer.PeerSet
 	storage    database.Storage
@@ -87,7 +99,11 @@ func New(cfg Config) (*State, error) {
 	}
 
 	// Construct a mempool with the specified sort strategy.
-	mempool, err := mempool.NewWithStrategy(cfg.SelectStrategy)
+	mempool, err := mempool.NewWithOptions(cfg.SelectStrategy, selector.Options{
+		MaxPerAccount: cfg.MaxTxsPerAcct,
+		AgeFactor:     cfg.TipAgeFactor,
+		MaxBytes:      cfg.MaxBlockBytes,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -99,7 +115,9 @@ func New(cfg Config) (*State, error) {
 		storage:       cfg.Storage,
 		evHandler:     ev,
 		consensus:     cfg.Consensus,
+		chainIDs:      append([]uint16{cfg.Genesis.ChainID}, cfg.ForkChainIDs...),
 		allowMining:   true,
+		peerHeights:   make(map[peer.Peer]uint64),
 
 		knownPeers: cfg.KnownPeers,
 		genesis:    cfg.Genesis,
@@ -132,6 +150,11 @@ func (s *State) Shutdown() error {
 	return nil
 }
 
+// Healthy checks the storage backend is able to take writes.
+func (s *State) Healthy(ctx context.Context) error {
+	return s.db.Healthy(ctx)
+}
+
 // =============================================================================
 
 // IsMiningAllowed identifies if we are allowed to mine blocks. This
@@ -143,6 +166,29 @@ func (s *State) IsMiningAllowed() bool {
 	return s.allowMining
 }
 
+// SyncStatus returns the latest block number and if this node is within a
+// small threshold of the highest block reported by its peers. The node is
+// never considered in sync while a resync is running.
+func (s *State) SyncStatus() (uint64, bool) {
+	height := s.db.LatestBlock().Header.Number
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	{
+		if !s.allowMining {
+			return height, false
+		}
+
+		for _, peerHeight := range s.peerHeights {
+			if peerHeight > height+syncThreshold {
+				return height, false
+			}
+		}
+
+		return height, true
+	}
+}
+
 // Host returns a copy of host information.
 func (s *State) Host() string {
 	return s.host
@@ -193,6 +239,23 @@ func (s *State) AddKnownPeer(peer peer.Peer) bool {
 // the known peer list.
 func (s *State) RemoveKnownPeer(peer peer.Peer) {
 	s.knownPeers.Remove(peer)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	{
+		delete(s.peerHeights, peer)
+	}
+}
+
+// PeerHeight returns the latest block number the peer reported in its last
+// status. False is returned if the peer hasn't reported a status yet.
+func (s *State) PeerHeight(peer peer.Peer) (uint64, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	{
+		height, exists := s.peerHeights[peer]
+		return height, exists
+	}
 }
 
 // KnownExternalPeers retrieves a copy of the known peer list without
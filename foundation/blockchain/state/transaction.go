@@ -1,9 +1,122 @@
 package state
 
-import "github.com/andrewyang17/blockchain/foundation/blockchain/database"
+import (
+	"fmt"
+
+	"github.com/andrewyang17/blockchain/foundation/blockchain/database"
+	"github.com/andrewyang17/blockchain/foundation/blockchain/metrics"
+)
 
 // UpsertWalletTransaction accepts a transaction from a wallet for inclusion.
 func (s *State) UpsertWalletTransaction(signedTx database.SignedTx) error {
+	tx, err := s.upsertWalletTransaction(signedTx)
+	if err != nil {
+		metrics.AddTxRejected()
+		return err
+	}
+
+	metrics.AddTxAccepted()
+	metrics.SetMempool(s.mempool.Count())
+
+	s.Worker.SignalShareTx(tx)
+	s.Worker.SignalStartMining()
+
+	return nil
+}
+
+// UpsertNodeTransaction accepts a transaction from a node for inclusion.
+func (s *State) UpsertNodeTransaction(tx database.BlockTx) error {
+	if err := s.upsertNodeTransaction(tx); err != nil {
+		metrics.AddTxRejected()
+		return err
+	}
+
+	metrics.AddTxAccepted()
+	metrics.SetMempool(s.mempool.Count())
+
+	s.Worker.SignalStartMining()
+
+	return nil
+}
+
+// PersistMempool writes the transactions in the mempool to the specified file
+// so they survive a restart.
+func (s *State) PersistMempool(path string) error {
+	return s.mempool.Persist(path)
+}
+
+// RestoreMempool adds the transactions written by PersistMempool back into
+// the mempool. Transactions that are no longer valid against the current
+// state, like ones whose nonce has been used by a block, are dropped. The
+// number of transactions restored and dropped are returned.
+func (s *State) RestoreMempool(path string) (restored int, dropped int, err error) {
+	restored, dropped, err = s.mempool.Restore(path, s.validateRestoredTx)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	metrics.SetMempool(s.mempool.Count())
+
+	return restored, dropped, nil
+}
+
+// TxEstimate is the outcome of estimating a transaction. Err is the reason the
+// transaction would fail, nil if it would succeed. Balance is the balance of
+// the from account after the transaction is applied, which still has the gas
+// fee taken when the transaction fails. Fee is the gas price times the units.
+type TxEstimate struct {
+	Err      error
+	Balance  uint64
+	GasPrice uint64
+	GasUnits uint64
+	Fee      uint64
+}
+
+// EstimateTransaction runs the unsigned transaction against a copy of the
+// accounts as if it was mined into the next block. The fields are validated
+// the same way a submitted transaction is, except for the signature. Nothing
+// in the node, including the mempool, is changed.
+func (s *State) EstimateTransaction(tx database.Tx) TxEstimate {
+	gasPrice, gasUnits := s.gas(tx)
+
+	estimate := TxEstimate{
+		GasPrice: gasPrice,
+		GasUnits: gasUnits,
+		Fee:      gasPrice * gasUnits,
+	}
+
+	db := s.db.Clone()
+	if account, err := db.Query(tx.FromID); err == nil {
+		estimate.Balance = account.Balance
+	}
+
+	if err := tx.ValidateFields(s.chainIDs, s.genesis.MinFee); err != nil {
+		estimate.Err = err
+		return estimate
+	}
+
+	block := database.Block{
+		Header: database.BlockHeader{
+			Number:        db.LatestBlock().Header.Number + 1,
+			BeneficiaryID: s.beneficiaryID,
+		},
+	}
+
+	blockTx := database.NewBlockTx(database.SignedTx{Tx: tx}, gasPrice, gasUnits)
+	estimate.Err = db.ApplyTransaction(block, blockTx)
+
+	if account, err := db.Query(tx.FromID); err == nil {
+		estimate.Balance = account.Balance
+	}
+
+	return estimate
+}
+
+// =============================================================================
+
+// upsertWalletTransaction validates the wallet transaction and adds it to
+// the mempool.
+func (s *State) upsertWalletTransaction(signedTx database.SignedTx) (database.BlockTx, error) {
 
 	// CORE NOTE: It's up to the wallet to make sure the account has a proper
 	// balance and this transaction has a proper nonce. Fees will be taken if
@@ -12,36 +125,66 @@ func (s *State) UpsertWalletTransaction(signedTx database.SignedTx) error {
 
 	// Check the signed transaction has a proper signature, the from matches the
 	// signature, and the from and to fields are properly formatted.
-	if err := signedTx.Validate(s.genesis.ChainID); err != nil {
-		return err
+	if err := signedTx.ValidateChainIDs(s.chainIDs, s.genesis.MinFee); err != nil {
+		return database.BlockTx{}, err
 	}
 
-	const oneUnitOfGas = 1
-	tx := database.NewBlockTx(signedTx, s.genesis.GasPrice, oneUnitOfGas)
+	tx := s.NewBlockTx(signedTx)
 	if err := s.mempool.Upsert(tx); err != nil {
-		return err
+		return database.BlockTx{}, err
 	}
 
-	s.Worker.SignalShareTx(tx)
-	s.Worker.SignalStartMining()
+	return tx, nil
+}
 
-	return nil
+// NewBlockTx constructs the block transaction for the signed transaction
+// with the gas it will be charged when it's mined.
+func (s *State) NewBlockTx(signedTx database.SignedTx) database.BlockTx {
+	gasPrice, gasUnits := s.gas(signedTx.Tx)
+	return database.NewBlockTx(signedTx, gasPrice, gasUnits)
 }
 
-// UpsertNodeTransaction accepts a transaction from a node for inclusion.
-func (s *State) UpsertNodeTransaction(tx database.BlockTx) error {
+// gas returns the gas price and units the transaction pays when it's mined.
+// Legacy transactions pay one unit of gas at the genesis gas price. All
+// other transactions pay the gas they signed for.
+func (s *State) gas(tx database.Tx) (gasPrice uint64, gasUnits uint64) {
+	const oneUnitOfGas = 1
+	if tx.IsLegacy() {
+		return s.genesis.GasPrice, oneUnitOfGas
+	}
+
+	return tx.GasPrice, tx.GasUnits
+}
+
+// upsertNodeTransaction validates the node transaction and adds it to
+// the mempool.
+func (s *State) upsertNodeTransaction(tx database.BlockTx) error {
 
 	// Check the signed transaction has a proper signature, the from matches the
 	// signature, and the from and to fields are properly formatted.
-	if err := tx.Validate(s.genesis.ChainID); err != nil {
+	if err := tx.ValidateChainIDs(s.chainIDs, s.genesis.MinFee); err != nil {
 		return err
 	}
 
-	if err := s.mempool.Upsert(tx); err != nil {
+	return s.mempool.Upsert(tx)
+}
+
+// validateRestoredTx checks a transaction restored from disk is still valid
+// and its nonce has not been used by the account since it was saved.
+func (s *State) validateRestoredTx(tx database.BlockTx) error {
+	if err := tx.ValidateChainIDs(s.chainIDs, s.genesis.MinFee); err != nil {
 		return err
 	}
 
-	s.Worker.SignalStartMining()
+	// An account that doesn't exist yet hasn't used any nonces.
+	var nonce uint64
+	if account, err := s.db.Query(tx.FromID); err == nil {
+		nonce = account.Nonce
+	}
+
+	if tx.Nonce <= nonce {
+		return fmt.Errorf("stale nonce, got %d, exp greater than %d", tx.Nonce, nonce)
+	}
 
 	return nil
 }
\ No newline at end of file
@@ -0,0 +1,274 @@
+// Package boltdb implements the ability to read and write blocks to a single
+// BoltDB file, storing each block in a bucket keyed by the block number.
+package boltdb
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/andrewyang17/blockchain/foundation/blockchain/database"
+	bolt "go.etcd.io/bbolt"
+)
+
+// Set of buckets used to store the blocks and the block hash index.
+var (
+	blocksBucket = []byte("blocks")
+	hashesBucket = []byte("hashes")
+)
+
+// BoltDB represents the serialization implementation for reading and storing
+// blocks in a single BoltDB file. This implements the database.Storage
+// interface.
+type BoltDB struct {
+	db *bolt.DB
+}
+
+// New constructs a BoltDB value for use. The dbPath is the path to the
+// database file which is created if it does not exist.
+func New(dbPath string) (*BoltDB, error) {
+	if err := os.MkdirAll(filepath.Dir(dbPath), 0755); err != nil {
+		return nil, err
+	}
+
+	db, err := bolt.Open(dbPath, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+
+	// Make sure the buckets exist before any reads take place.
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, bucket := range [][]byte{blocksBucket, hashesBucket} {
+			if _, err := tx.CreateBucketIfNotExists(bucket); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &BoltDB{db: db}, nil
+}
+
+// Close closes the underlying BoltDB file.
+func (b *BoltDB) Close() error {
+	return b.db.Close()
+}
+
+// Write takes the specified database block and stores it in the blocks
+// bucket keyed by the block number. The block hash is indexed in the same
+// transaction. Concurrent writes are serialized by BoltDB's single writer
+// transaction model.
+func (b *BoltDB) Write(ctx context.Context, blockData database.BlockData) error {
+	return b.WriteBatch(ctx, []database.BlockData{blockData})
+}
+
+// WriteBatch takes the specified database blocks and stores them in a single
+// transaction, so either all the blocks are written or none of them are. The
+// error names the block that failed. A cancelled context rolls back the
+// transaction.
+func (b *BoltDB) WriteBatch(ctx context.Context, blocks []database.BlockData) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		for _, blockData := range blocks {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			if err := put(tx, blockData); err != nil {
+				return fmt.Errorf("block %d: %w", blockData.Header.Number, err)
+			}
+		}
+		return nil
+	})
+}
+
+// GetBlock searches the blocks bucket to locate and return the contents of
+// the specified block by number.
+func (b *BoltDB) GetBlock(ctx context.Context, num uint64) (database.BlockData, error) {
+	if err := ctx.Err(); err != nil {
+		return database.BlockData{}, err
+	}
+
+	var blockData database.BlockData
+	err := b.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(blocksBucket).Get(toKey(num))
+		if data == nil {
+			return fmt.Errorf("block %d: %w", num, fs.ErrNotExist)
+		}
+
+		return json.Unmarshal(data, &blockData)
+	})
+	if err != nil {
+		return database.BlockData{}, err
+	}
+
+	return blockData, nil
+}
+
+// GetBlockByHash uses the hash index to locate and return the contents of
+// the specified block by hash.
+func (b *BoltDB) GetBlockByHash(ctx context.Context, hash string) (database.BlockData, error) {
+	if err := ctx.Err(); err != nil {
+		return database.BlockData{}, err
+	}
+
+	var blockData database.BlockData
+	err := b.db.View(func(tx *bolt.Tx) error {
+		key := tx.Bucket(hashesBucket).Get([]byte(hash))
+		if key == nil {
+			return fmt.Errorf("block hash %s: %w", hash, fs.ErrNotExist)
+		}
+
+		data := tx.Bucket(blocksBucket).Get(key)
+		if data == nil {
+			return fmt.Errorf("block hash %s: %w", hash, fs.ErrNotExist)
+		}
+
+		if err := json.Unmarshal(data, &blockData); err != nil {
+			return err
+		}
+
+		// The block number could have been rewritten with a different block
+		// since the index entry was added.
+		if blockData.Hash != hash {
+			return fmt.Errorf("block hash %s: %w", hash, fs.ErrNotExist)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return database.BlockData{}, err
+	}
+
+	return blockData, nil
+}
+
+// ForEach returns an iterator to walk through all the blocks
+// starting with block number 1.
+func (b *BoltDB) ForEach(ctx context.Context) database.Iterator {
+	return &boltIterator{ctx: ctx, storage: b}
+}
+
+// Healthy runs an empty write transaction to check the database file is
+// open and writable.
+func (b *BoltDB) Healthy(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return nil
+	})
+}
+
+// Reset will clear out the blockchain in the database file.
+func (b *BoltDB) Reset() error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		for _, bucket := range [][]byte{blocksBucket, hashesBucket} {
+			if err := tx.DeleteBucket(bucket); err != nil && !errors.Is(err, bolt.ErrBucketNotFound) {
+				return err
+			}
+
+			if _, err := tx.CreateBucket(bucket); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// put stores the block and indexes the block hash inside the transaction.
+func put(tx *bolt.Tx, blockData database.BlockData) error {
+	data, err := json.MarshalIndent(blockData, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	key := toKey(blockData.Header.Number)
+
+	if err := tx.Bucket(blocksBucket).Put(key, data); err != nil {
+		return err
+	}
+
+	return tx.Bucket(hashesBucket).Put([]byte(blockData.Hash), key)
+}
+
+// toKey converts the block number into a big endian key so the blocks
+// are ordered by number inside the bucket.
+func toKey(blockNum uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, blockNum)
+	return key
+}
+
+// =============================================================================
+
+// boltIterator represents the iteration implementation for walking through
+// and reading blocks from the database file. Each block is read in its own
+// short read-only transaction, seeking past the last block number read, so
+// no transaction is held open between calls and an iterator that isn't run
+// to the end of the chain never blocks Close or Reset. Blocks written while
+// iterating are seen if they come after the last block read. This implements
+// the database Iterator interface.
+type boltIterator struct {
+	ctx        context.Context
+	storage    *BoltDB
+	next       uint64
+	endOfChain bool
+	failed     bool
+}
+
+// Next retrieves the next block from the database file.
+func (bi *boltIterator) Next() (database.BlockData, error) {
+	if bi.endOfChain || bi.failed {
+		bi.endOfChain = true
+		return database.BlockData{}, database.ErrEndOfChain
+	}
+
+	// Report the context error once, the next call will then mark the
+	// end of the chain.
+	if err := bi.ctx.Err(); err != nil {
+		bi.failed = true
+		return database.BlockData{}, err
+	}
+
+	var blockData database.BlockData
+	var found bool
+	err := bi.storage.db.View(func(tx *bolt.Tx) error {
+		key, data := tx.Bucket(blocksBucket).Cursor().Seek(toKey(bi.next))
+		if key == nil {
+			return nil
+		}
+
+		found = true
+		bi.next = binary.BigEndian.Uint64(key) + 1
+
+		// The data is only valid while the transaction is open.
+		return json.Unmarshal(data, &blockData)
+	})
+	if err != nil {
+		bi.failed = true
+		return database.BlockData{}, err
+	}
+
+	if !found {
+		bi.endOfChain = true
+		return database.BlockData{}, database.ErrEndOfChain
+	}
+
+	return blockData, nil
+}
+
+// Done returns the end of chain value.
+func (bi *boltIterator) Done() bool {
+	return bi.endOfChain
+}
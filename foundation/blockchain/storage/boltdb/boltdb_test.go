@@ -0,0 +1,190 @@
+package boltdb_test
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/andrewyang17/blockchain/foundation/blockchain/database"
+	"github.com/andrewyang17/blockchain/foundation/blockchain/storage/boltdb"
+)
+
+// newBlockData returns the block data for the header with a matching hash.
+func newBlockData(header database.BlockHeader) database.BlockData {
+	return database.BlockData{
+		Hash:   database.Block{Header: header}.Hash(),
+		Header: header,
+	}
+}
+
+// newBoltDB constructs a BoltDB value in a temporary directory.
+func newBoltDB(t *testing.T) (*boltdb.BoltDB, string) {
+	dbPath := filepath.Join(t.TempDir(), "blocks.db")
+
+	b, err := boltdb.New(dbPath)
+	if err != nil {
+		t.Fatalf("Should be able to construct the storage: %s", err)
+	}
+
+	return b, dbPath
+}
+
+func Test_BoltDB(t *testing.T) {
+	b, dbPath := newBoltDB(t)
+
+	blocks := make(map[uint64]database.BlockData)
+	for _, num := range []uint64{3, 1, 2} {
+		blocks[num] = newBlockData(database.BlockHeader{Number: num, MiningReward: 700})
+		if err := b.Write(context.Background(), blocks[num]); err != nil {
+			t.Fatalf("Should be able to write block %d: %s", num, err)
+		}
+	}
+
+	for num, exp := range blocks {
+		got, err := b.GetBlock(context.Background(), num)
+		if err != nil {
+			t.Fatalf("Should be able to get block %d: %s", num, err)
+		}
+		if got.Hash != exp.Hash {
+			t.Fatalf("Should get back block %d: got %s, exp %s", num, got.Hash, exp.Hash)
+		}
+
+		got, err = b.GetBlockByHash(context.Background(), exp.Hash)
+		if err != nil {
+			t.Fatalf("Should be able to get block %d by hash: %s", num, err)
+		}
+		if got.Header.Number != num {
+			t.Fatalf("Should get back block %d by hash: got %d", num, got.Header.Number)
+		}
+	}
+
+	if _, err := b.GetBlock(context.Background(), 4); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Should get fs.ErrNotExist for a missing block, got %v", err)
+	}
+
+	var exp uint64
+	iter := b.ForEach(context.Background())
+	for blockData, err := iter.Next(); !iter.Done(); blockData, err = iter.Next() {
+		if err != nil {
+			t.Fatalf("Should be able to iterate the blocks: %s", err)
+		}
+
+		exp++
+		if blockData.Header.Number != exp {
+			t.Fatalf("Should iterate in block order: got %d, exp %d", blockData.Header.Number, exp)
+		}
+	}
+	if exp != 3 {
+		t.Fatalf("Should iterate over 3 blocks, got %d", exp)
+	}
+	if _, err := iter.Next(); !errors.Is(err, database.ErrEndOfChain) {
+		t.Fatalf("Should get ErrEndOfChain once the iterator is exhausted, got %v", err)
+	}
+
+	if err := b.Reset(); err != nil {
+		t.Fatalf("Should be able to reset: %s", err)
+	}
+	if _, err := b.GetBlock(context.Background(), 1); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Should not find block 1 after a reset, got %v", err)
+	}
+	if _, err := b.GetBlockByHash(context.Background(), blocks[1].Hash); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Should not find block 1 by hash after a reset, got %v", err)
+	}
+
+	// The blocks written before closing must be there after reopening.
+	if err := b.Write(context.Background(), blocks[1]); err != nil {
+		t.Fatalf("Should be able to write block 1: %s", err)
+	}
+	if err := b.Close(); err != nil {
+		t.Fatalf("Should be able to close: %s", err)
+	}
+
+	b, err := boltdb.New(dbPath)
+	if err != nil {
+		t.Fatalf("Should be able to reopen the storage: %s", err)
+	}
+	defer b.Close()
+
+	if _, err := b.GetBlock(context.Background(), 1); err != nil {
+		t.Fatalf("Should be able to get block 1 after reopening: %s", err)
+	}
+}
+
+func Test_ConcurrentWrite(t *testing.T) {
+	b, _ := newBoltDB(t)
+	defer b.Close()
+
+	const numBlocks = 50
+
+	var wg sync.WaitGroup
+	errs := make([]error, numBlocks)
+
+	wg.Add(numBlocks)
+	for i := 0; i < numBlocks; i++ {
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = b.Write(context.Background(), newBlockData(database.BlockHeader{Number: uint64(i + 1)}))
+		}(i)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("Should be able to write block %d: %s", i+1, err)
+		}
+	}
+
+	var count uint64
+	iter := b.ForEach(context.Background())
+	for blockData, err := iter.Next(); !iter.Done(); blockData, err = iter.Next() {
+		if err != nil {
+			t.Fatalf("Should be able to iterate the blocks: %s", err)
+		}
+
+		count++
+		if blockData.Header.Number != count {
+			t.Fatalf("Should iterate in block order: got %d, exp %d", blockData.Header.Number, count)
+		}
+	}
+	if count != numBlocks {
+		t.Fatalf("Should have every block written: got %d, exp %d", count, numBlocks)
+	}
+}
+
+func Test_AbandonedIterator(t *testing.T) {
+	b, _ := newBoltDB(t)
+
+	for num := uint64(1); num <= 3; num++ {
+		if err := b.Write(context.Background(), newBlockData(database.BlockHeader{Number: num})); err != nil {
+			t.Fatalf("Should be able to write block %d: %s", num, err)
+		}
+	}
+
+	// Stop iterating after the first block without reaching the end.
+	iter := b.ForEach(context.Background())
+	if _, err := iter.Next(); err != nil {
+		t.Fatalf("Should be able to read the first block: %s", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		if err := b.Reset(); err != nil {
+			done <- err
+			return
+		}
+		done <- b.Close()
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Should be able to reset and close: %s", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Should not block reset and close with an abandoned iterator.")
+	}
+}
@@ -0,0 +1,194 @@
+// Package cache implements a decorator for any storage implementation that
+// keeps the most recently read blocks in memory. This saves reading and
+// decoding the same recent blocks over and over during validation and
+// reorgs.
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+
+	"github.com/andrewyang17/blockchain/foundation/blockchain/database"
+)
+
+// Cache represents a storage implementation that serves blocks from a least
+// recently used cache and delegates everything else to the inner storage.
+// This implements the database.Storage interface.
+type Cache struct {
+	inner database.Storage
+	size  int
+
+	mu         sync.Mutex
+	blocks     map[uint64]*list.Element
+	order      *list.List
+	generation uint64
+}
+
+// New constructs a Cache value that wraps the specified storage and keeps up
+// to size blocks. A size of zero or less turns off the caching.
+func New(inner database.Storage, size int) *Cache {
+	return &Cache{
+		inner:  inner,
+		size:   size,
+		blocks: make(map[uint64]*list.Element),
+		order:  list.New(),
+	}
+}
+
+// Close closes the inner storage.
+func (c *Cache) Close() error {
+	return c.inner.Close()
+}
+
+// Write writes the block to the inner storage and drops any cached copy of
+// the block number, since a write can replace a block during a reorg.
+func (c *Cache) Write(ctx context.Context, blockData database.BlockData) error {
+	defer c.invalidate(blockData.Header.Number)
+
+	return c.inner.Write(ctx, blockData)
+}
+
+// WriteBatch writes the blocks to the inner storage and drops any cached
+// copies of the block numbers.
+func (c *Cache) WriteBatch(ctx context.Context, blocks []database.BlockData) error {
+	nums := make([]uint64, len(blocks))
+	for i, blockData := range blocks {
+		nums[i] = blockData.Header.Number
+	}
+	defer c.invalidate(nums...)
+
+	return c.inner.WriteBatch(ctx, blocks)
+}
+
+// GetBlock returns the block from the cache, reading it from the inner
+// storage and caching it when it's not there.
+func (c *Cache) GetBlock(ctx context.Context, num uint64) (database.BlockData, error) {
+	if err := ctx.Err(); err != nil {
+		return database.BlockData{}, err
+	}
+
+	blockData, generation, exists := c.get(num)
+	if exists {
+		return blockData, nil
+	}
+
+	blockData, err := c.inner.GetBlock(ctx, num)
+	if err != nil {
+		return database.BlockData{}, err
+	}
+
+	c.add(blockData, generation)
+
+	return blockData, nil
+}
+
+// GetBlockByHash reads the block from the inner storage, which knows how to
+// find a block by hash, and caches it by number.
+func (c *Cache) GetBlockByHash(ctx context.Context, hash string) (database.BlockData, error) {
+	c.mu.Lock()
+	generation := c.generation
+	c.mu.Unlock()
+
+	blockData, err := c.inner.GetBlockByHash(ctx, hash)
+	if err != nil {
+		return database.BlockData{}, err
+	}
+
+	c.add(blockData, generation)
+
+	return blockData, nil
+}
+
+// ForEach returns the iterator from the inner storage. Walking the chain
+// would push every recent block out of the cache.
+func (c *Cache) ForEach(ctx context.Context) database.Iterator {
+	return c.inner.ForEach(ctx)
+}
+
+// Healthy checks the inner storage.
+func (c *Cache) Healthy(ctx context.Context) error {
+	return c.inner.Healthy(ctx)
+}
+
+// Reset clears out the inner storage and the cache.
+func (c *Cache) Reset() error {
+	defer func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		{
+			c.blocks = make(map[uint64]*list.Element)
+			c.order.Init()
+			c.generation++
+		}
+	}()
+
+	return c.inner.Reset()
+}
+
+// =============================================================================
+
+// get returns a copy of the cached block and marks it as the most recently
+// used. The current generation is returned so a block read from the inner
+// storage after a miss is only cached if nothing was written in between.
+func (c *Cache) get(num uint64) (database.BlockData, uint64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	{
+		elem, exists := c.blocks[num]
+		if !exists {
+			return database.BlockData{}, c.generation, false
+		}
+
+		c.order.MoveToFront(elem)
+
+		return elem.Value.(database.BlockData).Copy(), c.generation, true
+	}
+}
+
+// add caches a copy of the block, dropping the least recently used block
+// when the cache is full. Nothing is cached if a write or reset happened
+// since the specified generation, because the block may be stale.
+func (c *Cache) add(blockData database.BlockData, generation uint64) {
+	if c.size <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	{
+		if generation != c.generation {
+			return
+		}
+
+		num := blockData.Header.Number
+		if elem, exists := c.blocks[num]; exists {
+			elem.Value = blockData.Copy()
+			c.order.MoveToFront(elem)
+			return
+		}
+
+		c.blocks[num] = c.order.PushFront(blockData.Copy())
+
+		if c.order.Len() > c.size {
+			oldest := c.order.Back()
+			c.order.Remove(oldest)
+			delete(c.blocks, oldest.Value.(database.BlockData).Header.Number)
+		}
+	}
+}
+
+// invalidate drops the specified block numbers from the cache.
+func (c *Cache) invalidate(nums ...uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	{
+		for _, num := range nums {
+			if elem, exists := c.blocks[num]; exists {
+				c.order.Remove(elem)
+				delete(c.blocks, num)
+			}
+		}
+		c.generation++
+	}
+}
@@ -0,0 +1,111 @@
+package cache_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/andrewyang17/blockchain/foundation/blockchain/database"
+	"github.com/andrewyang17/blockchain/foundation/blockchain/storage/cache"
+	"github.com/andrewyang17/blockchain/foundation/blockchain/storage/memory"
+)
+
+// counting wraps a storage and counts the blocks read from it.
+type counting struct {
+	*memory.Memory
+	reads int
+}
+
+func (c *counting) GetBlock(ctx context.Context, num uint64) (database.BlockData, error) {
+	c.reads++
+	return c.Memory.GetBlock(ctx, num)
+}
+
+func Test_GetBlock(t *testing.T) {
+	inner := counting{Memory: memory.New()}
+	strg := cache.New(&inner, 2)
+
+	for i := uint64(1); i <= 3; i++ {
+		blockData := database.BlockData{Hash: "0x01", Header: database.BlockHeader{Number: i}}
+		if err := strg.Write(context.Background(), blockData); err != nil {
+			t.Fatalf("Should be able to write block %d: %s", i, err)
+		}
+	}
+
+	for i := 0; i < 2; i++ {
+		got, err := strg.GetBlock(context.Background(), 1)
+		if err != nil {
+			t.Fatalf("Should be able to read the block: %s", err)
+		}
+		if got.Header.Number != 1 {
+			t.Fatalf("Should read block 1, got %d", got.Header.Number)
+		}
+	}
+
+	if inner.reads != 1 {
+		t.Fatalf("Should read the inner storage once for the same block, got %d", inner.reads)
+	}
+
+	// Reading two more blocks pushes block 1 out of a cache of size 2.
+	for _, num := range []uint64{2, 3, 1} {
+		if _, err := strg.GetBlock(context.Background(), num); err != nil {
+			t.Fatalf("Should be able to read block %d: %s", num, err)
+		}
+	}
+
+	if inner.reads != 4 {
+		t.Fatalf("Should read the least recently used block again, got %d reads", inner.reads)
+	}
+
+	// Writing a block replaces the cached copy.
+	replaced := database.BlockData{Hash: "0x02", Header: database.BlockHeader{Number: 1}}
+	if err := strg.Write(context.Background(), replaced); err != nil {
+		t.Fatalf("Should be able to write the block: %s", err)
+	}
+
+	got, err := strg.GetBlock(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("Should be able to read the block: %s", err)
+	}
+	if got.Hash != "0x02" {
+		t.Fatalf("Should read the rewritten block, got hash %s", got.Hash)
+	}
+
+	// Resetting clears the cache along with the inner storage.
+	if err := strg.Reset(); err != nil {
+		t.Fatalf("Should be able to reset the storage: %s", err)
+	}
+
+	if _, err := strg.GetBlock(context.Background(), 1); err == nil {
+		t.Fatalf("Should not be able to read a block after a reset.")
+	}
+}
+
+func Test_Concurrency(t *testing.T) {
+	strg := cache.New(memory.New(), 4)
+
+	for i := uint64(1); i <= 8; i++ {
+		if err := strg.Write(context.Background(), database.BlockData{Header: database.BlockHeader{Number: i}}); err != nil {
+			t.Fatalf("Should be able to write block %d: %s", i, err)
+		}
+	}
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := uint64(1); i <= 8; i++ {
+				num := (i+uint64(g))%8 + 1
+				if g%2 == 0 {
+					strg.Write(context.Background(), database.BlockData{Header: database.BlockHeader{Number: num}})
+					continue
+				}
+				if got, err := strg.GetBlock(context.Background(), num); err != nil || got.Header.Number != num {
+					t.Errorf("Should be able to read block %d: got %d, %v", num, got.Header.Number, err)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+}
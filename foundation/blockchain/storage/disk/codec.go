@@ -0,0 +1,139 @@
+package disk
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/andrewyang17/blockchain/foundation/blockchain/database"
+)
+
+// Set of codecs that can be used to encode the block files.
+const (
+	CodecJSON = "json"
+	CodecGob  = "gob"
+)
+
+var codecs = map[string]Codec{
+	CodecJSON: JSON{},
+	CodecGob:  Gob{},
+}
+
+// Codec defines the behavior required to encode and decode a block for
+// storage on disk. The extension is used to name the block files, which
+// means a directory can only be read with the codec that wrote it.
+type Codec interface {
+	Encode(blockData database.BlockData) ([]byte, error)
+	Decode(data []byte) (database.BlockData, error)
+	Ext() string
+}
+
+// headerDecoder is implemented by codecs that can decode the hash and header
+// of a block without decoding the transactions.
+type headerDecoder interface {
+	DecodeHeader(data []byte) (string, database.BlockHeader, error)
+}
+
+// decodeHeader returns the hash and header of the encoded block, falling
+// back to a full decode when the codec can't decode the header alone.
+func decodeHeader(codec Codec, data []byte) (string, database.BlockHeader, error) {
+	if hd, ok := codec.(headerDecoder); ok {
+		return hd.DecodeHeader(data)
+	}
+
+	blockData, err := codec.Decode(data)
+	if err != nil {
+		return "", database.BlockHeader{}, err
+	}
+	return blockData.Hash, blockData.Header, nil
+}
+
+// RetrieveCodec returns the specified codec.
+func RetrieveCodec(codec string) (Codec, error) {
+	c, exists := codecs[strings.ToLower(codec)]
+	if !exists {
+		return nil, fmt.Errorf("codec %q does not exist", codec)
+	}
+	return c, nil
+}
+
+// =============================================================================
+
+// JSON encodes blocks as indented JSON. This is the default codec. Compact
+// drops the indentation, which makes the files about half the size.
+type JSON struct {
+	Compact bool
+}
+
+// Encode marshals the block into JSON, indented unless compact.
+func (j JSON) Encode(blockData database.BlockData) ([]byte, error) {
+	fb := toFileBlock(blockData)
+	if j.Compact {
+		return json.Marshal(fb)
+	}
+	return json.MarshalIndent(fb, "", "  ")
+}
+
+// Decode unmarshals the JSON into a block.
+func (JSON) Decode(data []byte) (database.BlockData, error) {
+	var fb fileBlock
+	if err := json.Unmarshal(data, &fb); err != nil {
+		return database.BlockData{}, err
+	}
+	return toBlockData(fb)
+}
+
+// DecodeHeader unmarshals only the hash and header from the JSON.
+func (JSON) DecodeHeader(data []byte) (string, database.BlockHeader, error) {
+	var fh fileHeader
+	if err := json.Unmarshal(data, &fh); err != nil {
+		return "", database.BlockHeader{}, err
+	}
+	return toHeader(fh)
+}
+
+// Ext returns the file extension for JSON block files.
+func (JSON) Ext() string {
+	return ".json"
+}
+
+// =============================================================================
+
+// Gob encodes blocks using the encoding/gob package, which is smaller and
+// faster than JSON.
+type Gob struct{}
+
+// Encode marshals the block using gob.
+func (Gob) Encode(blockData database.BlockData) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(toFileBlock(blockData)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Decode unmarshals the gob data into a block.
+func (Gob) Decode(data []byte) (database.BlockData, error) {
+	var fb fileBlock
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&fb); err != nil {
+		return database.BlockData{}, err
+	}
+	return toBlockData(fb)
+}
+
+// DecodeHeader unmarshals only the hash and header from the gob data. Gob
+// skips the fields missing from the destination.
+func (Gob) DecodeHeader(data []byte) (string, database.BlockHeader, error) {
+	var fh fileHeader
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&fh); err != nil {
+		return "", database.BlockHeader{}, err
+	}
+	return toHeader(fh)
+}
+
+// Ext returns the file extension for gob block files.
+func (Gob) Ext() string {
+	return ".gob"
+}
@@ -0,0 +1,144 @@
+package disk_test
+
+import (
+	"context"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/andrewyang17/blockchain/foundation/blockchain/database"
+	"github.com/andrewyang17/blockchain/foundation/blockchain/storage/disk"
+)
+
+func Test_Codecs(t *testing.T) {
+	header := database.BlockHeader{
+		Number:        2,
+		PrevBlockHash: "0x00000b5c8a0ed3a2b0e9b4b1ad49c3afbf8cd5c2f4db3e0a37e0a0dbd3e4b5f6",
+		TimeStamp:     1662145563,
+		BeneficiaryID: "0xF01813E4B85e178A83e29B8E7bF26BD830a25f32",
+		Difficulty:    6,
+		MiningReward:  700,
+		Nonce:         4357438235,
+	}
+
+	trans := []database.BlockTx{
+		{
+			SignedTx: database.SignedTx{
+				Tx: database.Tx{
+					ChainID: 1,
+					Nonce:   1,
+					FromID:  "0xF01813E4B85e178A83e29B8E7bF26BD830a25f32",
+					ToID:    "0xbEE6ACE826eC3DE1B6349888B9151B92522F7F76",
+					Value:   100,
+					Tip:     10,
+					Data:    []byte("data"),
+				},
+				V: big.NewInt(29),
+				R: big.NewInt(1234567890),
+				S: big.NewInt(987654321),
+			},
+			TimeStamp: 1662145563000,
+			GasPrice:  15,
+			GasUnits:  1,
+		},
+	}
+	blockData := newBlockDataTrans(t, header, trans)
+
+	for _, name := range []string{disk.CodecJSON, disk.CodecGob} {
+		t.Run(name, func(t *testing.T) {
+			codec, err := disk.RetrieveCodec(name)
+			if err != nil {
+				t.Fatalf("Should be able to retrieve the codec: %s", err)
+			}
+
+			data, err := codec.Encode(blockData)
+			if err != nil {
+				t.Fatalf("Should be able to encode the block: %s", err)
+			}
+
+			got, err := codec.Decode(data)
+			if err != nil {
+				t.Fatalf("Should be able to decode the block: %s", err)
+			}
+
+			if !reflect.DeepEqual(got, blockData) {
+				t.Fatalf("Should get back the same block:\ngot %+v\nexp %+v", got, blockData)
+			}
+
+			// The block must also survive a trip through the disk storage.
+			d, err := disk.NewWithCodec(t.TempDir(), codec, false)
+			if err != nil {
+				t.Fatalf("Should be able to construct disk storage: %s", err)
+			}
+
+			if err := d.Write(context.Background(), blockData); err != nil {
+				t.Fatalf("Should be able to write the block: %s", err)
+			}
+
+			var blocks int
+			iter := d.ForEach(context.Background())
+			for got, err := iter.Next(); !iter.Done(); got, err = iter.Next() {
+				if err != nil {
+					t.Fatalf("Should be able to iterate the blocks: %s", err)
+				}
+				if !reflect.DeepEqual(got, blockData) {
+					t.Fatalf("Should read back the same block:\ngot %+v\nexp %+v", got, blockData)
+				}
+				blocks++
+			}
+			if blocks != 1 {
+				t.Fatalf("Should iterate over 1 block, got %d", blocks)
+			}
+		})
+	}
+
+	if _, err := disk.RetrieveCodec("xml"); err == nil {
+		t.Fatal("Should not be able to retrieve an unknown codec.")
+	}
+}
+
+func Test_Compact(t *testing.T) {
+	blockData := newBlockDataTrans(t, database.BlockHeader{Number: 1, MiningReward: 700}, []database.BlockTx{
+		{SignedTx: database.SignedTx{Tx: database.Tx{ChainID: 1, Nonce: 1, Value: 100, Tip: 10}, V: big.NewInt(29)}},
+	})
+
+	sizes := make(map[bool]int64)
+	for _, compact := range []bool{false, true} {
+		dbPath := t.TempDir()
+
+		d, err := disk.NewWithOptions(dbPath, disk.Options{Compact: compact})
+		if err != nil {
+			t.Fatalf("Should be able to construct disk storage: %s", err)
+		}
+
+		if err := d.Write(context.Background(), blockData); err != nil {
+			t.Fatalf("Should be able to write the block: %s", err)
+		}
+
+		_, size, err := d.Usage()
+		if err != nil {
+			t.Fatalf("Should be able to get the usage: %s", err)
+		}
+		sizes[compact] = size
+
+		// Blocks are read back the same no matter how they were written.
+		for _, readCompact := range []bool{false, true} {
+			r, err := disk.NewWithOptions(dbPath, disk.Options{Compact: readCompact})
+			if err != nil {
+				t.Fatalf("Should be able to construct disk storage: %s", err)
+			}
+
+			got, err := r.GetBlock(context.Background(), 1)
+			if err != nil {
+				t.Fatalf("Should be able to read the block: %s", err)
+			}
+			if !reflect.DeepEqual(got, blockData) {
+				t.Fatalf("Should read back the same block:\ngot %+v\nexp %+v", got, blockData)
+			}
+		}
+	}
+
+	if sizes[true] >= sizes[false] {
+		t.Fatalf("Should write a smaller file in compact mode: compact %d, indented %d", sizes[true], sizes[false])
+	}
+}
@@ -0,0 +1,65 @@
+package disk
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// syncCursorFile is the name of the sidecar file in the database directory
+// holding the number of the last block synced from peers.
+const syncCursorFile = "sync.cursor"
+
+// SyncCursor returns the number of the last block synced from peers. Zero is
+// returned when no cursor has been saved. The cursor is removed by Reset.
+func (d *Disk) SyncCursor() (uint64, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	data, err := os.ReadFile(path.Join(d.dbPath, syncCursorFile))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	num, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("decoding sync cursor: %w", err)
+	}
+
+	return num, nil
+}
+
+// SetSyncCursor saves the number of the last block synced from peers. The
+// cursor is written to a temporary file and renamed into place, so a crash
+// leaves either the old or the new cursor behind.
+func (d *Disk) SetSyncCursor(num uint64) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	cursorPath := path.Join(d.dbPath, syncCursorFile)
+	tmpPath := cursorPath + ".tmp"
+
+	f, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, d.fileMode)
+	if err != nil {
+		return err
+	}
+
+	if err := writeSync(f, d.fileMode, []byte(strconv.FormatUint(num, 10))); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	if err := os.Rename(tmpPath, cursorPath); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,56 @@
+package disk_test
+
+import (
+	"testing"
+
+	"github.com/andrewyang17/blockchain/foundation/blockchain/storage/disk"
+)
+
+func Test_SyncCursor(t *testing.T) {
+	dbPath := t.TempDir()
+
+	d, err := disk.New(dbPath)
+	if err != nil {
+		t.Fatalf("Should be able to construct disk storage: %s", err)
+	}
+
+	cursor, err := d.SyncCursor()
+	if err != nil {
+		t.Fatalf("Should be able to read a missing cursor: %s", err)
+	}
+	if cursor != 0 {
+		t.Fatalf("Should get zero for a missing cursor: got %d", cursor)
+	}
+
+	for _, num := range []uint64{42, 43} {
+		if err := d.SetSyncCursor(num); err != nil {
+			t.Fatalf("Should be able to save the cursor: %s", err)
+		}
+
+		// A fresh value on the same path must see the saved cursor.
+		fresh, err := disk.New(dbPath)
+		if err != nil {
+			t.Fatalf("Should be able to construct disk storage again: %s", err)
+		}
+
+		cursor, err := fresh.SyncCursor()
+		if err != nil {
+			t.Fatalf("Should be able to read the cursor: %s", err)
+		}
+		if cursor != num {
+			t.Fatalf("Should read back the saved cursor: got %d, exp %d", cursor, num)
+		}
+	}
+
+	if err := d.Reset(); err != nil {
+		t.Fatalf("Should be able to reset the storage: %s", err)
+	}
+
+	cursor, err = d.SyncCursor()
+	if err != nil {
+		t.Fatalf("Should be able to read the cursor after a reset: %s", err)
+	}
+	if cursor != 0 {
+		t.Fatalf("Should remove the cursor on reset: got %d", cursor)
+	}
+}
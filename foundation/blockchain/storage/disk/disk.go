@@ -1,33 +1,154 @@
 // Package disk implements the ability to read and write blocks to disk
-// writing each block to a separate block numbered file.
+// writing each block to a separate block numbered file. Block files are
+// encoded with a codec and can optionally be gzip compressed.
 package disk
 
 import (
-	"encoding/json"
+	"bytes"
+	"compress/gzip"
+	"context"
 	"errors"
 	"fmt"
+	"io"
 	"io/fs"
 	"os"
 	"path"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/andrewyang17/blockchain/foundation/blockchain/database"
 )
 
+// gzipExt is the file extension added to compressed block files.
+const gzipExt = ".gz"
+
+// healthFile is the name of the probe file written and removed in the
+// database directory to check the storage is writable.
+const healthFile = ".health"
+
+// Set of default permissions for the block files and the directory.
+const (
+	defaultFileMode fs.FileMode = 0600
+	defaultDirMode  fs.FileMode = 0755
+)
+
+// Set of error variables for reading blocks from disk.
+var (
+	ErrEmptyChain   = errors.New("no blocks on disk")
+	ErrCorruptBlock = errors.New("corrupt block")
+)
+
+// blockFile represents a block written to a temporary file that is waiting
+// to be moved into place.
+type blockFile struct {
+	number    uint64
+	path      string
+	otherPath string
+	tmpPath   string
+}
+
 // Disk represents the serialization implementation for reading and storing
 // blocks in their own separate files on disk. THis implements the database.Storage
 // interface.
 type Disk struct {
-	dbPath string
+	mu       sync.RWMutex
+	dbPath   string
+	codec    Codec
+	compress bool
+	fileMode fs.FileMode
+	dirMode  fs.FileMode
+
+	// hashIndex maps a block hash to its block number. It is built on the
+	// first call to GetBlockByHash and maintained by Write and Reset.
+	indexMu   sync.Mutex
+	hashIndex map[string]uint64
+
+	// accountIndex maps an account to the transactions it appears in. It is
+	// only maintained when turned on in the options.
+	accountIndex *accountIndex
+
+	// wal turns on the write ahead log, which records each block write so
+	// Recover can settle a write interrupted by a crash. The log is cleared
+	// once no block write begun by this value is left uncommitted.
+	wal        bool
+	walMu      sync.Mutex
+	walPending int
 }
 
 // New constructs a Disk value for use.
 func New(dbPath string) (*Disk, error) {
-	if err := os.MkdirAll(dbPath, 0755); err != nil {
+	return NewWithCompression(dbPath, false)
+}
+
+// NewWithCompression constructs a Disk value for use that writes gzip
+// compressed block files when compress is true. Blocks are read based on the
+// file extension, so a directory with both kinds of files can be read.
+func NewWithCompression(dbPath string, compress bool) (*Disk, error) {
+	return NewWithCodec(dbPath, JSON{}, compress)
+}
+
+// NewWithCodec constructs a Disk value for use that encodes the block files
+// with the specified codec.
+func NewWithCodec(dbPath string, codec Codec, compress bool) (*Disk, error) {
+	return NewWithOptions(dbPath, Options{Codec: codec, Compress: compress})
+}
+
+// Options represents the settings for constructing a Disk value. The zero
+// value of each field selects the default.
+type Options struct {
+	Codec    Codec       // Encoding for the block files, defaults to JSON.
+	Compress bool        // Gzip compress the block files.
+	Compact  bool        // Write JSON block files without indentation.
+	FileMode fs.FileMode // Permissions for the block files, defaults to 0600.
+	DirMode  fs.FileMode // Permissions for the directory, defaults to 0755.
+
+	// AccountIndex maintains an index of the transactions for each account
+	// in a sidecar file, which is rebuilt from the blocks if it's missing.
+	AccountIndex bool
+
+	// WAL records each block write in a write ahead log, so a write cut
+	// short by a crash can be settled by Recover at startup.
+	WAL bool
+}
+
+// NewWithOptions constructs a Disk value for use with the specified options.
+func NewWithOptions(dbPath string, opts Options) (*Disk, error) {
+	if opts.Codec == nil {
+		opts.Codec = JSON{}
+	}
+	if codec, ok := opts.Codec.(JSON); ok && opts.Compact {
+		codec.Compact = true
+		opts.Codec = codec
+	}
+	if opts.FileMode == 0 {
+		opts.FileMode = defaultFileMode
+	}
+	if opts.DirMode == 0 {
+		opts.DirMode = defaultDirMode
+	}
+
+	if err := os.MkdirAll(dbPath, opts.DirMode); err != nil {
 		return nil, err
 	}
 
-	return &Disk{dbPath: dbPath}, nil
+	d := Disk{
+		dbPath:   dbPath,
+		codec:    opts.Codec,
+		compress: opts.Compress,
+		fileMode: opts.FileMode,
+		dirMode:  opts.DirMode,
+		wal:      opts.WAL,
+	}
+
+	if opts.AccountIndex {
+		if err := d.loadAccountIndex(); err != nil {
+			return nil, err
+		}
+	}
+
+	return &d, nil
 }
 
 // Close in this implementation has nothing to do since a new file is
@@ -37,63 +158,720 @@ func (d *Disk) Close() error {
 }
 
 // Write takes the specified database blocks and stores it on disk in a
-// file labeled with the block number.
-func (d *Disk) Write(blockData database.BlockData) error {
-	data, err := json.MarshalIndent(blockData, "", "  ")
-	if err != nil {
-		return nil
+// file labeled with the block number. The block is written to a temporary
+// file first and then renamed into place, so a crash in the middle of a
+// write never leaves a truncated block file behind.
+func (d *Disk) Write(ctx context.Context, blockData database.BlockData) error {
+	if err := ctx.Err(); err != nil {
+		return err
 	}
 
-	// Create a new file for this block and name it based on the block number.
-	f, err := os.OpenFile(d.getPath(blockData.Header.Number), os.O_CREATE|os.O_RDWR, 0600)
+	path, otherPath, data, err := d.encode(blockData)
 	if err != nil {
 		return err
 	}
-	defer f.Close()
 
-	if _, err := f.Write(data); err != nil {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	blocks := []database.BlockData{blockData}
+
+	if d.wal {
+		if err := d.logBegin(blocks); err != nil {
+			return err
+		}
+	}
+
+	if err := d.writeFile(path, otherPath, data); err != nil {
+		return err
+	}
+
+	d.indexBlocks(blocks)
+
+	if err := d.indexAccounts(blocks); err != nil {
+		return err
+	}
+
+	if d.wal {
+		return d.logCommit(blocks)
+	}
+
+	return nil
+}
+
+// WriteBatch takes the specified database blocks and stores them on disk.
+// The blocks are written to temporary files concurrently and only moved into
+// place once every file has been written, so a failure while writing leaves
+// the chain on disk untouched. The error names the block that failed and no
+// blocks after it are moved into place. A cancelled context stops the
+// remaining files from being written and leaves the chain untouched.
+func (d *Disk) WriteBatch(ctx context.Context, blocks []database.BlockData) error {
+	files := make([]blockFile, len(blocks))
+	errs := make([]error, len(blocks))
+
+	// Limit the number of files being written at the same time.
+	const maxWorkers = 8
+	sem := make(chan struct{}, maxWorkers)
+
+	var wg sync.WaitGroup
+	wg.Add(len(blocks))
+	for i := range blocks {
+		sem <- struct{}{}
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			if err := ctx.Err(); err != nil {
+				errs[i] = err
+				return
+			}
+
+			path, otherPath, data, err := d.encode(blocks[i])
+			if err != nil {
+				errs[i] = err
+				return
+			}
+
+			f, err := os.CreateTemp(d.dbPath, filepath.Base(path)+".*.tmp")
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			files[i] = blockFile{number: blocks[i].Header.Number, path: path, otherPath: otherPath, tmpPath: f.Name()}
+
+			errs[i] = writeSync(f, d.fileMode, data)
+		}(i)
+	}
+	wg.Wait()
+
+	removeTmp := func() {
+		for _, file := range files {
+			if file.tmpPath != "" {
+				os.Remove(file.tmpPath)
+			}
+		}
+	}
+
+	for i, err := range errs {
+		if err != nil {
+			removeTmp()
+			return fmt.Errorf("block %d: %w", blocks[i].Header.Number, err)
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		removeTmp()
 		return err
 	}
 
+	if d.wal {
+		if err := d.logBegin(blocks); err != nil {
+			removeTmp()
+			return err
+		}
+	}
+
+	if err := d.renameFiles(files); err != nil {
+		removeTmp()
+		return err
+	}
+
+	d.indexBlocks(blocks)
+
+	if err := d.indexAccounts(blocks); err != nil {
+		return err
+	}
+
+	if d.wal {
+		return d.logCommit(blocks)
+	}
+
 	return nil
 }
 
 // GetBlock searches the blockchain on disk to locate and return the
-// contents of the specified block by number.
-func (d *Disk) GetBlock(num uint64) (database.BlockData, error) {
-	f, err := os.OpenFile(d.getPath(num), os.O_RDONLY, 0600)
+// contents of the specified block by number. An error wrapping ErrCorruptBlock
+// is returned when the block can't be decoded or its hash doesn't match.
+func (d *Disk) GetBlock(ctx context.Context, num uint64) (database.BlockData, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	return d.getBlock(ctx, num)
+}
+
+// getBlock reads the specified block from disk. The caller must hold
+// the read lock.
+func (d *Disk) getBlock(ctx context.Context, num uint64) (database.BlockData, error) {
+	data, err := d.readBlock(ctx, num)
 	if err != nil {
 		return database.BlockData{}, err
 	}
+
+	blockData, err := d.codec.Decode(data)
+	if err != nil {
+		if errors.Is(err, ErrUnsupportedFormat) {
+			return database.BlockData{}, fmt.Errorf("block %d: %w", num, err)
+		}
+		return database.BlockData{}, fmt.Errorf("block %d: %s: %w", num, err, ErrCorruptBlock)
+	}
+
+	// Recompute the hashes of the block to make sure the file wasn't changed
+	// after the block was written.
+	if err := validateBlock(blockData); err != nil {
+		return database.BlockData{}, fmt.Errorf("block %d: %s: %w", num, err, ErrCorruptBlock)
+	}
+
+	return blockData, nil
+}
+
+// getHeader reads the specified block from disk and decodes only the
+// header. The caller must hold the read lock.
+func (d *Disk) getHeader(ctx context.Context, num uint64) (database.BlockHeader, error) {
+	data, err := d.readBlock(ctx, num)
+	if err != nil {
+		return database.BlockHeader{}, err
+	}
+
+	hash, header, err := decodeHeader(d.codec, data)
+	if err != nil {
+		if errors.Is(err, ErrUnsupportedFormat) {
+			return database.BlockHeader{}, fmt.Errorf("block %d: %w", num, err)
+		}
+		return database.BlockHeader{}, fmt.Errorf("block %d: %s: %w", num, err, ErrCorruptBlock)
+	}
+
+	if computed := (database.Block{Header: header}).Hash(); computed != hash {
+		return database.BlockHeader{}, fmt.Errorf("block %d: hash %s does not match header hash %s: %w", num, hash, computed, ErrCorruptBlock)
+	}
+
+	return header, nil
+}
+
+// readBlock reads the raw encoded bytes of the specified block from disk,
+// decompressing them when needed. The caller must hold the read lock.
+func (d *Disk) readBlock(ctx context.Context, num uint64) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	f, compressed, err := d.openBlock(num)
+	if err != nil {
+		return nil, err
+	}
 	defer f.Close()
 
-	var blockData database.BlockData
-	if err := json.NewDecoder(f).Decode(&blockData); err != nil {
+	var r io.Reader = f
+	if compressed {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, fmt.Errorf("block %d: %s: %w", num, err, ErrCorruptBlock)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("block %d: %s: %w", num, err, ErrCorruptBlock)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// GetBlockByHash locates and returns the contents of the block with the
+// specified hash. An error wrapping fs.ErrNotExist is returned when no block
+// on disk has this hash.
+func (d *Disk) GetBlockByHash(ctx context.Context, hash string) (database.BlockData, error) {
+	d.indexMu.Lock()
+	defer d.indexMu.Unlock()
+	{
+		if d.hashIndex == nil {
+			index, err := d.buildHashIndex(ctx)
+			if err != nil {
+				return database.BlockData{}, err
+			}
+			d.hashIndex = index
+		}
+
+		num, exists := d.hashIndex[hash]
+		if !exists {
+			return database.BlockData{}, fmt.Errorf("block hash %s: %w", hash, fs.ErrNotExist)
+		}
+
+		// The block number could have been rewritten with a different block
+		// since the index entry was added.
+		blockData, err := d.GetBlock(ctx, num)
+		if err != nil {
+			return database.BlockData{}, err
+		}
+		if blockData.Hash != hash {
+			return database.BlockData{}, fmt.Errorf("block hash %s: %w", hash, fs.ErrNotExist)
+		}
+
+		return blockData, nil
+	}
+}
+
+// LatestBlock returns the block with the highest block number on disk
+// without walking the chain.
+func (d *Disk) LatestBlock(ctx context.Context) (database.BlockData, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	head, err := d.headNumber()
+	if err != nil {
 		return database.BlockData{}, err
 	}
 
-	return blockData, nil
+	if head == 0 {
+		return database.BlockData{}, ErrEmptyChain
+	}
+
+	return d.getBlock(ctx, head)
 }
 
 // ForEach returns an iterator to walk through all the blocks
-// starting with block number 1.
-func (d *Disk) ForEach() database.Iterator {
-	return &diskIterator{storage: d}
+// starting with the lowest block number on disk.
+func (d *Disk) ForEach(ctx context.Context) database.Iterator {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	low, _, err := d.blockRange()
+	if err != nil {
+		return &diskIterator{ctx: ctx, storage: d, err: err}
+	}
+
+	// An empty chain starts at block 1 which will mark the end of the chain.
+	if low == 0 {
+		low = 1
+	}
+
+	return &diskIterator{ctx: ctx, storage: d, currentBlockNumber: low - 1}
+}
+
+// ForEachRange returns an iterator to walk through the blocks numbered from
+// the first value through the second value. Blocks past the head of the chain
+// are not returned, and the iterator is done right away when from is greater
+// than to.
+func (d *Disk) ForEachRange(ctx context.Context, from uint64, to uint64) database.Iterator {
+	if from == 0 {
+		from = 1
+	}
+
+	if from > to {
+		return &diskIterator{ctx: ctx, storage: d, endOfChain: true}
+	}
+
+	return &diskIterator{ctx: ctx, storage: d, currentBlockNumber: from - 1, lastBlockNumber: to}
+}
+
+// ForEachReverse returns an iterator to walk through all the blocks
+// starting with the highest block number on disk down to block number 1.
+func (d *Disk) ForEachReverse(ctx context.Context) database.Iterator {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	head, err := d.headNumber()
+	if err != nil {
+		return &diskReverseIterator{ctx: ctx, storage: d, err: err}
+	}
+
+	return &diskReverseIterator{ctx: ctx, storage: d, currentBlockNumber: head + 1}
+}
+
+// ForEachHeader returns an iterator to walk through the headers of all the
+// blocks starting with the lowest block number on disk. The block files are
+// still read in full, but the transactions are not returned.
+func (d *Disk) ForEachHeader(ctx context.Context) database.HeaderIterator {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	low, _, err := d.blockRange()
+	if err != nil {
+		return &diskHeaderIterator{ctx: ctx, storage: d, err: err}
+	}
+
+	// An empty chain starts at block 1 which will mark the end of the chain.
+	if low == 0 {
+		low = 1
+	}
+
+	return &diskHeaderIterator{ctx: ctx, storage: d, currentBlockNumber: low - 1}
 }
 
 // Reset will clear out the blockchain on disk.
 func (d *Disk) Reset() error {
+	d.indexMu.Lock()
+	{
+		d.hashIndex = nil
+	}
+	d.indexMu.Unlock()
+
+	d.resetAccountIndex()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
 	if err := os.RemoveAll(d.dbPath); err != nil {
 		return err
 	}
 
-	return os.MkdirAll(d.dbPath, 0755)
+	return os.MkdirAll(d.dbPath, d.dirMode)
+}
+
+// Healthy writes and removes a probe file in the database directory to check
+// the directory is still there and writable.
+func (d *Disk) Healthy(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	probePath := path.Join(d.dbPath, healthFile)
+
+	f, err := os.OpenFile(probePath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, d.fileMode)
+	if err != nil {
+		return fmt.Errorf("storage not writable: %w", err)
+	}
+
+	if err := writeSync(f, d.fileMode, []byte("ok")); err != nil {
+		os.Remove(probePath)
+		return fmt.Errorf("storage not writable: %w", err)
+	}
+
+	if err := os.Remove(probePath); err != nil {
+		return fmt.Errorf("storage not writable: %w", err)
+	}
+
+	return nil
+}
+
+// Usage walks the directory once to count the block files on disk and sum
+// their sizes in bytes.
+func (d *Disk) Usage() (int, int64, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	entries, err := os.ReadDir(d.dbPath)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var blockCount int
+	var totalBytes int64
+	for _, entry := range entries {
+		if _, ok := d.blockNumber(entry); !ok {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return 0, 0, err
+		}
+
+		blockCount++
+		totalBytes += info.Size()
+	}
+
+	return blockCount, totalBytes, nil
+}
+
+// Prune removes all the blocks on disk with a block number lower than
+// keepFromBlock, leaving the rest of the chain in place.
+func (d *Disk) Prune(keepFromBlock uint64) error {
+	if err := d.prune(keepFromBlock); err != nil {
+		return err
+	}
+
+	if err := d.pruneAccountIndex(keepFromBlock); err != nil {
+		return err
+	}
+
+	d.indexMu.Lock()
+	defer d.indexMu.Unlock()
+	{
+		for hash, num := range d.hashIndex {
+			if num < keepFromBlock {
+				delete(d.hashIndex, hash)
+			}
+		}
+	}
+
+	return nil
 }
 
-// getPath forms the path to the specified block.
+// prune removes the block files below keepFromBlock.
+func (d *Disk) prune(keepFromBlock uint64) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	entries, err := os.ReadDir(d.dbPath)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		num, ok := d.blockNumber(entry)
+		if !ok || num >= keepFromBlock {
+			continue
+		}
+
+		if err := os.Remove(path.Join(d.dbPath, entry.Name())); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// encode marshals the block into the data to store on disk and returns the
+// path to write the data to. The otherPath is the path used by the block in
+// the other format.
+func (d *Disk) encode(blockData database.BlockData) (string, string, []byte, error) {
+	data, err := d.codec.Encode(blockData)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	path := d.getPath(blockData.Header.Number)
+	otherPath := path + gzipExt
+	if d.compress {
+		if data, err = compress(data); err != nil {
+			return "", "", nil, err
+		}
+		path, otherPath = otherPath, path
+	}
+
+	return path, otherPath, data, nil
+}
+
+// renameFiles moves the temporary files into place, removing any file for
+// the same block stored in the other format.
+func (d *Disk) renameFiles(files []blockFile) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, file := range files {
+		if err := os.Rename(file.tmpPath, file.path); err != nil {
+			return fmt.Errorf("block %d: %w", file.number, err)
+		}
+
+		if err := os.Remove(file.otherPath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// indexBlocks keeps the hash index current if it has already been built.
+func (d *Disk) indexBlocks(blocks []database.BlockData) {
+	d.indexMu.Lock()
+	defer d.indexMu.Unlock()
+	{
+		if d.hashIndex == nil {
+			return
+		}
+
+		for _, blockData := range blocks {
+			d.hashIndex[blockData.Hash] = blockData.Header.Number
+		}
+	}
+}
+
+// getPath forms the path to the specified uncompressed block.
 func (d *Disk) getPath(blockNum uint64) string {
 	name := strconv.FormatUint(blockNum, 10)
-	return path.Join(d.dbPath, fmt.Sprintf("%s.json", name))
+	return path.Join(d.dbPath, fmt.Sprintf("%s%s", name, d.codec.Ext()))
+}
+
+// writeFile writes the data to a temporary file and then moves it into place
+// at the specified path. Any file at otherPath holding the same block in the
+// other format is removed.
+func (d *Disk) writeFile(path string, otherPath string, data []byte) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	tmpPath := path + ".tmp"
+
+	// Create a temporary file for this block in the same directory so the
+	// rename below is atomic.
+	f, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, d.fileMode)
+	if err != nil {
+		return err
+	}
+
+	if err := writeSync(f, d.fileMode, data); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	// Move the completed file into place under the block number.
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	// Remove a copy of this block stored in the other format so reads
+	// don't find a stale version.
+	if err := os.Remove(otherPath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+// openBlock opens the file for the specified block, looking for a compressed
+// file first. The returned bool reports if the file is compressed.
+func (d *Disk) openBlock(blockNum uint64) (*os.File, bool, error) {
+	path := d.getPath(blockNum)
+
+	f, err := os.OpenFile(path+gzipExt, os.O_RDONLY, 0600)
+	if err == nil {
+		return f, true, nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, false, err
+	}
+
+	f, err = os.OpenFile(path, os.O_RDONLY, 0600)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return f, false, nil
+}
+
+// headNumber reads the directory entries to find the highest block number
+// stored on disk. Zero is returned if there are no blocks.
+func (d *Disk) headNumber() (uint64, error) {
+	_, high, err := d.blockRange()
+	return high, err
+}
+
+// blockRange reads the directory entries to find the lowest and highest
+// block numbers stored on disk. Zeros are returned if there are no blocks.
+func (d *Disk) blockRange() (uint64, uint64, error) {
+	entries, err := os.ReadDir(d.dbPath)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var low, high uint64
+	for _, entry := range entries {
+		num, ok := d.blockNumber(entry)
+		if !ok {
+			continue
+		}
+
+		if low == 0 || num < low {
+			low = num
+		}
+		if num > high {
+			high = num
+		}
+	}
+
+	return low, high, nil
+}
+
+// blockNumber parses the block number from the name of a block file. False
+// is returned if the entry is not a block file for the codec.
+func (d *Disk) blockNumber(entry fs.DirEntry) (uint64, bool) {
+	ext := d.codec.Ext()
+
+	name := strings.TrimSuffix(entry.Name(), gzipExt)
+	if entry.IsDir() || !strings.HasSuffix(name, ext) {
+		return 0, false
+	}
+
+	num, err := strconv.ParseUint(strings.TrimSuffix(name, ext), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return num, true
+}
+
+// buildHashIndex walks the blocks on disk to map each block hash to
+// its block number.
+func (d *Disk) buildHashIndex(ctx context.Context) (map[string]uint64, error) {
+	index := make(map[string]uint64)
+
+	iter := d.ForEach(ctx)
+	for blockData, err := iter.Next(); !iter.Done(); blockData, err = iter.Next() {
+		if err != nil {
+			return nil, err
+		}
+		index[blockData.Hash] = blockData.Header.Number
+	}
+
+	return index, nil
+}
+
+// validateBlock checks the hash of the header matches the block hash and the
+// merkle root in the header matches the transactions, so a change to any
+// part of a block file is caught.
+func validateBlock(blockData database.BlockData) error {
+	if hash := (database.Block{Header: blockData.Header}).Hash(); hash != blockData.Hash {
+		return fmt.Errorf("hash %s does not match header hash %s", blockData.Hash, hash)
+	}
+
+	switch {
+	case len(blockData.Trans) > 0:
+		return blockData.ValidateHash()
+
+	case blockData.Header.TransRoot != "":
+		return errors.New("merkle root set for a block without transactions")
+	}
+
+	return nil
+}
+
+// compress returns the gzip compressed version of the data.
+func compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write(data); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// writeSync sets the permissions on the file, writes the data to the file
+// and flushes it to stable storage before closing the file. The permissions
+// are set explicitly so the umask doesn't change them.
+func writeSync(f *os.File, mode fs.FileMode, data []byte) error {
+	if err := f.Chmod(mode); err != nil {
+		f.Close()
+		return err
+	}
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
 }
 
 // =============================================================================
@@ -102,20 +880,53 @@ func (d *Disk) getPath(blockNum uint64) string {
 // through and reading blocks on disk. This implements the database
 // Iterator interface.
 type diskIterator struct {
+	ctx                context.Context
 	storage            *Disk
 	currentBlockNumber uint64
+	lastBlockNumber    uint64
 	endOfChain         bool
+	failed             bool
+	err                error
 }
 
 // Next retrieves  the next block from disk.
 func (di *diskIterator) Next() (database.BlockData, error) {
-	if di.endOfChain {
-		return database.BlockData{}, errors.New("end of chain")
+	// Report a failure to read the directory once, the next call will
+	// then mark the end of the chain.
+	if di.err != nil {
+		err := di.err
+		di.err = nil
+		di.failed = true
+		return database.BlockData{}, err
+	}
+
+	if di.endOfChain || di.failed {
+		di.endOfChain = true
+		return database.BlockData{}, database.ErrEndOfChain
+	}
+
+	// Report the context error once, the next call will then mark the
+	// end of the chain.
+	if err := di.ctx.Err(); err != nil {
+		di.failed = true
+		return database.BlockData{}, err
 	}
 	di.currentBlockNumber++
-	blockData, err := di.storage.GetBlock(di.currentBlockNumber)
+
+	// A last block number of zero means there is no upper bound.
+	if di.lastBlockNumber != 0 && di.currentBlockNumber > di.lastBlockNumber {
+		di.endOfChain = true
+		return database.BlockData{}, database.ErrEndOfChain
+	}
+
+	di.storage.mu.RLock()
+	blockData, err := di.storage.getBlock(di.ctx, di.currentBlockNumber)
+	di.storage.mu.RUnlock()
+
+	// The chain ends at the first block missing from disk.
 	if errors.Is(err, fs.ErrNotExist) {
 		di.endOfChain = true
+		return database.BlockData{}, database.ErrEndOfChain
 	}
 
 	return blockData, err
@@ -125,3 +936,116 @@ func (di *diskIterator) Next() (database.BlockData, error) {
 func (di *diskIterator) Done() bool {
 	return di.endOfChain
 }
+
+// =============================================================================
+
+// diskReverseIterator represents the iteration implementation for walking
+// backwards through and reading blocks on disk. This implements the database
+// Iterator interface.
+type diskReverseIterator struct {
+	ctx                context.Context
+	storage            *Disk
+	currentBlockNumber uint64
+	beginningOfChain   bool
+	failed             bool
+	err                error
+}
+
+// Next retrieves the previous block from disk.
+func (di *diskReverseIterator) Next() (database.BlockData, error) {
+	// Report a failure to read the directory once, the next call will
+	// then mark the beginning of the chain.
+	if di.err != nil {
+		err := di.err
+		di.err = nil
+		return database.BlockData{}, err
+	}
+
+	if di.beginningOfChain || di.failed || di.currentBlockNumber <= 1 {
+		di.beginningOfChain = true
+		return database.BlockData{}, database.ErrEndOfChain
+	}
+
+	// Report the context error once, the next call will then mark the
+	// beginning of the chain.
+	if err := di.ctx.Err(); err != nil {
+		di.failed = true
+		return database.BlockData{}, err
+	}
+	di.currentBlockNumber--
+
+	di.storage.mu.RLock()
+	blockData, err := di.storage.getBlock(di.ctx, di.currentBlockNumber)
+	di.storage.mu.RUnlock()
+
+	// A missing block means the chain was pruned below this point, which is
+	// the beginning of the chain still on disk.
+	if errors.Is(err, fs.ErrNotExist) {
+		di.beginningOfChain = true
+		return database.BlockData{}, database.ErrEndOfChain
+	}
+
+	return blockData, err
+}
+
+// Done returns the beginning of chain value. Walking backwards, the beginning
+// of the chain is the end of the iteration, so Next returns
+// database.ErrEndOfChain once Done reports true.
+func (di *diskReverseIterator) Done() bool {
+	return di.beginningOfChain
+}
+
+// =============================================================================
+
+// diskHeaderIterator represents the iteration implementation for walking
+// through the block headers on disk.
+type diskHeaderIterator struct {
+	ctx                context.Context
+	storage            *Disk
+	currentBlockNumber uint64
+	endOfChain         bool
+	failed             bool
+	err                error
+}
+
+// Next retrieves the header of the next block from disk.
+func (di *diskHeaderIterator) Next() (database.BlockHeader, error) {
+	// Report a failure to read the directory once, the next call will
+	// then mark the end of the chain.
+	if di.err != nil {
+		err := di.err
+		di.err = nil
+		di.failed = true
+		return database.BlockHeader{}, err
+	}
+
+	if di.endOfChain || di.failed {
+		di.endOfChain = true
+		return database.BlockHeader{}, database.ErrEndOfChain
+	}
+
+	// Report the context error once, the next call will then mark the
+	// end of the chain.
+	if err := di.ctx.Err(); err != nil {
+		di.failed = true
+		return database.BlockHeader{}, err
+	}
+	di.currentBlockNumber++
+
+	di.storage.mu.RLock()
+	header, err := di.storage.getHeader(di.ctx, di.currentBlockNumber)
+	di.storage.mu.RUnlock()
+
+	// The chain ends at the first block missing from disk.
+	if errors.Is(err, fs.ErrNotExist) {
+		di.endOfChain = true
+		return database.BlockHeader{}, database.ErrEndOfChain
+	}
+
+	return header, err
+}
+
+// Done returns the end of chain value.
+func (di *diskHeaderIterator) Done() bool {
+	return di.endOfChain
+}
\ No newline at end of file
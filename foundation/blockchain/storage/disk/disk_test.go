@@ -0,0 +1,1018 @@
+package disk_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/andrewyang17/blockchain/foundation/blockchain/database"
+	"github.com/andrewyang17/blockchain/foundation/blockchain/merkle"
+	"github.com/andrewyang17/blockchain/foundation/blockchain/storage/disk"
+)
+
+// newBlockData returns the block data for the header with a matching hash.
+func newBlockData(header database.BlockHeader) database.BlockData {
+	return database.BlockData{
+		Hash:   database.Block{Header: header}.Hash(),
+		Header: header,
+	}
+}
+
+// newBlockDataTrans returns the block data for the header and transactions
+// with a matching merkle root and hash.
+func newBlockDataTrans(t *testing.T, header database.BlockHeader, trans []database.BlockTx) database.BlockData {
+	tree, err := merkle.NewTree(trans)
+	if err != nil {
+		t.Fatalf("Should be able to build the merkle tree: %s", err)
+	}
+	header.TransRoot = tree.RootHex()
+
+	blockData := newBlockData(header)
+	blockData.Trans = trans
+
+	return blockData
+}
+
+func Test_StrayTempFile(t *testing.T) {
+	dbPath := t.TempDir()
+
+	d, err := disk.New(dbPath)
+	if err != nil {
+		t.Fatalf("Should be able to construct disk storage: %s", err)
+	}
+
+	blockData := newBlockData(database.BlockHeader{Number: 1, MiningReward: 700})
+	if err := d.Write(context.Background(), blockData); err != nil {
+		t.Fatalf("Should be able to write block: %s", err)
+	}
+
+	// Simulate a crash in the middle of rewriting block 1.
+	if err := os.WriteFile(filepath.Join(dbPath, "1.json.tmp"), []byte(`{"hash": "0x`), 0600); err != nil {
+		t.Fatalf("Should be able to write the stray temp file: %s", err)
+	}
+
+	got, err := d.GetBlock(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("Should be able to read block 1: %s", err)
+	}
+	if got.Header != blockData.Header {
+		t.Fatalf("Should get back the previous good block: got %+v, exp %+v", got.Header, blockData.Header)
+	}
+
+	var blocks int
+	iter := d.ForEach(context.Background())
+	for _, err := iter.Next(); !iter.Done(); _, err = iter.Next() {
+		if err != nil {
+			t.Fatalf("Should be able to iterate the blocks: %s", err)
+		}
+		blocks++
+	}
+	if blocks != 1 {
+		t.Fatalf("Should iterate over 1 block, got %d", blocks)
+	}
+}
+
+func Test_GetBlockByHash(t *testing.T) {
+	d, err := disk.New(t.TempDir())
+	if err != nil {
+		t.Fatalf("Should be able to construct disk storage: %s", err)
+	}
+
+	var hashes []string
+	for i := uint64(1); i <= 4; i++ {
+		blockData := newBlockData(database.BlockHeader{Number: i})
+		hashes = append(hashes, blockData.Hash)
+		if i == 4 {
+			continue
+		}
+		if err := d.Write(context.Background(), blockData); err != nil {
+			t.Fatalf("Should be able to write block %d: %s", i, err)
+		}
+	}
+
+	got, err := d.GetBlockByHash(context.Background(), hashes[1])
+	if err != nil {
+		t.Fatalf("Should be able to find block by hash: %s", err)
+	}
+	if got.Header.Number != 2 {
+		t.Fatalf("Should get back block 2, got %d", got.Header.Number)
+	}
+
+	// Blocks written after the index is built must be found as well.
+	if err := d.Write(context.Background(), newBlockData(database.BlockHeader{Number: 4})); err != nil {
+		t.Fatalf("Should be able to write block 4: %s", err)
+	}
+	if _, err := d.GetBlockByHash(context.Background(), hashes[3]); err != nil {
+		t.Fatalf("Should be able to find a new block by hash: %s", err)
+	}
+
+	if _, err := d.GetBlockByHash(context.Background(), fmt.Sprintf("0x%064d", 9)); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Should get fs.ErrNotExist for an unknown hash, got %v", err)
+	}
+}
+
+func Test_ForEachReverse(t *testing.T) {
+	d, err := disk.New(t.TempDir())
+	if err != nil {
+		t.Fatalf("Should be able to construct disk storage: %s", err)
+	}
+
+	for i := uint64(1); i <= 3; i++ {
+		if err := d.Write(context.Background(), newBlockData(database.BlockHeader{Number: i})); err != nil {
+			t.Fatalf("Should be able to write block %d: %s", i, err)
+		}
+	}
+
+	var got []uint64
+	iter := d.ForEachReverse(context.Background())
+	for blockData, err := iter.Next(); !iter.Done(); blockData, err = iter.Next() {
+		if err != nil {
+			t.Fatalf("Should be able to iterate the blocks: %s", err)
+		}
+		got = append(got, blockData.Header.Number)
+	}
+
+	exp := []uint64{3, 2, 1}
+	if fmt.Sprint(got) != fmt.Sprint(exp) {
+		t.Fatalf("Should walk the chain from the head: got %v, exp %v", got, exp)
+	}
+
+	if _, err := iter.Next(); !errors.Is(err, database.ErrEndOfChain) {
+		t.Fatalf("Should get ErrEndOfChain once the beginning of the chain is passed, got %v", err)
+	}
+}
+
+func Test_EndOfChain(t *testing.T) {
+	d, err := disk.New(t.TempDir())
+	if err != nil {
+		t.Fatalf("Should be able to construct disk storage: %s", err)
+	}
+
+	for i := uint64(1); i <= 2; i++ {
+		if err := d.Write(context.Background(), newBlockData(database.BlockHeader{Number: i})); err != nil {
+			t.Fatalf("Should be able to write block %d: %s", i, err)
+		}
+	}
+
+	iter := d.ForEach(context.Background())
+	for i := 0; i < 2; i++ {
+		if _, err := iter.Next(); err != nil {
+			t.Fatalf("Should be able to read block %d: %s", i+1, err)
+		}
+	}
+
+	// The first call past the last block finds the missing block file and
+	// every call after that is already at the end.
+	for i := 0; i < 2; i++ {
+		if _, err := iter.Next(); !errors.Is(err, database.ErrEndOfChain) {
+			t.Fatalf("Should get ErrEndOfChain once the iterator is exhausted, got %v", err)
+		}
+		if !iter.Done() {
+			t.Fatalf("Should be done once the iterator is exhausted.")
+		}
+	}
+
+	headers := d.ForEachHeader(context.Background())
+	for header, err := headers.Next(); !headers.Done(); header, err = headers.Next() {
+		if err != nil {
+			t.Fatalf("Should be able to read header %d: %s", header.Number, err)
+		}
+	}
+	if _, err := headers.Next(); !errors.Is(err, database.ErrEndOfChain) {
+		t.Fatalf("Should get ErrEndOfChain once the header iterator is exhausted, got %v", err)
+	}
+
+	// A cancelled context is a real error and not the end of the chain.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	iter = d.ForEach(ctx)
+	if _, err := iter.Next(); errors.Is(err, database.ErrEndOfChain) || !errors.Is(err, context.Canceled) {
+		t.Fatalf("Should get the context error from a cancelled iteration, got %v", err)
+	}
+}
+
+func Test_ForEachFromLowest(t *testing.T) {
+	d, err := disk.New(t.TempDir())
+	if err != nil {
+		t.Fatalf("Should be able to construct disk storage: %s", err)
+	}
+
+	for _, i := range []uint64{5, 6, 7} {
+		if err := d.Write(context.Background(), newBlockData(database.BlockHeader{Number: i})); err != nil {
+			t.Fatalf("Should be able to write block %d: %s", i, err)
+		}
+	}
+
+	var got []uint64
+	iter := d.ForEach(context.Background())
+	for blockData, err := iter.Next(); !iter.Done(); blockData, err = iter.Next() {
+		if err != nil {
+			t.Fatalf("Should be able to iterate the blocks: %s", err)
+		}
+		got = append(got, blockData.Header.Number)
+	}
+
+	exp := []uint64{5, 6, 7}
+	if fmt.Sprint(got) != fmt.Sprint(exp) {
+		t.Fatalf("Should walk the chain from the lowest block: got %v, exp %v", got, exp)
+	}
+
+	if _, err := iter.Next(); !errors.Is(err, database.ErrEndOfChain) || !iter.Done() {
+		t.Fatalf("Should report the end of chain after the last block: %v", err)
+	}
+}
+
+func Test_ForEachHeader(t *testing.T) {
+	for _, codec := range []disk.Codec{disk.JSON{}, disk.Gob{}} {
+		f := func(t *testing.T) {
+			d, err := disk.NewWithCodec(t.TempDir(), codec, false)
+			if err != nil {
+				t.Fatalf("Should be able to construct disk storage: %s", err)
+			}
+
+			for _, i := range []uint64{1, 2, 3} {
+				blockData := newBlockData(database.BlockHeader{Number: i, MiningReward: 700})
+				blockData.Trans = []database.BlockTx{{GasPrice: 15, GasUnits: 1}}
+				if err := d.Write(context.Background(), blockData); err != nil {
+					t.Fatalf("Should be able to write block %d: %s", i, err)
+				}
+			}
+
+			var got []uint64
+			iter := d.ForEachHeader(context.Background())
+			for header, err := iter.Next(); !iter.Done(); header, err = iter.Next() {
+				if err != nil {
+					t.Fatalf("Should be able to iterate the headers: %s", err)
+				}
+				if header.MiningReward != 700 {
+					t.Fatalf("Should decode the full header: got reward %d, exp 700", header.MiningReward)
+				}
+				got = append(got, header.Number)
+			}
+
+			exp := []uint64{1, 2, 3}
+			if fmt.Sprint(got) != fmt.Sprint(exp) {
+				t.Fatalf("Should walk the headers from the lowest block: got %v, exp %v", got, exp)
+			}
+		}
+
+		t.Run(codec.Ext(), f)
+	}
+}
+
+func Test_ForEachRange(t *testing.T) {
+	d, err := disk.New(t.TempDir())
+	if err != nil {
+		t.Fatalf("Should be able to construct disk storage: %s", err)
+	}
+
+	for i := uint64(1); i <= 5; i++ {
+		if err := d.Write(context.Background(), newBlockData(database.BlockHeader{Number: i})); err != nil {
+			t.Fatalf("Should be able to write block %d: %s", i, err)
+		}
+	}
+
+	tt := []struct {
+		name string
+		from uint64
+		to   uint64
+		exp  []uint64
+	}{
+		{name: "range", from: 2, to: 4, exp: []uint64{2, 3, 4}},
+		{name: "single", from: 3, to: 3, exp: []uint64{3}},
+		{name: "zero", from: 0, to: 2, exp: []uint64{1, 2}},
+		{name: "pasthead", from: 4, to: 10, exp: []uint64{4, 5}},
+		{name: "beyondhead", from: 8, to: 10, exp: nil},
+		{name: "inverted", from: 4, to: 2, exp: nil},
+	}
+
+	for _, tst := range tt {
+		f := func(t *testing.T) {
+			var got []uint64
+			iter := d.ForEachRange(context.Background(), tst.from, tst.to)
+			for blockData, err := iter.Next(); !iter.Done(); blockData, err = iter.Next() {
+				if err != nil {
+					t.Fatalf("Should be able to iterate the blocks: %s", err)
+				}
+				got = append(got, blockData.Header.Number)
+			}
+
+			if fmt.Sprint(got) != fmt.Sprint(tst.exp) {
+				t.Fatalf("Should walk the requested range: got %v, exp %v", got, tst.exp)
+			}
+
+			if _, err := iter.Next(); err == nil || !iter.Done() {
+				t.Fatalf("Should report the end of chain after the range.")
+			}
+		}
+
+		t.Run(tst.name, f)
+	}
+}
+
+func Test_LatestBlock(t *testing.T) {
+	d, err := disk.New(t.TempDir())
+	if err != nil {
+		t.Fatalf("Should be able to construct disk storage: %s", err)
+	}
+
+	if _, err := d.LatestBlock(context.Background()); !errors.Is(err, disk.ErrEmptyChain) {
+		t.Fatalf("Should get ErrEmptyChain with no blocks, got %v", err)
+	}
+
+	for _, i := range []uint64{3, 1, 2} {
+		if err := d.Write(context.Background(), newBlockData(database.BlockHeader{Number: i})); err != nil {
+			t.Fatalf("Should be able to write block %d: %s", i, err)
+		}
+	}
+
+	got, err := d.LatestBlock(context.Background())
+	if err != nil {
+		t.Fatalf("Should be able to get the latest block: %s", err)
+	}
+	if got.Header.Number != 3 {
+		t.Fatalf("Should get back block 3, got %d", got.Header.Number)
+	}
+}
+
+func Test_Compression(t *testing.T) {
+	dbPath := t.TempDir()
+
+	plain, err := disk.New(dbPath)
+	if err != nil {
+		t.Fatalf("Should be able to construct disk storage: %s", err)
+	}
+
+	compressed, err := disk.NewWithCompression(dbPath, true)
+	if err != nil {
+		t.Fatalf("Should be able to construct compressed disk storage: %s", err)
+	}
+
+	// Write a mixed directory of compressed and uncompressed blocks.
+	for i := uint64(1); i <= 4; i++ {
+		d := plain
+		if i%2 == 0 {
+			d = compressed
+		}
+		if err := d.Write(context.Background(), newBlockData(database.BlockHeader{Number: i})); err != nil {
+			t.Fatalf("Should be able to write block %d: %s", i, err)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(dbPath, "2.json.gz")); err != nil {
+		t.Fatalf("Should have written a compressed block file: %s", err)
+	}
+
+	for _, d := range []*disk.Disk{plain, compressed} {
+		var got []uint64
+		iter := d.ForEach(context.Background())
+		for blockData, err := iter.Next(); !iter.Done(); blockData, err = iter.Next() {
+			if err != nil {
+				t.Fatalf("Should be able to iterate the blocks: %s", err)
+			}
+			got = append(got, blockData.Header.Number)
+		}
+
+		exp := []uint64{1, 2, 3, 4}
+		if fmt.Sprint(got) != fmt.Sprint(exp) {
+			t.Fatalf("Should read the mixed directory: got %v, exp %v", got, exp)
+		}
+	}
+
+	// Rewriting a block in the other format must replace the old file.
+	if err := plain.Write(context.Background(), newBlockData(database.BlockHeader{Number: 2, MiningReward: 700})); err != nil {
+		t.Fatalf("Should be able to rewrite block 2: %s", err)
+	}
+	got, err := compressed.GetBlock(context.Background(), 2)
+	if err != nil {
+		t.Fatalf("Should be able to read block 2: %s", err)
+	}
+	if got.Header.MiningReward != 700 {
+		t.Fatalf("Should get back the rewritten block 2, got %+v", got.Header)
+	}
+}
+
+func Test_CorruptBlock(t *testing.T) {
+	dbPath := t.TempDir()
+
+	d, err := disk.New(dbPath)
+	if err != nil {
+		t.Fatalf("Should be able to construct disk storage: %s", err)
+	}
+
+	if err := d.Write(context.Background(), newBlockData(database.BlockHeader{Number: 1, MiningReward: 700})); err != nil {
+		t.Fatalf("Should be able to write block: %s", err)
+	}
+
+	if _, err := d.GetBlock(context.Background(), 1); err != nil {
+		t.Fatalf("Should be able to read a valid block: %s", err)
+	}
+
+	// Flip a byte in the mining reward so the file still decodes.
+	path := filepath.Join(dbPath, "1.json")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Should be able to read the block file: %s", err)
+	}
+	data = bytes.Replace(data, []byte("700"), []byte("900"), 1)
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatalf("Should be able to write the block file: %s", err)
+	}
+
+	_, err = d.GetBlock(context.Background(), 1)
+	if !errors.Is(err, disk.ErrCorruptBlock) {
+		t.Fatalf("Should get ErrCorruptBlock for a changed block, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "block 1") {
+		t.Fatalf("Should name the corrupt block in the error, got %v", err)
+	}
+}
+
+func Test_CorruptTransaction(t *testing.T) {
+	dbPath := t.TempDir()
+
+	d, err := disk.New(dbPath)
+	if err != nil {
+		t.Fatalf("Should be able to construct disk storage: %s", err)
+	}
+
+	blockData := newBlockDataTrans(t, database.BlockHeader{Number: 1}, []database.BlockTx{
+		{SignedTx: database.SignedTx{Tx: database.Tx{ChainID: 1, Nonce: 1, Value: 12345}}},
+	})
+	if err := d.Write(context.Background(), blockData); err != nil {
+		t.Fatalf("Should be able to write block: %s", err)
+	}
+
+	if _, err := d.GetBlock(context.Background(), 1); err != nil {
+		t.Fatalf("Should be able to read a valid block: %s", err)
+	}
+
+	// Change the value of the transaction so the file still decodes and
+	// the header is untouched.
+	path := filepath.Join(dbPath, "1.json")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Should be able to read the block file: %s", err)
+	}
+	if !bytes.Contains(data, []byte("12345")) {
+		t.Fatalf("Should find the transaction value in the block file")
+	}
+	data = bytes.Replace(data, []byte("12345"), []byte("92345"), 1)
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatalf("Should be able to write the block file: %s", err)
+	}
+
+	_, err = d.GetBlock(context.Background(), 1)
+	if !errors.Is(err, disk.ErrCorruptBlock) {
+		t.Fatalf("Should get ErrCorruptBlock for a changed transaction, got %v", err)
+	}
+}
+
+func Test_FormatVersion(t *testing.T) {
+	dbPath := t.TempDir()
+
+	d, err := disk.New(dbPath)
+	if err != nil {
+		t.Fatalf("Should be able to construct disk storage: %s", err)
+	}
+
+	if err := d.Write(context.Background(), newBlockData(database.BlockHeader{Number: 1, MiningReward: 700})); err != nil {
+		t.Fatalf("Should be able to write block: %s", err)
+	}
+
+	path := filepath.Join(dbPath, "1.json")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Should be able to read the block file: %s", err)
+	}
+
+	current := fmt.Sprintf(`"format_version": %d`, disk.FormatVersion)
+	if !bytes.Contains(data, []byte(current)) {
+		t.Fatalf("Should tag the block file with the format version, got %s", data)
+	}
+
+	if _, err := d.GetBlock(context.Background(), 1); err != nil {
+		t.Fatalf("Should be able to read a current version block: %s", err)
+	}
+
+	// Files written before the format version was added have no field.
+	legacy := bytes.Replace(data, []byte(current+","), nil, 1)
+	if err := os.WriteFile(path, legacy, 0600); err != nil {
+		t.Fatalf("Should be able to write the block file: %s", err)
+	}
+
+	if _, err := d.GetBlock(context.Background(), 1); err != nil {
+		t.Fatalf("Should be able to read a block without a format version: %s", err)
+	}
+
+	bumped := bytes.Replace(data, []byte(current), []byte(fmt.Sprintf(`"format_version": %d`, disk.FormatVersion+1)), 1)
+	if err := os.WriteFile(path, bumped, 0600); err != nil {
+		t.Fatalf("Should be able to write the block file: %s", err)
+	}
+
+	_, err = d.GetBlock(context.Background(), 1)
+	if !errors.Is(err, disk.ErrUnsupportedFormat) {
+		t.Fatalf("Should get ErrUnsupportedFormat for a newer version, got %v", err)
+	}
+	if errors.Is(err, disk.ErrCorruptBlock) {
+		t.Fatalf("Should not report a newer version as corrupt, got %v", err)
+	}
+}
+
+func Test_Usage(t *testing.T) {
+	dbPath := t.TempDir()
+
+	d, err := disk.New(dbPath)
+	if err != nil {
+		t.Fatalf("Should be able to construct disk storage: %s", err)
+	}
+
+	var exp int64
+	for i := uint64(1); i <= 3; i++ {
+		blockData := newBlockData(database.BlockHeader{Number: i})
+		if err := d.Write(context.Background(), blockData); err != nil {
+			t.Fatalf("Should be able to write block %d: %s", i, err)
+		}
+
+		data, err := disk.JSON{}.Encode(blockData)
+		if err != nil {
+			t.Fatalf("Should be able to encode block %d: %s", i, err)
+		}
+		exp += int64(len(data))
+	}
+
+	// Files that are not blocks must not be counted.
+	if err := os.WriteFile(filepath.Join(dbPath, "4.json.tmp"), []byte("{}"), 0600); err != nil {
+		t.Fatalf("Should be able to write the stray temp file: %s", err)
+	}
+
+	blockCount, totalBytes, err := d.Usage()
+	if err != nil {
+		t.Fatalf("Should be able to get the usage: %s", err)
+	}
+
+	if blockCount != 3 {
+		t.Fatalf("Should count 3 blocks, got %d", blockCount)
+	}
+	if totalBytes < exp || totalBytes > exp+3 {
+		t.Fatalf("Should sum about %d bytes, got %d", exp, totalBytes)
+	}
+}
+
+func Test_Prune(t *testing.T) {
+	d, err := disk.New(t.TempDir())
+	if err != nil {
+		t.Fatalf("Should be able to construct disk storage: %s", err)
+	}
+
+	const blocks = 110
+	var hashes []string
+	for i := uint64(1); i <= blocks; i++ {
+		blockData := newBlockData(database.BlockHeader{Number: i})
+		if err := d.Write(context.Background(), blockData); err != nil {
+			t.Fatalf("Should be able to write block %d: %s", i, err)
+		}
+		hashes = append(hashes, blockData.Hash)
+	}
+
+	// Make sure the hash index is built before the prune.
+	if _, err := d.GetBlockByHash(context.Background(), hashes[0]); err != nil {
+		t.Fatalf("Should be able to find block 1 by hash: %s", err)
+	}
+
+	if err := d.Prune(101); err != nil {
+		t.Fatalf("Should be able to prune the blocks: %s", err)
+	}
+
+	var got []uint64
+	iter := d.ForEach(context.Background())
+	for blockData, err := iter.Next(); !iter.Done(); blockData, err = iter.Next() {
+		if err != nil {
+			t.Fatalf("Should be able to iterate the blocks: %s", err)
+		}
+		got = append(got, blockData.Header.Number)
+	}
+
+	if len(got) != 10 || got[0] != 101 || got[len(got)-1] != blocks {
+		t.Fatalf("Should iterate over blocks 101 to %d, got %v", blocks, got)
+	}
+
+	// Walking backwards, the call that reaches the first pruned block is
+	// the end of the iteration.
+	got = nil
+	iter = d.ForEachReverse(context.Background())
+	blockData, err := iter.Next()
+	for ; !iter.Done(); blockData, err = iter.Next() {
+		if err != nil {
+			t.Fatalf("Should be able to iterate the blocks in reverse: %s", err)
+		}
+		got = append(got, blockData.Header.Number)
+	}
+
+	if !errors.Is(err, database.ErrEndOfChain) {
+		t.Fatalf("Should get ErrEndOfChain once the pruned point is passed, got %v", err)
+	}
+
+	if len(got) != 10 || got[0] != blocks || got[len(got)-1] != 101 {
+		t.Fatalf("Should iterate over blocks %d to 101 in reverse, got %v", blocks, got)
+	}
+
+	if _, err := d.GetBlock(context.Background(), 100); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Should get fs.ErrNotExist for a pruned block, got %v", err)
+	}
+	if _, err := d.GetBlockByHash(context.Background(), hashes[0]); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Should get fs.ErrNotExist for a pruned block hash, got %v", err)
+	}
+}
+
+func BenchmarkWrite(b *testing.B) {
+	trans := make([]database.BlockTx, 10)
+	for i := range trans {
+		trans[i] = database.BlockTx{
+			SignedTx: database.SignedTx{
+				Tx: database.Tx{
+					ChainID: 1,
+					Nonce:   uint64(i),
+					FromID:  "0xF01813E4B85e178A83e29B8E7bF26BD830a25f32",
+					ToID:    "0xbEE6ACE826eC3DE1B6349888B9151B92522F7F76",
+					Value:   100,
+					Tip:     10,
+				},
+				V: big.NewInt(29),
+				R: big.NewInt(1),
+				S: big.NewInt(1),
+			},
+			GasPrice: 15,
+			GasUnits: 1,
+		}
+	}
+
+	for _, compress := range []bool{false, true} {
+		b.Run(fmt.Sprintf("compress=%t", compress), func(b *testing.B) {
+			d, err := disk.NewWithCompression(b.TempDir(), compress)
+			if err != nil {
+				b.Fatalf("Should be able to construct disk storage: %s", err)
+			}
+
+			for i := 0; i < b.N; i++ {
+				blockData := database.BlockData{
+					Header: database.BlockHeader{Number: uint64(i + 1)},
+					Trans:  trans,
+				}
+				if err := d.Write(context.Background(), blockData); err != nil {
+					b.Fatalf("Should be able to write block: %s", err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkForEachHeader(b *testing.B) {
+	const blocks = 20
+
+	d, err := disk.New(b.TempDir())
+	if err != nil {
+		b.Fatalf("Should be able to construct disk storage: %s", err)
+	}
+
+	trans := make([]database.BlockTx, 100)
+	for i := range trans {
+		trans[i] = database.BlockTx{
+			SignedTx: database.SignedTx{
+				Tx: database.Tx{
+					ChainID: 1,
+					Nonce:   uint64(i),
+					FromID:  "0xF01813E4B85e178A83e29B8E7bF26BD830a25f32",
+					ToID:    "0xbEE6ACE826eC3DE1B6349888B9151B92522F7F76",
+					Value:   100,
+					Tip:     10,
+				},
+				V: big.NewInt(29),
+				R: big.NewInt(1),
+				S: big.NewInt(1),
+			},
+			GasPrice: 15,
+			GasUnits: 1,
+		}
+	}
+
+	for i := uint64(1); i <= blocks; i++ {
+		blockData := newBlockData(database.BlockHeader{Number: i})
+		blockData.Trans = trans
+		if err := d.Write(context.Background(), blockData); err != nil {
+			b.Fatalf("Should be able to write block %d: %s", i, err)
+		}
+	}
+
+	b.Run("full", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			iter := d.ForEach(context.Background())
+			for _, err := iter.Next(); !iter.Done(); _, err = iter.Next() {
+				if err != nil {
+					b.Fatalf("Should be able to iterate the blocks: %s", err)
+				}
+			}
+		}
+	})
+
+	b.Run("header", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			iter := d.ForEachHeader(context.Background())
+			for _, err := iter.Next(); !iter.Done(); _, err = iter.Next() {
+				if err != nil {
+					b.Fatalf("Should be able to iterate the headers: %s", err)
+				}
+			}
+		}
+	})
+}
+
+func Test_WriteBatch(t *testing.T) {
+	dbPath := t.TempDir()
+
+	d, err := disk.New(dbPath)
+	if err != nil {
+		t.Fatalf("Should be able to construct disk storage: %s", err)
+	}
+
+	var blocks []database.BlockData
+	for i := uint64(1); i <= 20; i++ {
+		blocks = append(blocks, newBlockData(database.BlockHeader{Number: i}))
+	}
+
+	if err := d.WriteBatch(context.Background(), blocks); err != nil {
+		t.Fatalf("Should be able to write the batch: %s", err)
+	}
+
+	var got int
+	iter := d.ForEach(context.Background())
+	for blockData, err := iter.Next(); !iter.Done(); blockData, err = iter.Next() {
+		if err != nil {
+			t.Fatalf("Should be able to iterate the blocks: %s", err)
+		}
+		got++
+		if blockData.Header.Number != uint64(got) {
+			t.Fatalf("Should read back the batch in order: got %d, exp %d", blockData.Header.Number, got)
+		}
+	}
+	if got != len(blocks) {
+		t.Fatalf("Should read back %d blocks, got %d", len(blocks), got)
+	}
+
+	// A batch that fails must name the block and not leave temporary
+	// files behind.
+	if err := d.Reset(); err != nil {
+		t.Fatalf("Should be able to reset: %s", err)
+	}
+	if err := os.Mkdir(filepath.Join(dbPath, "3.json"), 0755); err != nil {
+		t.Fatalf("Should be able to block the path for block 3: %s", err)
+	}
+
+	err = d.WriteBatch(context.Background(), blocks[:5])
+	if err == nil || !strings.Contains(err.Error(), "block 3") {
+		t.Fatalf("Should get an error naming block 3, got %v", err)
+	}
+
+	entries, err := os.ReadDir(dbPath)
+	if err != nil {
+		t.Fatalf("Should be able to read the directory: %s", err)
+	}
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), ".tmp") {
+			t.Fatalf("Should not leave temporary files behind, found %s", entry.Name())
+		}
+	}
+}
+
+func BenchmarkWriteBatch(b *testing.B) {
+	const batch = 100
+
+	blocks := make([]database.BlockData, batch)
+	for i := range blocks {
+		blocks[i] = newBlockData(database.BlockHeader{Number: uint64(i + 1)})
+	}
+
+	b.Run("sequential", func(b *testing.B) {
+		d, err := disk.New(b.TempDir())
+		if err != nil {
+			b.Fatalf("Should be able to construct disk storage: %s", err)
+		}
+
+		for i := 0; i < b.N; i++ {
+			for _, blockData := range blocks {
+				if err := d.Write(context.Background(), blockData); err != nil {
+					b.Fatalf("Should be able to write block: %s", err)
+				}
+			}
+		}
+	})
+
+	b.Run("batch", func(b *testing.B) {
+		d, err := disk.New(b.TempDir())
+		if err != nil {
+			b.Fatalf("Should be able to construct disk storage: %s", err)
+		}
+
+		for i := 0; i < b.N; i++ {
+			if err := d.WriteBatch(context.Background(), blocks); err != nil {
+				b.Fatalf("Should be able to write the batch: %s", err)
+			}
+		}
+	})
+}
+
+func Test_Concurrency(t *testing.T) {
+	d, err := disk.New(t.TempDir())
+	if err != nil {
+		t.Fatalf("Should be able to construct disk storage: %s", err)
+	}
+
+	const blocks = 20
+	const goroutines = 4
+
+	var wg sync.WaitGroup
+	wg.Add(goroutines * 2)
+
+	for g := 0; g < goroutines; g++ {
+		go func() {
+			defer wg.Done()
+			for i := uint64(1); i <= blocks; i++ {
+				if err := d.Write(context.Background(), newBlockData(database.BlockHeader{Number: i})); err != nil {
+					t.Errorf("Should be able to write block %d: %s", i, err)
+				}
+			}
+		}()
+
+		go func() {
+			defer wg.Done()
+			for i := uint64(1); i <= blocks; i++ {
+				if _, err := d.GetBlock(context.Background(), i); err != nil && !errors.Is(err, fs.ErrNotExist) {
+					t.Errorf("Should be able to read block %d: %s", i, err)
+				}
+
+				iter := d.ForEach(context.Background())
+				for _, err := iter.Next(); !iter.Done(); _, err = iter.Next() {
+					if err != nil {
+						t.Errorf("Should be able to iterate the blocks: %s", err)
+					}
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	for i := uint64(1); i <= blocks; i++ {
+		if _, err := d.GetBlock(context.Background(), i); err != nil {
+			t.Fatalf("Should be able to read block %d: %s", i, err)
+		}
+	}
+}
+
+func Test_Permissions(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "blocks")
+
+	d, err := disk.NewWithOptions(dbPath, disk.Options{FileMode: 0640, DirMode: 0700})
+	if err != nil {
+		t.Fatalf("Should be able to construct disk storage: %s", err)
+	}
+
+	if err := d.Write(context.Background(), newBlockData(database.BlockHeader{Number: 1})); err != nil {
+		t.Fatalf("Should be able to write block 1: %s", err)
+	}
+	if err := d.WriteBatch(context.Background(), []database.BlockData{newBlockData(database.BlockHeader{Number: 2})}); err != nil {
+		t.Fatalf("Should be able to write block 2: %s", err)
+	}
+
+	for _, name := range []string{"1.json", "2.json"} {
+		info, err := os.Stat(filepath.Join(dbPath, name))
+		if err != nil {
+			t.Fatalf("Should be able to stat %s: %s", name, err)
+		}
+		if perm := info.Mode().Perm(); perm != 0640 {
+			t.Fatalf("Should write %s with the file mode: got %o, exp %o", name, perm, 0640)
+		}
+	}
+
+	if err := d.Reset(); err != nil {
+		t.Fatalf("Should be able to reset: %s", err)
+	}
+
+	info, err := os.Stat(dbPath)
+	if err != nil {
+		t.Fatalf("Should be able to stat the directory: %s", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0700 {
+		t.Fatalf("Should create the directory with the dir mode: got %o, exp %o", perm, 0700)
+	}
+}
+
+func Test_ContextCancel(t *testing.T) {
+	d, err := disk.New(t.TempDir())
+	if err != nil {
+		t.Fatalf("Should be able to construct disk storage: %s", err)
+	}
+
+	for i := uint64(1); i <= 5; i++ {
+		if err := d.Write(context.Background(), newBlockData(database.BlockHeader{Number: i})); err != nil {
+			t.Fatalf("Should be able to write block %d: %s", i, err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var blocks int
+	var iterErr error
+	iter := d.ForEach(ctx)
+	for _, err := iter.Next(); !iter.Done(); _, err = iter.Next() {
+		if err != nil {
+			iterErr = err
+			continue
+		}
+
+		// Cancel the iteration in the middle of the chain.
+		blocks++
+		if blocks == 2 {
+			cancel()
+		}
+	}
+
+	if blocks != 2 {
+		t.Fatalf("Should stop iterating after the cancel, got %d blocks", blocks)
+	}
+	if !errors.Is(iterErr, context.Canceled) {
+		t.Fatalf("Should get back the context error from the iterator: %v", iterErr)
+	}
+
+	if _, err := d.GetBlock(ctx, 1); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Should not be able to read a block with a cancelled context: %v", err)
+	}
+
+	if err := d.WriteBatch(ctx, []database.BlockData{newBlockData(database.BlockHeader{Number: 6})}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Should not be able to write blocks with a cancelled context: %v", err)
+	}
+	if _, err := d.GetBlock(context.Background(), 6); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Should not find block 6 after a cancelled write: %v", err)
+	}
+}
+
+func Test_Healthy(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "blocks")
+
+	d, err := disk.New(dbPath)
+	if err != nil {
+		t.Fatalf("Should be able to construct disk storage: %s", err)
+	}
+
+	if err := d.Healthy(context.Background()); err != nil {
+		t.Fatalf("Should be healthy with a writable directory: %s", err)
+	}
+
+	entries, err := os.ReadDir(dbPath)
+	if err != nil {
+		t.Fatalf("Should be able to read the directory: %s", err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("Should not leave the probe file behind, got %d files", len(entries))
+	}
+
+	t.Run("read only", func(t *testing.T) {
+		if err := os.Chmod(dbPath, 0500); err != nil {
+			t.Fatalf("Should be able to make the directory read only: %s", err)
+		}
+		defer os.Chmod(dbPath, 0755)
+
+		// Permissions aren't enforced for root.
+		probe := filepath.Join(dbPath, "probe")
+		if err := os.WriteFile(probe, nil, 0600); err == nil {
+			os.Remove(probe)
+			t.Skip("Directory permissions aren't enforced for this user.")
+		}
+
+		if err := d.Healthy(context.Background()); err == nil {
+			t.Fatalf("Should not be healthy with a read only directory.")
+		}
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		if err := os.RemoveAll(dbPath); err != nil {
+			t.Fatalf("Should be able to remove the directory: %s", err)
+		}
+
+		if err := d.Healthy(context.Background()); err == nil {
+			t.Fatalf("Should not be healthy with a missing directory.")
+		}
+	})
+}
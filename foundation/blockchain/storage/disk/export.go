@@ -0,0 +1,116 @@
+package disk
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/andrewyang17/blockchain/foundation/blockchain/database"
+)
+
+// CORE NOTE: An export is a stream of blocks in block order. Each block is
+// encoded as JSON, no matter the codec used by the disk, and written after
+// an 8 byte big endian length. This keeps the export portable between nodes
+// that store their blocks differently.
+
+// maxExportBlockSize is the largest encoded block accepted on import. This
+// protects against allocating huge buffers for a corrupt stream.
+const maxExportBlockSize = 64 << 20
+
+// importBatchSize is the number of blocks written to disk at a time on import.
+const importBatchSize = 100
+
+// Export writes all the blocks on disk to the writer in block order as a
+// length prefixed stream.
+func (d *Disk) Export(ctx context.Context, w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	var codec JSON
+	iter := d.ForEach(ctx)
+	for blockData, err := iter.Next(); !iter.Done(); blockData, err = iter.Next() {
+		if err != nil {
+			return err
+		}
+
+		data, err := codec.Encode(blockData)
+		if err != nil {
+			return fmt.Errorf("block %d: %w", blockData.Header.Number, err)
+		}
+
+		var size [8]byte
+		binary.BigEndian.PutUint64(size[:], uint64(len(data)))
+
+		if _, err := bw.Write(size[:]); err != nil {
+			return err
+		}
+		if _, err := bw.Write(data); err != nil {
+			return err
+		}
+	}
+
+	return bw.Flush()
+}
+
+// Import reads a stream written by Export and writes the blocks to disk. The
+// block numbers in the stream must be increasing. Blocks are written in
+// batches, so the blocks before a bad block in the stream are kept.
+func (d *Disk) Import(ctx context.Context, r io.Reader) error {
+	br := bufio.NewReader(r)
+
+	var codec JSON
+	var last uint64
+	var batch []database.BlockData
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		var size [8]byte
+		if _, err := io.ReadFull(br, size[:]); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return fmt.Errorf("after block %d: reading size: %w", last, err)
+		}
+
+		n := binary.BigEndian.Uint64(size[:])
+		if n > maxExportBlockSize {
+			return fmt.Errorf("after block %d: block size %d is too large", last, n)
+		}
+
+		data := make([]byte, n)
+		if _, err := io.ReadFull(br, data); err != nil {
+			return fmt.Errorf("after block %d: reading block: %w", last, err)
+		}
+
+		blockData, err := codec.Decode(data)
+		if err != nil {
+			if errors.Is(err, ErrUnsupportedFormat) {
+				return fmt.Errorf("after block %d: %w", last, err)
+			}
+			return fmt.Errorf("after block %d: %s: %w", last, err, ErrCorruptBlock)
+		}
+
+		if blockData.Header.Number <= last {
+			return fmt.Errorf("block %d: out of order, follows block %d", blockData.Header.Number, last)
+		}
+		last = blockData.Header.Number
+
+		batch = append(batch, blockData)
+		if len(batch) == importBatchSize {
+			if err := d.WriteBatch(ctx, batch); err != nil {
+				return err
+			}
+			batch = nil
+		}
+	}
+
+	if len(batch) > 0 {
+		return d.WriteBatch(ctx, batch)
+	}
+
+	return nil
+}
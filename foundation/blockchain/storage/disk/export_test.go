@@ -0,0 +1,83 @@
+package disk_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+
+	"github.com/andrewyang17/blockchain/foundation/blockchain/database"
+	"github.com/andrewyang17/blockchain/foundation/blockchain/storage/disk"
+)
+
+func Test_ExportImport(t *testing.T) {
+	d, err := disk.NewWithCodec(t.TempDir(), disk.Gob{}, true)
+	if err != nil {
+		t.Fatalf("Should be able to construct disk storage: %s", err)
+	}
+
+	var blocks []database.BlockData
+	for i := uint64(1); i <= 10; i++ {
+		blockData := newBlockData(database.BlockHeader{Number: i, MiningReward: 700})
+		blocks = append(blocks, blockData)
+	}
+	if err := d.WriteBatch(context.Background(), blocks); err != nil {
+		t.Fatalf("Should be able to write the blocks: %s", err)
+	}
+
+	var buf bytes.Buffer
+	if err := d.Export(context.Background(), &buf); err != nil {
+		t.Fatalf("Should be able to export the chain: %s", err)
+	}
+
+	imported, err := disk.New(t.TempDir())
+	if err != nil {
+		t.Fatalf("Should be able to construct disk storage: %s", err)
+	}
+	if err := imported.Import(context.Background(), &buf); err != nil {
+		t.Fatalf("Should be able to import the chain: %s", err)
+	}
+
+	var got []database.BlockData
+	iter := imported.ForEach(context.Background())
+	for blockData, err := iter.Next(); !iter.Done(); blockData, err = iter.Next() {
+		if err != nil {
+			t.Fatalf("Should be able to iterate the imported blocks: %s", err)
+		}
+		got = append(got, blockData)
+	}
+
+	if len(got) != len(blocks) {
+		t.Fatalf("Should import every block: got %d, exp %d", len(got), len(blocks))
+	}
+	for i := range blocks {
+		if got[i].Hash != blocks[i].Hash || got[i].Header != blocks[i].Header {
+			t.Fatalf("Should import block %d unchanged: got %+v, exp %+v", i+1, got[i].Header, blocks[i].Header)
+		}
+	}
+}
+
+func Test_ImportOutOfOrder(t *testing.T) {
+	var buf bytes.Buffer
+	for _, i := range []uint64{1, 3, 2} {
+		data, err := json.Marshal(newBlockData(database.BlockHeader{Number: i}))
+		if err != nil {
+			t.Fatalf("Should be able to marshal block %d: %s", i, err)
+		}
+
+		var size [8]byte
+		binary.BigEndian.PutUint64(size[:], uint64(len(data)))
+		buf.Write(size[:])
+		buf.Write(data)
+	}
+
+	d, err := disk.New(t.TempDir())
+	if err != nil {
+		t.Fatalf("Should be able to construct disk storage: %s", err)
+	}
+
+	if err := d.Import(context.Background(), &buf); err == nil {
+		t.Fatalf("Should not be able to import blocks out of order")
+	}
+}
@@ -0,0 +1,107 @@
+package disk
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/andrewyang17/blockchain/foundation/blockchain/database"
+)
+
+// FormatVersion is the version of the block file layout written by the
+// codecs. Bump it when a change to database.BlockData can't be read by the
+// older code, and add a migration from the previous version.
+const FormatVersion = 1
+
+// ErrUnsupportedFormat is returned when a block file was written with a
+// format version this node can't read.
+var ErrUnsupportedFormat = errors.New("unsupported block format")
+
+// migrations holds the functions that upgrade a block file from the version
+// used as the key to the next version. Files written before the format
+// version was added decode as version 0 and have the same layout as
+// version 1.
+var migrations = map[int]func(fb fileBlock) (fileBlock, error){
+	0: func(fb fileBlock) (fileBlock, error) {
+		return fb, nil
+	},
+}
+
+// fileBlock represents the layout of a block file. It mirrors
+// database.BlockData with the format version added.
+type fileBlock struct {
+	FormatVersion int                  `json:"format_version"`
+	Hash          string               `json:"hash"`
+	Header        database.BlockHeader `json:"block"`
+	Trans         []database.BlockTx   `json:"trans"`
+}
+
+// fileHeader mirrors fileBlock without the transactions, so decoding into it
+// skips over them.
+type fileHeader struct {
+	FormatVersion int                  `json:"format_version"`
+	Hash          string               `json:"hash"`
+	Header        database.BlockHeader `json:"block"`
+}
+
+// toFileBlock tags the block with the current format version for encoding.
+func toFileBlock(blockData database.BlockData) fileBlock {
+	return fileBlock{
+		FormatVersion: FormatVersion,
+		Hash:          blockData.Hash,
+		Header:        blockData.Header,
+		Trans:         blockData.Trans,
+	}
+}
+
+// toBlockData upgrades the decoded block file to the current format version
+// and returns the block.
+func toBlockData(fb fileBlock) (database.BlockData, error) {
+	fb, err := migrate(fb)
+	if err != nil {
+		return database.BlockData{}, err
+	}
+
+	blockData := database.BlockData{
+		Hash:   fb.Hash,
+		Header: fb.Header,
+		Trans:  fb.Trans,
+	}
+
+	return blockData, nil
+}
+
+// toHeader upgrades the decoded header to the current format version and
+// returns the hash and header.
+func toHeader(fh fileHeader) (string, database.BlockHeader, error) {
+	fb, err := migrate(fileBlock{FormatVersion: fh.FormatVersion, Hash: fh.Hash, Header: fh.Header})
+	if err != nil {
+		return "", database.BlockHeader{}, err
+	}
+
+	return fb.Hash, fb.Header, nil
+}
+
+// migrate runs the migrations needed to bring the block file up to the
+// current format version.
+func migrate(fb fileBlock) (fileBlock, error) {
+	if fb.FormatVersion < 0 || fb.FormatVersion > FormatVersion {
+		return fileBlock{}, fmt.Errorf("format version %d: %w", fb.FormatVersion, ErrUnsupportedFormat)
+	}
+
+	for fb.FormatVersion < FormatVersion {
+		fn, exists := migrations[fb.FormatVersion]
+		if !exists {
+			return fileBlock{}, fmt.Errorf("format version %d: no migration: %w", fb.FormatVersion, ErrUnsupportedFormat)
+		}
+
+		version := fb.FormatVersion
+
+		var err error
+		if fb, err = fn(fb); err != nil {
+			return fileBlock{}, fmt.Errorf("migrating format version %d: %w", version, err)
+		}
+		fb.FormatVersion = version + 1
+	}
+
+	return fb, nil
+}
@@ -0,0 +1,218 @@
+package disk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"sync"
+
+	"github.com/andrewyang17/blockchain/foundation/blockchain/database"
+)
+
+// accountIndexFile is the name of the sidecar file in the database directory
+// holding the account index.
+const accountIndexFile = "accounts.idx"
+
+// ErrNoAccountIndex is returned when the account index is used but wasn't
+// turned on in the options.
+var ErrNoAccountIndex = errors.New("account index not enabled")
+
+// TxRef locates a transaction inside a block on disk.
+type TxRef struct {
+	BlockNumber uint64 `json:"block_number"`
+	TxIndex     int    `json:"tx_index"`
+}
+
+// accountIndex maps an account to the transactions where the account is
+// the sender or the receiver. The refs for each account are kept ordered
+// by block number and transaction index.
+type accountIndex struct {
+	mu   sync.Mutex
+	refs map[database.AccountID][]TxRef
+}
+
+// TxsForAccount returns the location of every transaction on disk where the
+// account is the sender or the receiver.
+func (d *Disk) TxsForAccount(accountID database.AccountID) ([]TxRef, error) {
+	if d.accountIndex == nil {
+		return nil, ErrNoAccountIndex
+	}
+
+	ai := d.accountIndex
+	ai.mu.Lock()
+	defer ai.mu.Unlock()
+	{
+		refs := make([]TxRef, len(ai.refs[accountID]))
+		copy(refs, ai.refs[accountID])
+		return refs, nil
+	}
+}
+
+// loadAccountIndex reads the account index from the sidecar file. The index
+// is rebuilt from the blocks on disk when the file is missing or can't be
+// decoded.
+func (d *Disk) loadAccountIndex() error {
+	ai := accountIndex{
+		refs: make(map[database.AccountID][]TxRef),
+	}
+	d.accountIndex = &ai
+
+	data, err := os.ReadFile(path.Join(d.dbPath, accountIndexFile))
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(data, &ai.refs); err == nil {
+			return nil
+		}
+		ai.refs = make(map[database.AccountID][]TxRef)
+
+	case !errors.Is(err, fs.ErrNotExist):
+		return err
+	}
+
+	var blocks []database.BlockData
+	iter := d.ForEach(context.Background())
+	for blockData, err := iter.Next(); !iter.Done(); blockData, err = iter.Next() {
+		if err != nil {
+			return err
+		}
+		blocks = append(blocks, blockData)
+	}
+
+	return d.indexAccounts(blocks)
+}
+
+// indexAccounts adds the transactions in the blocks to the account index and
+// saves the index to the sidecar file. Refs for a block number that is being
+// rewritten are replaced.
+func (d *Disk) indexAccounts(blocks []database.BlockData) error {
+	if d.accountIndex == nil {
+		return nil
+	}
+
+	ai := d.accountIndex
+	ai.mu.Lock()
+	defer ai.mu.Unlock()
+	{
+		numbers := make(map[uint64]bool, len(blocks))
+		for _, blockData := range blocks {
+			numbers[blockData.Header.Number] = true
+		}
+		ai.remove(func(ref TxRef) bool { return numbers[ref.BlockNumber] })
+
+		changed := make(map[database.AccountID]bool)
+		for _, blockData := range blocks {
+			for i, tx := range blockData.Trans {
+				ref := TxRef{BlockNumber: blockData.Header.Number, TxIndex: i}
+
+				ai.refs[tx.FromID] = append(ai.refs[tx.FromID], ref)
+				changed[tx.FromID] = true
+
+				// An account paid by more than one output only gets the
+				// one reference, which would be the last one added.
+				for _, out := range tx.Payments() {
+					refs := ai.refs[out.ToID]
+					if out.ToID == tx.FromID || (len(refs) > 0 && refs[len(refs)-1] == ref) {
+						continue
+					}
+					ai.refs[out.ToID] = append(ai.refs[out.ToID], ref)
+					changed[out.ToID] = true
+				}
+			}
+		}
+
+		for accountID := range changed {
+			refs := ai.refs[accountID]
+			sort.Slice(refs, func(i, j int) bool {
+				if refs[i].BlockNumber != refs[j].BlockNumber {
+					return refs[i].BlockNumber < refs[j].BlockNumber
+				}
+				return refs[i].TxIndex < refs[j].TxIndex
+			})
+		}
+
+		return d.saveAccountIndex()
+	}
+}
+
+// pruneAccountIndex removes the refs to blocks lower than keepFromBlock and
+// saves the index to the sidecar file.
+func (d *Disk) pruneAccountIndex(keepFromBlock uint64) error {
+	if d.accountIndex == nil {
+		return nil
+	}
+
+	ai := d.accountIndex
+	ai.mu.Lock()
+	defer ai.mu.Unlock()
+	{
+		ai.remove(func(ref TxRef) bool { return ref.BlockNumber < keepFromBlock })
+		return d.saveAccountIndex()
+	}
+}
+
+// resetAccountIndex clears out the account index. The sidecar file is
+// removed with the rest of the database directory.
+func (d *Disk) resetAccountIndex() {
+	if d.accountIndex == nil {
+		return
+	}
+
+	ai := d.accountIndex
+	ai.mu.Lock()
+	defer ai.mu.Unlock()
+	{
+		ai.refs = make(map[database.AccountID][]TxRef)
+	}
+}
+
+// saveAccountIndex writes the account index to a temporary file and moves
+// it into place. The caller must hold the account index lock.
+func (d *Disk) saveAccountIndex() error {
+	data, err := json.Marshal(d.accountIndex.refs)
+	if err != nil {
+		return err
+	}
+
+	indexPath := path.Join(d.dbPath, accountIndexFile)
+	tmpPath := indexPath + ".tmp"
+
+	f, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, d.fileMode)
+	if err != nil {
+		return err
+	}
+
+	if err := writeSync(f, d.fileMode, data); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	if err := os.Rename(tmpPath, indexPath); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	return nil
+}
+
+// remove drops the refs that match the filter, deleting accounts that are
+// left without any refs. The caller must hold the lock.
+func (ai *accountIndex) remove(filter func(ref TxRef) bool) {
+	for accountID, refs := range ai.refs {
+		kept := refs[:0]
+		for _, ref := range refs {
+			if !filter(ref) {
+				kept = append(kept, ref)
+			}
+		}
+
+		if len(kept) == 0 {
+			delete(ai.refs, accountID)
+			continue
+		}
+		ai.refs[accountID] = kept
+	}
+}
@@ -0,0 +1,98 @@
+package disk_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/andrewyang17/blockchain/foundation/blockchain/database"
+	"github.com/andrewyang17/blockchain/foundation/blockchain/storage/disk"
+)
+
+func Test_TxsForAccount(t *testing.T) {
+	const (
+		idKennedy = "0xF01813E4B85e178A83e29B8E7bF26BD830a25f32"
+		idPavel   = "0xdd6B972ffcc631a62CAE1BB9d80b7ff429c8ebA4"
+		idCesar   = "0xbEE6ACE826eC3DE1B6349888B9151B92522F7F76"
+	)
+
+	tran := func(from database.AccountID, to database.AccountID) database.BlockTx {
+		return database.BlockTx{SignedTx: database.SignedTx{Tx: database.Tx{FromID: from, ToID: to}}}
+	}
+
+	block := func(num uint64, trans ...database.BlockTx) database.BlockData {
+		return newBlockDataTrans(t, database.BlockHeader{Number: num}, trans)
+	}
+
+	dbPath := t.TempDir()
+
+	d, err := disk.NewWithOptions(dbPath, disk.Options{AccountIndex: true})
+	if err != nil {
+		t.Fatalf("Should be able to construct disk storage: %s", err)
+	}
+
+	if err := d.Write(context.Background(), block(1, tran(idKennedy, idPavel))); err != nil {
+		t.Fatalf("Should be able to write block 1: %s", err)
+	}
+	if err := d.Write(context.Background(), block(2, tran(idPavel, idCesar), tran(idKennedy, idCesar))); err != nil {
+		t.Fatalf("Should be able to write block 2: %s", err)
+	}
+	if err := d.WriteBatch(context.Background(), []database.BlockData{block(3, tran(idCesar, idKennedy)), block(4, tran(idPavel, idPavel))}); err != nil {
+		t.Fatalf("Should be able to write blocks 3 and 4: %s", err)
+	}
+
+	exp := map[database.AccountID][]disk.TxRef{
+		idKennedy: {{BlockNumber: 1, TxIndex: 0}, {BlockNumber: 2, TxIndex: 1}, {BlockNumber: 3, TxIndex: 0}},
+		idPavel:   {{BlockNumber: 1, TxIndex: 0}, {BlockNumber: 2, TxIndex: 0}, {BlockNumber: 4, TxIndex: 0}},
+		idCesar:   {{BlockNumber: 2, TxIndex: 0}, {BlockNumber: 2, TxIndex: 1}, {BlockNumber: 3, TxIndex: 0}},
+	}
+
+	check := func(name string, d *disk.Disk) {
+		for accountID, refs := range exp {
+			got, err := d.TxsForAccount(accountID)
+			if err != nil {
+				t.Fatalf("%s: Should be able to look up account %s: %s", name, accountID, err)
+			}
+			if !reflect.DeepEqual(got, refs) {
+				t.Fatalf("%s: Should get the right refs for account %s: got %v, exp %v", name, accountID, got, refs)
+			}
+		}
+	}
+
+	check("after writes", d)
+
+	// Reopening the storage reads the index from the sidecar file.
+	d, err = disk.NewWithOptions(dbPath, disk.Options{AccountIndex: true})
+	if err != nil {
+		t.Fatalf("Should be able to reopen disk storage: %s", err)
+	}
+	check("after reopen", d)
+
+	// Reopening the storage without the sidecar file rebuilds the index.
+	if err := os.Remove(filepath.Join(dbPath, "accounts.idx")); err != nil {
+		t.Fatalf("Should be able to remove the index file: %s", err)
+	}
+	d, err = disk.NewWithOptions(dbPath, disk.Options{AccountIndex: true})
+	if err != nil {
+		t.Fatalf("Should be able to reopen disk storage: %s", err)
+	}
+	check("after rebuild", d)
+
+	// Rewriting a block replaces the refs for the block.
+	if err := d.Write(context.Background(), block(4, tran(idPavel, idKennedy))); err != nil {
+		t.Fatalf("Should be able to rewrite block 4: %s", err)
+	}
+	exp[idKennedy] = append(exp[idKennedy], disk.TxRef{BlockNumber: 4, TxIndex: 0})
+	check("after rewrite", d)
+
+	d, err = disk.New(dbPath)
+	if err != nil {
+		t.Fatalf("Should be able to construct disk storage: %s", err)
+	}
+	if _, err := d.TxsForAccount(idKennedy); !errors.Is(err, disk.ErrNoAccountIndex) {
+		t.Fatalf("Should get an error when the index isn't enabled: %v", err)
+	}
+}
@@ -0,0 +1,282 @@
+package disk
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/andrewyang17/blockchain/foundation/blockchain/database"
+)
+
+// walFile is the name of the write ahead log in the database directory.
+const walFile = "wal.log"
+
+// Set of record types written to the write ahead log.
+const (
+	walBegin  = "begin"
+	walCommit = "commit"
+)
+
+// logBegin records that the blocks are about to be written. Each record
+// carries the block hash so recovery can tell the new block from an older
+// block stored under the same number.
+func (d *Disk) logBegin(blocks []database.BlockData) error {
+	var b strings.Builder
+	for _, blockData := range blocks {
+		fmt.Fprintf(&b, "%s %d %s\n", walBegin, blockData.Header.Number, blockData.Hash)
+	}
+
+	d.walMu.Lock()
+	defer d.walMu.Unlock()
+
+	if err := d.appendWAL(b.String()); err != nil {
+		return err
+	}
+	d.walPending += len(blocks)
+
+	return nil
+}
+
+// logCommit records that the blocks have been written and indexed. When no
+// other write is left between its begin and commit records, every record in
+// the log is settled and the log is truncated so it doesn't grow forever. A
+// write that failed before its commit keeps the log until Recover runs.
+func (d *Disk) logCommit(blocks []database.BlockData) error {
+	var b strings.Builder
+	for _, blockData := range blocks {
+		fmt.Fprintf(&b, "%s %d\n", walCommit, blockData.Header.Number)
+	}
+
+	d.walMu.Lock()
+	defer d.walMu.Unlock()
+
+	if err := d.appendWAL(b.String()); err != nil {
+		return err
+	}
+	d.walPending -= len(blocks)
+
+	if d.walPending > 0 {
+		return nil
+	}
+
+	if err := os.Truncate(path.Join(d.dbPath, walFile), 0); err != nil {
+		return fmt.Errorf("truncating write ahead log: %w", err)
+	}
+
+	return nil
+}
+
+// appendWAL appends the records to the write ahead log and flushes them to
+// stable storage before returning. The caller must hold the wal lock.
+func (d *Disk) appendWAL(records string) error {
+	f, err := os.OpenFile(path.Join(d.dbPath, walFile), os.O_CREATE|os.O_APPEND|os.O_WRONLY, d.fileMode)
+	if err != nil {
+		return fmt.Errorf("opening write ahead log: %w", err)
+	}
+
+	if err := writeSync(f, d.fileMode, []byte(records)); err != nil {
+		return fmt.Errorf("writing write ahead log: %w", err)
+	}
+
+	return nil
+}
+
+// Recover reads the write ahead log and finishes any block write that was
+// interrupted between its begin and commit records. A block whose file made
+// it into place with the logged hash is rolled forward by finishing the
+// write and updating the indexes. Otherwise the write is rolled back by
+// removing a partial block file, leaving any older block with the same
+// number in place. Temporary files left by the write are removed and the log
+// is cleared once every write is settled. Recover should be called at
+// startup before the storage is used, and does nothing without a log.
+func (d *Disk) Recover() error {
+	d.walMu.Lock()
+	defer d.walMu.Unlock()
+
+	walPath := path.Join(d.dbPath, walFile)
+
+	pending, err := readWAL(walPath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	nums := make([]uint64, 0, len(pending))
+	for num := range pending {
+		nums = append(nums, num)
+	}
+	sort.Slice(nums, func(i, j int) bool { return nums[i] < nums[j] })
+
+	var forward []database.BlockData
+	for _, num := range nums {
+		blockData, ok, err := d.recoverBlock(num, pending[num])
+		if err != nil {
+			return fmt.Errorf("recovering block %d: %w", num, err)
+		}
+		if ok {
+			forward = append(forward, blockData)
+		}
+	}
+
+	d.indexBlocks(forward)
+	if err := d.indexAccounts(forward); err != nil {
+		return err
+	}
+
+	if err := os.Remove(walPath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	d.walPending = 0
+
+	return nil
+}
+
+// recoverBlock settles the interrupted write of the specified block. The
+// returned bool reports if the write was rolled forward.
+func (d *Disk) recoverBlock(num uint64, hash string) (database.BlockData, bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if err := d.removeTmpFiles(num); err != nil {
+		return database.BlockData{}, false, err
+	}
+
+	blockPath := d.getPath(num)
+	otherPath := blockPath + gzipExt
+	if d.compress {
+		blockPath, otherPath = otherPath, blockPath
+	}
+
+	blockData, err := d.readFile(blockPath)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+
+		// The block never made it into place.
+		return database.BlockData{}, false, nil
+
+	case err != nil:
+
+		// Whatever is in place can't be trusted, so remove it.
+		if err := os.Remove(blockPath); err != nil {
+			return database.BlockData{}, false, err
+		}
+		return database.BlockData{}, false, nil
+
+	case blockData.Hash != hash:
+
+		// This is the block that was there before the write.
+		return database.BlockData{}, false, nil
+	}
+
+	// Finish the write by removing the copy in the other format.
+	if err := os.Remove(otherPath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return database.BlockData{}, false, err
+	}
+
+	return blockData, true, nil
+}
+
+// removeTmpFiles removes the temporary files written for the specified
+// block by Write and WriteBatch. The caller must hold the write lock.
+func (d *Disk) removeTmpFiles(num uint64) error {
+	entries, err := os.ReadDir(d.dbPath)
+	if err != nil {
+		return err
+	}
+
+	prefix := filepath.Base(d.getPath(num))
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ".tmp") {
+			continue
+		}
+
+		if err := os.Remove(path.Join(d.dbPath, name)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// readFile reads and decodes the block file at the specified path, checking
+// the hashes match the block. The caller must hold the lock.
+func (d *Disk) readFile(filePath string) (database.BlockData, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return database.BlockData{}, err
+	}
+
+	if strings.HasSuffix(filePath, gzipExt) {
+		gz, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return database.BlockData{}, fmt.Errorf("%s: %w", err, ErrCorruptBlock)
+		}
+		defer gz.Close()
+
+		if data, err = io.ReadAll(gz); err != nil {
+			return database.BlockData{}, fmt.Errorf("%s: %w", err, ErrCorruptBlock)
+		}
+	}
+
+	blockData, err := d.codec.Decode(data)
+	if err != nil {
+		return database.BlockData{}, fmt.Errorf("%s: %w", err, ErrCorruptBlock)
+	}
+
+	if err := validateBlock(blockData); err != nil {
+		return database.BlockData{}, fmt.Errorf("%s: %w", err, ErrCorruptBlock)
+	}
+
+	return blockData, nil
+}
+
+// readWAL reads the write ahead log and returns the hash of each block that
+// was begun but not committed. A record cut short by a crash is ignored.
+func readWAL(walPath string) (map[uint64]string, error) {
+	f, err := os.Open(walPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	pending := make(map[uint64]string)
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+
+		num, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+
+		switch {
+		case fields[0] == walBegin && len(fields) == 3:
+			pending[num] = fields[2]
+		case fields[0] == walCommit && len(fields) == 2:
+			delete(pending, num)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading write ahead log: %w", err)
+	}
+
+	return pending, nil
+}
@@ -0,0 +1,170 @@
+package disk_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/andrewyang17/blockchain/foundation/blockchain/database"
+	"github.com/andrewyang17/blockchain/foundation/blockchain/signature"
+	"github.com/andrewyang17/blockchain/foundation/blockchain/storage/disk"
+)
+
+func Test_Recover(t *testing.T) {
+	block1 := newBlockData(database.BlockHeader{Number: 1, PrevBlockHash: signature.ZeroHash, MiningReward: 700})
+	block2 := newBlockData(database.BlockHeader{Number: 2, PrevBlockHash: block1.Hash, MiningReward: 700})
+
+	type test struct {
+		name  string
+		crash func(t *testing.T, dbPath string)
+		head  uint64
+	}
+
+	tt := []test{
+		{
+			name: "before rename",
+			crash: func(t *testing.T, dbPath string) {
+				writeFile(t, filepath.Join(dbPath, "2.json.tmp"), `{"hash": "0x`)
+			},
+			head: 1,
+		},
+		{
+			name: "after rename",
+			crash: func(t *testing.T, dbPath string) {
+				d, err := disk.New(dbPath)
+				if err != nil {
+					t.Fatalf("Should be able to construct disk storage: %s", err)
+				}
+				if err := d.Write(context.Background(), block2); err != nil {
+					t.Fatalf("Should be able to write block 2: %s", err)
+				}
+			},
+			head: 2,
+		},
+		{
+			name: "partial block",
+			crash: func(t *testing.T, dbPath string) {
+				writeFile(t, filepath.Join(dbPath, "2.json"), `{"hash": "0x`)
+			},
+			head: 1,
+		},
+	}
+
+	for _, tst := range tt {
+		f := func(t *testing.T) {
+			dbPath := t.TempDir()
+
+			d, err := disk.NewWithOptions(dbPath, disk.Options{WAL: true})
+			if err != nil {
+				t.Fatalf("Test %s:\tShould be able to construct disk storage: %s", tst.name, err)
+			}
+
+			if err := d.Write(context.Background(), block1); err != nil {
+				t.Fatalf("Test %s:\tShould be able to write block 1: %s", tst.name, err)
+			}
+
+			// Simulate a crash after the pre-commit record for block 2 is
+			// written, leaving the write in the state set up by the test.
+			walPath := filepath.Join(dbPath, "wal.log")
+			wal, err := os.OpenFile(walPath, os.O_APPEND|os.O_WRONLY, 0600)
+			if err != nil {
+				t.Fatalf("Test %s:\tShould have a write ahead log: %s", tst.name, err)
+			}
+			fmt.Fprintf(wal, "begin 2 %s\n", block2.Hash)
+			wal.Close()
+
+			tst.crash(t, dbPath)
+
+			d, err = disk.NewWithOptions(dbPath, disk.Options{WAL: true})
+			if err != nil {
+				t.Fatalf("Test %s:\tShould be able to construct disk storage again: %s", tst.name, err)
+			}
+
+			if err := d.Recover(); err != nil {
+				t.Fatalf("Test %s:\tShould be able to recover: %s", tst.name, err)
+			}
+
+			latest, err := d.LatestBlock(context.Background())
+			if err != nil {
+				t.Fatalf("Test %s:\tShould be able to read the latest block: %s", tst.name, err)
+			}
+			if latest.Header.Number != tst.head {
+				t.Fatalf("Test %s:\tShould have block %d as the latest block: got %d", tst.name, tst.head, latest.Header.Number)
+			}
+
+			if err := database.VerifyChain(d); err != nil {
+				t.Fatalf("Test %s:\tShould have an intact chain: %s", tst.name, err)
+			}
+
+			for _, name := range []string{"2.json.tmp", "wal.log"} {
+				if _, err := os.Stat(filepath.Join(dbPath, name)); !errors.Is(err, fs.ErrNotExist) {
+					t.Fatalf("Test %s:\tShould remove %s: %v", tst.name, name, err)
+				}
+			}
+		}
+
+		t.Run(tst.name, f)
+	}
+}
+
+func Test_RecoverCommitted(t *testing.T) {
+	dbPath := t.TempDir()
+
+	d, err := disk.NewWithOptions(dbPath, disk.Options{WAL: true})
+	if err != nil {
+		t.Fatalf("Should be able to construct disk storage: %s", err)
+	}
+
+	block1 := newBlockData(database.BlockHeader{Number: 1, PrevBlockHash: signature.ZeroHash, MiningReward: 700})
+	block2 := newBlockData(database.BlockHeader{Number: 2, PrevBlockHash: block1.Hash, MiningReward: 700})
+
+	if err := d.Write(context.Background(), block1); err != nil {
+		t.Fatalf("Should be able to write block 1: %s", err)
+	}
+	if err := d.WriteBatch(context.Background(), []database.BlockData{block2}); err != nil {
+		t.Fatalf("Should be able to write block 2: %s", err)
+	}
+
+	// Every write was committed, so the log has been truncated.
+	info, err := os.Stat(filepath.Join(dbPath, "wal.log"))
+	if err != nil {
+		t.Fatalf("Should have a write ahead log: %s", err)
+	}
+	if info.Size() != 0 {
+		t.Fatalf("Should truncate the write ahead log once every write is committed: got %d bytes", info.Size())
+	}
+
+	if err := d.Recover(); err != nil {
+		t.Fatalf("Should be able to recover: %s", err)
+	}
+
+	for _, blockData := range []database.BlockData{block1, block2} {
+		got, err := d.GetBlock(context.Background(), blockData.Header.Number)
+		if err != nil {
+			t.Fatalf("Should be able to read block %d after recovery: %s", blockData.Header.Number, err)
+		}
+		if got.Hash != blockData.Hash {
+			t.Fatalf("Should get back block %d unchanged: got %s, exp %s", blockData.Header.Number, got.Hash, blockData.Hash)
+		}
+	}
+
+	// A disk without a log has nothing to recover.
+	d, err = disk.New(t.TempDir())
+	if err != nil {
+		t.Fatalf("Should be able to construct disk storage: %s", err)
+	}
+	if err := d.Recover(); err != nil {
+		t.Fatalf("Should be able to recover without a log: %s", err)
+	}
+}
+
+// writeFile writes the data to the file at the path.
+func writeFile(t *testing.T, path string, data string) {
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatalf("Should be able to write %s: %s", path, err)
+	}
+}
@@ -0,0 +1,112 @@
+// Package logging implements a decorator for any storage implementation that
+// logs the block reads and writes. This is useful for debugging without
+// changing any of the call sites.
+package logging
+
+import (
+	"context"
+	"time"
+
+	"github.com/andrewyang17/blockchain/foundation/blockchain/database"
+	"go.uber.org/zap"
+)
+
+// Logging represents a storage implementation that logs each call and
+// delegates the work to the inner storage. This implements the
+// database.Storage interface.
+type Logging struct {
+	inner database.Storage
+	log   *zap.SugaredLogger
+}
+
+// New constructs a Logging value that wraps the specified storage.
+func New(inner database.Storage, log *zap.SugaredLogger) *Logging {
+	return &Logging{
+		inner: inner,
+		log:   log,
+	}
+}
+
+// Close closes the inner storage.
+func (l *Logging) Close() error {
+	return l.inner.Close()
+}
+
+// Write logs the block number and latency of writing the block.
+func (l *Logging) Write(ctx context.Context, blockData database.BlockData) error {
+	start := time.Now()
+	err := l.inner.Write(ctx, blockData)
+	l.logCall("write", start, err, "blocknum", blockData.Header.Number)
+
+	return err
+}
+
+// WriteBatch logs the block range and latency of writing the blocks.
+func (l *Logging) WriteBatch(ctx context.Context, blocks []database.BlockData) error {
+	start := time.Now()
+	err := l.inner.WriteBatch(ctx, blocks)
+
+	var first, last uint64
+	if len(blocks) > 0 {
+		first = blocks[0].Header.Number
+		last = blocks[len(blocks)-1].Header.Number
+	}
+	l.logCall("write batch", start, err, "count", len(blocks), "first", first, "last", last)
+
+	return err
+}
+
+// GetBlock logs the block number and latency of reading the block.
+func (l *Logging) GetBlock(ctx context.Context, num uint64) (database.BlockData, error) {
+	start := time.Now()
+	blockData, err := l.inner.GetBlock(ctx, num)
+	l.logCall("get block", start, err, "blocknum", num)
+
+	return blockData, err
+}
+
+// GetBlockByHash logs the block hash and latency of reading the block.
+func (l *Logging) GetBlockByHash(ctx context.Context, hash string) (database.BlockData, error) {
+	start := time.Now()
+	blockData, err := l.inner.GetBlockByHash(ctx, hash)
+	l.logCall("get block by hash", start, err, "hash", hash, "blocknum", blockData.Header.Number)
+
+	return blockData, err
+}
+
+// ForEach returns the iterator from the inner storage.
+func (l *Logging) ForEach(ctx context.Context) database.Iterator {
+	return l.inner.ForEach(ctx)
+}
+
+// Healthy logs the latency of checking the inner storage.
+func (l *Logging) Healthy(ctx context.Context) error {
+	start := time.Now()
+	err := l.inner.Healthy(ctx)
+	l.logCall("healthy", start, err)
+
+	return err
+}
+
+// Reset logs the latency of clearing out the inner storage.
+func (l *Logging) Reset() error {
+	start := time.Now()
+	err := l.inner.Reset()
+	l.logCall("reset", start, err)
+
+	return err
+}
+
+// logCall writes a log entry for the storage call with the latency and
+// the error if one occurred.
+func (l *Logging) logCall(call string, start time.Time, err error, keysAndValues ...any) {
+	keysAndValues = append([]any{"call", call}, keysAndValues...)
+	keysAndValues = append(keysAndValues, "latency", time.Since(start).String())
+
+	if err != nil {
+		l.log.Infow("storage", append(keysAndValues, "ERROR", err)...)
+		return
+	}
+
+	l.log.Infow("storage", keysAndValues...)
+}
@@ -0,0 +1,62 @@
+package logging_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/andrewyang17/blockchain/foundation/blockchain/database"
+	"github.com/andrewyang17/blockchain/foundation/blockchain/storage/logging"
+	"github.com/andrewyang17/blockchain/foundation/blockchain/storage/memory"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func Test_Logging(t *testing.T) {
+	var buf bytes.Buffer
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), zapcore.AddSync(&buf), zapcore.InfoLevel)
+	log := zap.New(core).Sugar()
+
+	var strg database.Storage = logging.New(memory.New(), log)
+
+	blockData := database.BlockData{Hash: "0x01", Header: database.BlockHeader{Number: 7}}
+	if err := strg.Write(context.Background(), blockData); err != nil {
+		t.Fatalf("Should be able to write the block: %s", err)
+	}
+
+	got, err := strg.GetBlock(context.Background(), 7)
+	if err != nil {
+		t.Fatalf("Should be able to read the block: %s", err)
+	}
+	if got.Hash != blockData.Hash {
+		t.Fatalf("Should get back the written block: got %s, exp %s", got.Hash, blockData.Hash)
+	}
+
+	if err := strg.Reset(); err != nil {
+		t.Fatalf("Should be able to reset: %s", err)
+	}
+	if _, err := strg.GetBlock(context.Background(), 7); err == nil {
+		t.Fatalf("Should not find block 7 after a reset")
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	exp := []string{
+		`"call":"write","blocknum":7`,
+		`"call":"get block","blocknum":7`,
+		`"call":"reset"`,
+		`"call":"get block","blocknum":7`,
+	}
+	if len(lines) != len(exp) {
+		t.Fatalf("Should log %d calls, got %d: %s", len(exp), len(lines), buf.String())
+	}
+
+	for i, e := range exp {
+		if !strings.Contains(lines[i], e) || !strings.Contains(lines[i], `"latency"`) {
+			t.Fatalf("Should log %s with a latency, got %s", e, lines[i])
+		}
+	}
+	if !strings.Contains(lines[3], `"ERROR"`) {
+		t.Fatalf("Should log the error for the missing block, got %s", lines[3])
+	}
+}
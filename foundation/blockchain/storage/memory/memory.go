@@ -0,0 +1,178 @@
+// Package memory implements the ability to read and write blocks in memory.
+// This is useful for tests that need storage without touching disk.
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"sync"
+
+	"github.com/andrewyang17/blockchain/foundation/blockchain/database"
+)
+
+// Memory represents the serialization implementation for reading and storing
+// blocks in memory. This implements the database.Storage interface.
+type Memory struct {
+	mu     sync.RWMutex
+	blocks map[uint64]database.BlockData
+}
+
+// New constructs a Memory value for use.
+func New() *Memory {
+	return &Memory{
+		blocks: make(map[uint64]database.BlockData),
+	}
+}
+
+// Close in this implementation has nothing to do.
+func (m *Memory) Close() error {
+	return nil
+}
+
+// Write takes a copy of the specified database block and stores it
+// by block number.
+func (m *Memory) Write(ctx context.Context, blockData database.BlockData) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	{
+		m.blocks[blockData.Header.Number] = blockData.Copy()
+		return nil
+	}
+}
+
+// WriteBatch takes a copy of the specified database blocks and stores them
+// by block number.
+func (m *Memory) WriteBatch(ctx context.Context, blocks []database.BlockData) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	{
+		for _, blockData := range blocks {
+			m.blocks[blockData.Header.Number] = blockData.Copy()
+		}
+		return nil
+	}
+}
+
+// GetBlock returns a copy of the specified block by number.
+func (m *Memory) GetBlock(ctx context.Context, num uint64) (database.BlockData, error) {
+	if err := ctx.Err(); err != nil {
+		return database.BlockData{}, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	{
+		blockData, exists := m.blocks[num]
+		if !exists {
+			return database.BlockData{}, fmt.Errorf("block %d: %w", num, fs.ErrNotExist)
+		}
+
+		return blockData.Copy(), nil
+	}
+}
+
+// GetBlockByHash returns a copy of the block with the specified hash.
+func (m *Memory) GetBlockByHash(ctx context.Context, hash string) (database.BlockData, error) {
+	if err := ctx.Err(); err != nil {
+		return database.BlockData{}, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	{
+		for _, blockData := range m.blocks {
+			if blockData.Hash == hash {
+				return blockData.Copy(), nil
+			}
+		}
+
+		return database.BlockData{}, fmt.Errorf("block hash %s: %w", hash, fs.ErrNotExist)
+	}
+}
+
+// ForEach returns an iterator to walk through all the blocks
+// starting with block number 1.
+func (m *Memory) ForEach(ctx context.Context) database.Iterator {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	{
+		numbers := make([]uint64, 0, len(m.blocks))
+		for num := range m.blocks {
+			if num >= 1 {
+				numbers = append(numbers, num)
+			}
+		}
+		sort.Slice(numbers, func(i, j int) bool { return numbers[i] < numbers[j] })
+
+		return &memoryIterator{ctx: ctx, storage: m, numbers: numbers}
+	}
+}
+
+// Healthy in this implementation has nothing to check.
+func (m *Memory) Healthy(ctx context.Context) error {
+	return ctx.Err()
+}
+
+// Reset will clear out the blockchain in memory.
+func (m *Memory) Reset() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	{
+		m.blocks = make(map[uint64]database.BlockData)
+		return nil
+	}
+}
+
+// =============================================================================
+
+// memoryIterator represents the iteration implementation for walking
+// through the blocks in memory. The block numbers are captured when the
+// iterator is constructed. This implements the database Iterator interface.
+type memoryIterator struct {
+	ctx        context.Context
+	storage    *Memory
+	numbers    []uint64
+	endOfChain bool
+	failed     bool
+}
+
+// Next retrieves the next block from memory.
+func (mi *memoryIterator) Next() (database.BlockData, error) {
+	if mi.endOfChain || mi.failed || len(mi.numbers) == 0 {
+		mi.endOfChain = true
+		return database.BlockData{}, database.ErrEndOfChain
+	}
+
+	// Report the context error once, the next call will then mark the
+	// end of the chain.
+	if err := mi.ctx.Err(); err != nil {
+		mi.failed = true
+		return database.BlockData{}, err
+	}
+
+	num := mi.numbers[0]
+	mi.numbers = mi.numbers[1:]
+
+	blockData, err := mi.storage.GetBlock(mi.ctx, num)
+	if errors.Is(err, fs.ErrNotExist) {
+		mi.endOfChain = true
+	}
+
+	return blockData, err
+}
+
+// Done returns the end of chain value.
+func (mi *memoryIterator) Done() bool {
+	return mi.endOfChain
+}
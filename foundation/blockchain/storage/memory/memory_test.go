@@ -0,0 +1,62 @@
+package memory_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/andrewyang17/blockchain/foundation/blockchain/database"
+	"github.com/andrewyang17/blockchain/foundation/blockchain/storage/memory"
+)
+
+func Test_Memory(t *testing.T) {
+	m := memory.New()
+
+	for _, i := range []uint64{3, 1, 2} {
+		blockData := database.BlockData{
+			Header: database.BlockHeader{Number: i},
+			Trans: []database.BlockTx{
+				{SignedTx: database.SignedTx{Tx: database.Tx{Nonce: i, Data: []byte("data")}, V: big.NewInt(29)}},
+			},
+		}
+		if err := m.Write(context.Background(), blockData); err != nil {
+			t.Fatalf("Should be able to write block %d: %s", i, err)
+		}
+
+		// Changing the block after the write must not change the stored block.
+		blockData.Trans[0].Data[0] = 'X'
+		blockData.Trans[0].V.SetInt64(0)
+	}
+
+	var exp uint64
+	iter := m.ForEach(context.Background())
+	for blockData, err := iter.Next(); !iter.Done(); blockData, err = iter.Next() {
+		if err != nil {
+			t.Fatalf("Should be able to iterate the blocks: %s", err)
+		}
+
+		exp++
+		if blockData.Header.Number != exp {
+			t.Fatalf("Should iterate in block order: got %d, exp %d", blockData.Header.Number, exp)
+		}
+
+		tx := blockData.Trans[0]
+		if string(tx.Data) != "data" || tx.V.Int64() != 29 {
+			t.Fatalf("Should not see changes made after the write: data %q, v %d", tx.Data, tx.V)
+		}
+	}
+	if exp != 3 {
+		t.Fatalf("Should iterate over 3 blocks, got %d", exp)
+	}
+	if _, err := iter.Next(); !errors.Is(err, database.ErrEndOfChain) {
+		t.Fatalf("Should get ErrEndOfChain once the iterator is exhausted, got %v", err)
+	}
+
+	if err := m.Reset(); err != nil {
+		t.Fatalf("Should be able to reset: %s", err)
+	}
+	if _, err := m.GetBlock(context.Background(), 1); err == nil {
+		t.Fatalf("Should not find block 1 after a reset")
+	}
+}
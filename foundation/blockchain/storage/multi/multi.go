@@ -0,0 +1,89 @@
+// Package multi implements the ability to write blocks to several storage
+// implementations at the same time, such as local disk for fast reads and
+// an object store for durability.
+package multi
+
+import (
+	"context"
+
+	"github.com/andrewyang17/blockchain/foundation/blockchain/database"
+)
+
+// Multi represents a storage implementation that writes to every backend and
+// reads from the primary backend. This implements the database.Storage
+// interface.
+type Multi struct {
+	backends []database.Storage
+}
+
+// New constructs a Multi value that reads from the primary backend and
+// writes to the primary and all the other backends.
+func New(primary database.Storage, others ...database.Storage) *Multi {
+	return &Multi{
+		backends: append([]database.Storage{primary}, others...),
+	}
+}
+
+// Close closes every backend and returns the first error.
+func (m *Multi) Close() error {
+	return m.each(func(s database.Storage) error {
+		return s.Close()
+	})
+}
+
+// Write writes the block to every backend and returns the first error.
+func (m *Multi) Write(ctx context.Context, blockData database.BlockData) error {
+	return m.each(func(s database.Storage) error {
+		return s.Write(ctx, blockData)
+	})
+}
+
+// WriteBatch writes the blocks to every backend and returns the first error.
+func (m *Multi) WriteBatch(ctx context.Context, blocks []database.BlockData) error {
+	return m.each(func(s database.Storage) error {
+		return s.WriteBatch(ctx, blocks)
+	})
+}
+
+// GetBlock returns the specified block from the primary backend.
+func (m *Multi) GetBlock(ctx context.Context, num uint64) (database.BlockData, error) {
+	return m.backends[0].GetBlock(ctx, num)
+}
+
+// GetBlockByHash returns the specified block from the primary backend.
+func (m *Multi) GetBlockByHash(ctx context.Context, hash string) (database.BlockData, error) {
+	return m.backends[0].GetBlockByHash(ctx, hash)
+}
+
+// ForEach returns an iterator over the primary backend.
+func (m *Multi) ForEach(ctx context.Context) database.Iterator {
+	return m.backends[0].ForEach(ctx)
+}
+
+// Healthy checks every backend and returns the first error.
+func (m *Multi) Healthy(ctx context.Context) error {
+	return m.each(func(s database.Storage) error {
+		return s.Healthy(ctx)
+	})
+}
+
+// Reset clears out every backend and returns the first error.
+func (m *Multi) Reset() error {
+	return m.each(func(s database.Storage) error {
+		return s.Reset()
+	})
+}
+
+// each calls the function for every backend, even after an error, so the
+// backends stay as close to each other as possible. The first error is
+// returned.
+func (m *Multi) each(f func(s database.Storage) error) error {
+	var first error
+	for _, s := range m.backends {
+		if err := f(s); err != nil && first == nil {
+			first = err
+		}
+	}
+
+	return first
+}
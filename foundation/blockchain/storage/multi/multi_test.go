@@ -0,0 +1,53 @@
+package multi_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/andrewyang17/blockchain/foundation/blockchain/database"
+	"github.com/andrewyang17/blockchain/foundation/blockchain/storage/memory"
+	"github.com/andrewyang17/blockchain/foundation/blockchain/storage/multi"
+)
+
+func Test_Multi(t *testing.T) {
+	primary := memory.New()
+	secondary := memory.New()
+
+	var strg database.Storage = multi.New(primary, secondary)
+
+	blockData := database.BlockData{Hash: "0x01", Header: database.BlockHeader{Number: 1}}
+	if err := strg.Write(context.Background(), blockData); err != nil {
+		t.Fatalf("Should be able to write the block: %s", err)
+	}
+
+	for name, backend := range map[string]*memory.Memory{"primary": primary, "secondary": secondary} {
+		got, err := backend.GetBlock(context.Background(), 1)
+		if err != nil {
+			t.Fatalf("Should find the block in the %s backend: %s", name, err)
+		}
+		if got.Hash != blockData.Hash {
+			t.Fatalf("Should get back the written block from the %s backend: got %s, exp %s", name, got.Hash, blockData.Hash)
+		}
+	}
+
+	// Change the block in the secondary backend only, so it's clear where
+	// the reads come from.
+	if err := secondary.Write(context.Background(), database.BlockData{Hash: "0x02", Header: database.BlockHeader{Number: 1}}); err != nil {
+		t.Fatalf("Should be able to write the block to the secondary: %s", err)
+	}
+
+	got, err := strg.GetBlock(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("Should be able to read the block: %s", err)
+	}
+	if got.Hash != blockData.Hash {
+		t.Fatalf("Should read the block from the primary: got %s, exp %s", got.Hash, blockData.Hash)
+	}
+
+	if err := strg.Reset(); err != nil {
+		t.Fatalf("Should be able to reset: %s", err)
+	}
+	if _, err := secondary.GetBlock(context.Background(), 1); err == nil {
+		t.Fatalf("Should reset the secondary backend")
+	}
+}
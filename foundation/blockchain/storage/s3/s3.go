@@ -0,0 +1,257 @@
+// Package s3 implements the ability to read and write blocks to an object
+// store such as AWS S3. Each block is stored as an object keyed by the block
+// number under a prefix, using the same JSON encoding as the disk storage so
+// blocks can be copied between the two.
+package s3
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andrewyang17/blockchain/foundation/blockchain/database"
+	"github.com/andrewyang17/blockchain/foundation/blockchain/storage/disk"
+)
+
+// healthKey is the name of the probe object put and deleted under the prefix
+// to check the object store is writable.
+const healthKey = ".health"
+
+// Client defines the behavior required of the object store. An adapter over
+// the AWS SDK satisfies this for S3 and tests can provide a mock. GetObject
+// must return an error wrapping fs.ErrNotExist when the key doesn't exist.
+type Client interface {
+	PutObject(ctx context.Context, key string, data []byte) error
+	GetObject(ctx context.Context, key string) ([]byte, error)
+	ListObjects(ctx context.Context, prefix string) ([]string, error)
+	DeleteObjects(ctx context.Context, keys []string) error
+}
+
+// S3 represents the serialization implementation for reading and storing
+// blocks in an object store. This implements the database.Storage interface.
+type S3 struct {
+	client    Client
+	prefix    string
+	codec     disk.JSON
+	mu        sync.RWMutex
+	hashIndex map[string]uint64
+}
+
+// New constructs an S3 value for use. The blocks are stored under the
+// specified prefix, which is used as is so include any trailing slash. The
+// existing blocks are read to build the block hash index.
+func New(ctx context.Context, client Client, prefix string) (*S3, error) {
+	s := S3{
+		client:    client,
+		prefix:    prefix,
+		hashIndex: make(map[string]uint64),
+	}
+
+	iter := s.ForEach(ctx)
+	for blockData, err := iter.Next(); !iter.Done(); blockData, err = iter.Next() {
+		if err != nil {
+			return nil, err
+		}
+		s.hashIndex[blockData.Hash] = blockData.Header.Number
+	}
+
+	return &s, nil
+}
+
+// Close in this implementation has nothing to do.
+func (s *S3) Close() error {
+	return nil
+}
+
+// Write takes the specified database block and stores it as an object.
+func (s *S3) Write(ctx context.Context, blockData database.BlockData) error {
+	return s.WriteBatch(ctx, []database.BlockData{blockData})
+}
+
+// WriteBatch takes the specified database blocks and stores each one as an
+// object. The error names the block that failed.
+func (s *S3) WriteBatch(ctx context.Context, blocks []database.BlockData) error {
+	for _, blockData := range blocks {
+		data, err := s.codec.Encode(blockData)
+		if err != nil {
+			return fmt.Errorf("block %d: %w", blockData.Header.Number, err)
+		}
+
+		if err := s.client.PutObject(ctx, s.key(blockData.Header.Number), data); err != nil {
+			return fmt.Errorf("block %d: %w", blockData.Header.Number, err)
+		}
+
+		s.mu.Lock()
+		s.hashIndex[blockData.Hash] = blockData.Header.Number
+		s.mu.Unlock()
+	}
+
+	return nil
+}
+
+// GetBlock reads the object for the specified block number and returns
+// the contents.
+func (s *S3) GetBlock(ctx context.Context, num uint64) (database.BlockData, error) {
+	data, err := s.client.GetObject(ctx, s.key(num))
+	if err != nil {
+		return database.BlockData{}, fmt.Errorf("block %d: %w", num, err)
+	}
+
+	blockData, err := s.codec.Decode(data)
+	if err != nil {
+		return database.BlockData{}, fmt.Errorf("block %d: %s: %w", num, err, disk.ErrCorruptBlock)
+	}
+
+	return blockData, nil
+}
+
+// GetBlockByHash uses the hash index to locate and return the contents of
+// the specified block by hash.
+func (s *S3) GetBlockByHash(ctx context.Context, hash string) (database.BlockData, error) {
+	s.mu.RLock()
+	num, exists := s.hashIndex[hash]
+	s.mu.RUnlock()
+
+	if !exists {
+		return database.BlockData{}, fmt.Errorf("block hash %s: %w", hash, fs.ErrNotExist)
+	}
+
+	blockData, err := s.GetBlock(ctx, num)
+	if err != nil {
+		return database.BlockData{}, err
+	}
+
+	// The block number could have been rewritten with a different block
+	// since the index entry was added.
+	if blockData.Hash != hash {
+		return database.BlockData{}, fmt.Errorf("block hash %s: %w", hash, fs.ErrNotExist)
+	}
+
+	return blockData, nil
+}
+
+// ForEach returns an iterator to walk through all the blocks under the
+// prefix starting with the lowest block number.
+func (s *S3) ForEach(ctx context.Context) database.Iterator {
+	numbers, err := s.blockNumbers(ctx)
+	return &s3Iterator{ctx: ctx, storage: s, numbers: numbers, err: err}
+}
+
+// Healthy puts and deletes a probe object under the prefix to check the
+// object store is reachable and writable.
+func (s *S3) Healthy(ctx context.Context) error {
+	key := s.prefix + healthKey
+
+	if err := s.client.PutObject(ctx, key, []byte("ok")); err != nil {
+		return fmt.Errorf("storage not writable: %w", err)
+	}
+
+	if err := s.client.DeleteObjects(ctx, []string{key}); err != nil {
+		return fmt.Errorf("storage not writable: %w", err)
+	}
+
+	return nil
+}
+
+// Reset deletes all the objects under the prefix.
+func (s *S3) Reset() error {
+	keys, err := s.client.ListObjects(context.Background(), s.prefix)
+	if err != nil {
+		return err
+	}
+
+	if len(keys) > 0 {
+		if err := s.client.DeleteObjects(context.Background(), keys); err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	{
+		s.hashIndex = make(map[string]uint64)
+		return nil
+	}
+}
+
+// key forms the object key for the specified block.
+func (s *S3) key(num uint64) string {
+	return s.prefix + strconv.FormatUint(num, 10) + s.codec.Ext()
+}
+
+// blockNumbers lists the objects under the prefix and returns the block
+// numbers in order. Objects that aren't blocks are skipped.
+func (s *S3) blockNumbers(ctx context.Context) ([]uint64, error) {
+	keys, err := s.client.ListObjects(ctx, s.prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	var numbers []uint64
+	for _, key := range keys {
+		name := strings.TrimPrefix(key, s.prefix)
+		if !strings.HasSuffix(name, s.codec.Ext()) {
+			continue
+		}
+
+		num, err := strconv.ParseUint(strings.TrimSuffix(name, s.codec.Ext()), 10, 64)
+		if err != nil {
+			continue
+		}
+		numbers = append(numbers, num)
+	}
+
+	sort.Slice(numbers, func(i, j int) bool { return numbers[i] < numbers[j] })
+
+	return numbers, nil
+}
+
+// =============================================================================
+
+// s3Iterator represents the iteration implementation for walking through
+// the blocks in the object store. The block numbers are captured when the
+// iterator is constructed. This implements the database Iterator interface.
+type s3Iterator struct {
+	ctx        context.Context
+	storage    *S3
+	numbers    []uint64
+	err        error
+	endOfChain bool
+	failed     bool
+}
+
+// Next retrieves the next block from the object store.
+func (si *s3Iterator) Next() (database.BlockData, error) {
+	if si.err != nil {
+		err := si.err
+		si.err = nil
+		si.failed = true
+		return database.BlockData{}, err
+	}
+
+	if si.endOfChain || si.failed || len(si.numbers) == 0 {
+		si.endOfChain = true
+		return database.BlockData{}, database.ErrEndOfChain
+	}
+
+	// Report the context error once, the next call will then mark the
+	// end of the chain.
+	if err := si.ctx.Err(); err != nil {
+		si.failed = true
+		return database.BlockData{}, err
+	}
+
+	num := si.numbers[0]
+	si.numbers = si.numbers[1:]
+
+	return si.storage.GetBlock(si.ctx, num)
+}
+
+// Done returns the end of chain value.
+func (si *s3Iterator) Done() bool {
+	return si.endOfChain
+}
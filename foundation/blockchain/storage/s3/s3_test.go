@@ -0,0 +1,162 @@
+package s3_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/andrewyang17/blockchain/foundation/blockchain/database"
+	"github.com/andrewyang17/blockchain/foundation/blockchain/storage/disk"
+	"github.com/andrewyang17/blockchain/foundation/blockchain/storage/s3"
+)
+
+// mockClient is an in memory object store.
+type mockClient struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func newMockClient() *mockClient {
+	return &mockClient{objects: make(map[string][]byte)}
+}
+
+func (mc *mockClient) PutObject(ctx context.Context, key string, data []byte) error {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	mc.objects[key] = append([]byte{}, data...)
+	return nil
+}
+
+func (mc *mockClient) GetObject(ctx context.Context, key string) ([]byte, error) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	data, exists := mc.objects[key]
+	if !exists {
+		return nil, fmt.Errorf("object %s: %w", key, fs.ErrNotExist)
+	}
+	return data, nil
+}
+
+func (mc *mockClient) ListObjects(ctx context.Context, prefix string) ([]string, error) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	var keys []string
+	for key := range mc.objects {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func (mc *mockClient) DeleteObjects(ctx context.Context, keys []string) error {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	for _, key := range keys {
+		delete(mc.objects, key)
+	}
+	return nil
+}
+
+// =============================================================================
+
+// newBlockData returns the block data for the header with a matching hash.
+func newBlockData(header database.BlockHeader) database.BlockData {
+	return database.BlockData{
+		Hash:   database.Block{Header: header}.Hash(),
+		Header: header,
+	}
+}
+
+func Test_S3(t *testing.T) {
+	client := newMockClient()
+
+	// An object from another node must not be touched.
+	client.PutObject(context.Background(), "node2/1.json", []byte("{}"))
+
+	s, err := s3.New(context.Background(), client, "node1/")
+	if err != nil {
+		t.Fatalf("Should be able to construct s3 storage: %s", err)
+	}
+
+	var blocks []database.BlockData
+	for _, i := range []uint64{2, 10, 1} {
+		blockData := newBlockData(database.BlockHeader{Number: i, MiningReward: 700})
+		if err := s.Write(context.Background(), blockData); err != nil {
+			t.Fatalf("Should be able to write block %d: %s", i, err)
+		}
+		blocks = append(blocks, blockData)
+	}
+
+	if _, exists := client.objects["node1/10.json"]; !exists {
+		t.Fatalf("Should store the block keyed by number under the prefix: %v", client.objects)
+	}
+
+	// Blocks must be encoded the same way as the disk storage.
+	dbPath := t.TempDir()
+	d, err := disk.New(dbPath)
+	if err != nil {
+		t.Fatalf("Should be able to construct disk storage: %s", err)
+	}
+	if err := d.Write(context.Background(), blocks[0]); err != nil {
+		t.Fatalf("Should be able to write block to disk: %s", err)
+	}
+	onDisk, err := os.ReadFile(filepath.Join(dbPath, "2.json"))
+	if err != nil {
+		t.Fatalf("Should be able to read block from disk: %s", err)
+	}
+	if string(onDisk) != string(client.objects["node1/2.json"]) {
+		t.Fatalf("Should encode the block the same as disk:\n%s\n%s", onDisk, client.objects["node1/2.json"])
+	}
+
+	got, err := s.GetBlockByHash(context.Background(), blocks[1].Hash)
+	if err != nil {
+		t.Fatalf("Should be able to get block by hash: %s", err)
+	}
+	if got.Header.Number != 10 {
+		t.Fatalf("Should get back block 10 by hash: got %d", got.Header.Number)
+	}
+
+	// A new value must find the existing blocks.
+	s, err = s3.New(context.Background(), client, "node1/")
+	if err != nil {
+		t.Fatalf("Should be able to construct s3 storage: %s", err)
+	}
+	if _, err := s.GetBlockByHash(context.Background(), blocks[2].Hash); err != nil {
+		t.Fatalf("Should be able to get existing block by hash: %s", err)
+	}
+
+	var numbers []uint64
+	iter := s.ForEach(context.Background())
+	for blockData, err := iter.Next(); !iter.Done(); blockData, err = iter.Next() {
+		if err != nil {
+			t.Fatalf("Should be able to iterate the blocks: %s", err)
+		}
+		numbers = append(numbers, blockData.Header.Number)
+	}
+	if fmt.Sprint(numbers) != "[1 2 10]" {
+		t.Fatalf("Should iterate in block order: got %v", numbers)
+	}
+
+	if err := s.Reset(); err != nil {
+		t.Fatalf("Should be able to reset: %s", err)
+	}
+	if _, err := s.GetBlock(context.Background(), 1); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Should not find block 1 after a reset: %v", err)
+	}
+	if _, exists := client.objects["node2/1.json"]; !exists {
+		t.Fatalf("Should not delete objects outside the prefix")
+	}
+}
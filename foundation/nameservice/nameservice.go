@@ -38,7 +38,7 @@ func New(root string) (*NameService, error) {
 			return err
 		}
 
-		accountID := database.PublicKeyToAccountID(privateKey.PublicKey)
+		accountID := database.AccountIDFromPrivateKey(privateKey)
 		ns.accounts[accountID] = strings.TrimSuffix(path.Base(fileName), ".ecdsa")
 
 		return nil
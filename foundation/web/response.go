@@ -9,11 +9,9 @@ import (
 // Respond converts a Go value to JSON and sends it to the client.
 func Respond(ctx context.Context, w http.ResponseWriter, data any, statusCode int) error {
 
-	// Set the status code for the request logger middleware.
-	SetStatusCode(ctx, statusCode)
-
 	// If there is nothing to marshal then set status code and return.
 	if statusCode == http.StatusNoContent {
+		SetStatusCode(ctx, statusCode)
 		w.WriteHeader(statusCode)
 		return nil
 	}
@@ -24,6 +22,11 @@ func Respond(ctx context.Context, w http.ResponseWriter, data any, statusCode in
 		return err
 	}
 
+	// Set the status code for the request logger middleware. This waits
+	// until marshaling has succeeded, so a failed response can still be
+	// answered with an error.
+	SetStatusCode(ctx, statusCode)
+
 	// Set the content type and headers once we know marshaling has succeeded.
 	w.Header().Set("Content-Type", "application/json")
 